@@ -1,7 +1,9 @@
 package shortener
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -22,12 +24,15 @@ import (
 	grpcv1 "github.com/alukart32/shortener-url/internal/shortener/controller/grpc/v1"
 	"github.com/alukart32/shortener-url/internal/shortener/controller/http/pinger"
 	httpv1 "github.com/alukart32/shortener-url/internal/shortener/controller/http/v1"
+	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/services"
 	"github.com/alukart32/shortener-url/internal/shortener/services/pingpgx"
 	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl/filestorage"
 	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl/memstorage"
 	shortenedurlpgx "github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl/postgres"
+	"github.com/caarlos0/env/v6"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -132,7 +137,7 @@ func Run() {
 
 	// Run grpc server.
 	logger.Info().Msg("run: grpc server")
-	grpcv1.RegServices(grpcServer.Srv, servs)
+	grpcv1.RegServices(grpcServer.Srv, servs, jwtManager)
 	grpcServer.Run()
 	defer func() {
 		logger.Info().Msg("shutdown: grpc server")
@@ -155,6 +160,12 @@ func Run() {
 // shutdownFn defines a func that can shut down anything.
 type shutdownFn func() error
 
+// urlEditor defines the storage editor of the shortened URL destinations.
+type urlEditor interface {
+	Edit(context.Context, models.URLChange) (models.URLChange, error)
+	History(context.Context, string, string) ([]models.URLChange, error)
+}
+
 // prepareServices prepares services.
 func prepareServices(conf config, pgxPool *pgxpool.Pool) (*services.Services, shutdownFn) {
 	logger := zerologx.Get()
@@ -162,59 +173,196 @@ func prepareServices(conf config, pgxPool *pgxpool.Pool) (*services.Services, sh
 	var (
 		shortener services.Shortener
 		provider  services.Provider
+		visitor   services.Visitor
 		deleter   services.Deleter
+		edits     urlEditor
 		statistic services.StatProvider
+		expirer   services.Expirer
 		pinger    services.Pinger
 
-		err      error
-		shutdown shutdownFn
+		err       error
+		shutdowns []shutdownFn
 	)
 
+	scope, err := shortenedurl.ParseScope(conf.URLUniqueScope)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url uniqueness scope")
+	}
+
+	slugConf := shorturl.SlugConfig{
+		Strategy: conf.SlugStrategy,
+		Length:   conf.SlugLength,
+		Alphabet: conf.SlugAlphabet,
+	}
+
+	canon, err := shorturl.Canonicalizer(shorturl.CanonicalConfig{
+		Steps:          conf.URLCanonicalSteps,
+		TrackingParams: conf.URLTrackingParams,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url canonicalizer")
+	}
+
+	policy, err := shorturl.LoadPolicy(shorturl.PolicyConfig{
+		Path: conf.URLPolicyPath,
+	}, conf.BaseURL)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url policy")
+	}
+
+	blocklist, err := shorturl.Blocklist(shorturl.BlocklistConfig{
+		Path:           conf.URLBlocklistPath,
+		ReloadInterval: conf.URLBlocklistReloadInterval,
+	}, canon)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url blocklist")
+	}
+	checks := shorturl.Policies(policy, blocklist)
+
 	if pgxPool != nil {
+		slugs, err := shorturl.Slugs(slugConf, shortenedurlpgx.SlugSequence(pgxPool))
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to prepare slug generator")
+		}
 		shortener, err = shorturl.Shortener(
 			conf.BaseURL,
-			shortenedurlpgx.ShortURLSaver(pgxPool),
+			shortenedurlpgx.ShortURLSaver(pgxPool, scope),
+			slugs,
+			canon,
+			checks,
 		)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("failed to prepare url shortener")
 		}
-		provider = shortenedurlpgx.ShortURLProvider(pgxPool)
+		provider = shortenedurlpgx.ShortURLProvider(pgxPool, scope)
 		statistic = shortenedurlpgx.StatProvider(pgxPool)
+		visitor = shortenedurlpgx.ShortURLVisitor(pgxPool)
 		deleter = shortenedurlpgx.ShortURLDeleter(pgxPool)
+		edits = shortenedurlpgx.ShortURLEditor(pgxPool, scope)
+		expirer = shortenedurlpgx.ShortURLExpirer(pgxPool)
 		pinger = pingpgx.Pinger(pgxPool, 1*time.Second)
+
+		if conf.CacheMaxEntries > 0 || conf.CacheMaxBytes > 0 {
+			cache, err := memstorage.Cache(memstorage.CacheConfig{
+				MaxEntries:   conf.CacheMaxEntries,
+				MaxBytes:     conf.CacheMaxBytes,
+				StatInterval: conf.CacheStatInterval,
+			}, provider, deleter, edits)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("failed to prepare cache")
+			}
+			shutdowns = append(shutdowns, cache.Close)
+
+			provider = cache
+			deleter = cache
+			edits = cache
+		}
 	}
 
 	if len(conf.FileStoragePath) != 0 {
-		fileStorage, err := filestorage.FileStorage(conf.FileStoragePath)
+		fileStorage, err := filestorage.FileStorage(filestorage.Config{
+			Path:               conf.FileStoragePath,
+			SegmentSize:        conf.FileStorageSegmentSize,
+			CompactionInterval: conf.FileStorageCompactionInterval,
+			Sync:               conf.FileStorageSync,
+			SyncInterval:       conf.FileStorageSyncInterval,
+			Upgrade:            conf.FileStorageUpgrade,
+			Scope:              scope,
+		})
 		if err != nil {
 			logger.Fatal().Err(err).Msg("failed to preapre file storage")
 		}
-		shutdown = func() error {
-			return fileStorage.Close()
-		}
+		shutdowns = append(shutdowns, fileStorage.Close)
 
-		shortener, err = shorturl.Shortener(conf.BaseURL, fileStorage)
+		slugs, err := shorturl.Slugs(slugConf, fileStorage)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to prepare slug generator")
+		}
+		shortener, err = shorturl.Shortener(conf.BaseURL, fileStorage, slugs, canon, checks)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("failed to prepare url shortener")
 		}
 		provider = fileStorage
 		statistic = fileStorage
+		visitor = fileStorage
 		deleter = fileStorage
+		edits = fileStorage
+		expirer = fileStorage
 	}
 
 	if pgxPool == nil && len(conf.FileStoragePath) == 0 {
-		memStorage := memstorage.MemStorage()
+		memStorage := memstorage.MemStorage(scope)
+		if len(conf.MemStorageSnapshotPath) != 0 {
+			memStorage, err = memstorage.PersistentMemStorage(memstorage.Config{
+				SnapshotPath:     conf.MemStorageSnapshotPath,
+				SnapshotInterval: conf.MemStorageSnapshotInterval,
+				WAL:              conf.MemStorageWAL,
+				Scope:            scope,
+			})
+			if err != nil {
+				logger.Fatal().Err(err).Msg("failed to prepare memory storage")
+			}
+			shutdowns = append(shutdowns, memStorage.Close)
+		}
 
-		shortener, err = shorturl.Shortener(conf.BaseURL, memStorage)
+		slugs, err := shorturl.Slugs(slugConf, memStorage)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to prepare slug generator")
+		}
+		shortener, err = shorturl.Shortener(conf.BaseURL, memStorage, slugs, canon, checks)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("failed to prepare url shortener")
 		}
 		provider = memStorage
 		statistic = memStorage
+		visitor = memStorage
 		deleter = memStorage
+		edits = memStorage
+		expirer = memStorage
+	}
+
+	// The URLs are looked up the way the shortener deduplicates them,
+	// the blocked destinations are flagged.
+	provider, err = shorturl.Provider(provider, canon, blocklist)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url provider")
 	}
 
-	servs := services.NewServices(shortener, provider, deleter, statistic, pinger)
+	visitor, err = shorturl.Visitor(visitor)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url visitor")
+	}
+
+	// The new destinations are checked the way the shortened ones are.
+	editor, err := shorturl.Editor(conf.BaseURL, edits, canon, checks)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url editor")
+	}
+
+	unlocker, err := shorturl.Unlocker(shorturl.UnlockConfig{
+		Key: conf.URLUnlockKey,
+		TTL: conf.URLUnlockTTL,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url unlocker")
+	}
+
+	sweeper, err := shorturl.Sweeper(shorturl.SweeperConfig{
+		Interval: conf.URLExpirySweepInterval,
+	}, expirer)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url expiry sweeper")
+	}
+
+	shutdown := func() error {
+		err := errors.Join(sweeper.Close(), blocklist.Close())
+		for _, fn := range shutdowns {
+			err = errors.Join(err, fn())
+		}
+		return err
+	}
+
+	servs := services.NewServices(shortener, provider, visitor, unlocker, editor, deleter, statistic, pinger)
 	return servs, shutdown
 }
 
@@ -232,7 +380,24 @@ func setHTTPRoutes(conf config, g *gin.Engine, servs *services.Services) {
 		logger.Fatal().Err(err).Msg("failed to prepare subnet validator")
 	}
 
-	httpv1.SetRoutes(g, cookieAuth, subnetValidator, servs)
+	pending, err := httpv1.Pending(httpv1.PendingConfig{
+		Status:  conf.URLPendingStatus,
+		Message: conf.URLPendingMessage,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare pending url response")
+	}
+
+	redirect, err := httpv1.Redirect(httpv1.RedirectConfig{
+		Status: conf.URLRedirectStatus,
+		Cache:  conf.URLRedirectCache,
+		MaxAge: conf.URLRedirectMaxAge,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to prepare url redirect")
+	}
+
+	httpv1.SetRoutes(g, cookieAuth, subnetValidator, pending, redirect, servs)
 }
 
 // config is the representation of shortener app settings.
@@ -246,6 +411,46 @@ type config struct {
 	TrustedSubnet   string        `json:"trusted_subnet"`
 	JWTSignKey      string        `json:"jwt_sign_key"`
 	JWTExpTime      time.Duration `json:"jwt_token_expr"`
+
+	FileStorageSegmentSize        int64         `json:"file_storage_segment_size" env:"FILE_STORAGE_SEGMENT_SIZE"`
+	FileStorageCompactionInterval time.Duration `json:"file_storage_compaction_interval" env:"FILE_STORAGE_COMPACTION_INTERVAL"`
+	FileStorageSync               string        `json:"file_storage_sync" env:"FILE_STORAGE_SYNC"`
+	FileStorageSyncInterval       time.Duration `json:"file_storage_sync_interval" env:"FILE_STORAGE_SYNC_INTERVAL"`
+	FileStorageUpgrade            bool          `json:"file_storage_upgrade" env:"FILE_STORAGE_UPGRADE"`
+
+	MemStorageSnapshotPath     string        `json:"mem_storage_snapshot_path" env:"MEM_STORAGE_SNAPSHOT_PATH"`
+	MemStorageSnapshotInterval time.Duration `json:"mem_storage_snapshot_interval" env:"MEM_STORAGE_SNAPSHOT_INTERVAL"`
+	MemStorageWAL              bool          `json:"mem_storage_wal" env:"MEM_STORAGE_WAL"`
+
+	CacheMaxEntries   int           `json:"cache_max_entries" env:"CACHE_MAX_ENTRIES"`
+	CacheMaxBytes     int64         `json:"cache_max_bytes" env:"CACHE_MAX_BYTES"`
+	CacheStatInterval time.Duration `json:"cache_stat_interval" env:"CACHE_STAT_INTERVAL"`
+
+	URLUniqueScope string `json:"url_unique_scope" env:"URL_UNIQUE_SCOPE"`
+
+	SlugStrategy string `json:"slug_strategy" env:"SLUG_STRATEGY"`
+	SlugLength   int    `json:"slug_length" env:"SLUG_LENGTH"`
+	SlugAlphabet string `json:"slug_alphabet" env:"SLUG_ALPHABET"`
+
+	URLCanonicalSteps []string `json:"url_canonical_steps" env:"URL_CANONICAL_STEPS" envSeparator:","`
+	URLTrackingParams []string `json:"url_tracking_params" env:"URL_TRACKING_PARAMS" envSeparator:","`
+
+	URLPolicyPath string `json:"url_policy_path" env:"URL_POLICY_PATH"`
+
+	URLBlocklistPath           string        `json:"url_blocklist_path" env:"URL_BLOCKLIST_PATH"`
+	URLBlocklistReloadInterval time.Duration `json:"url_blocklist_reload_interval" env:"URL_BLOCKLIST_RELOAD_INTERVAL"`
+
+	URLExpirySweepInterval time.Duration `json:"url_expiry_sweep_interval" env:"URL_EXPIRY_SWEEP_INTERVAL"`
+
+	URLUnlockKey string        `json:"url_unlock_key" env:"URL_UNLOCK_KEY"`
+	URLUnlockTTL time.Duration `json:"url_unlock_ttl" env:"URL_UNLOCK_TTL"`
+
+	URLPendingStatus  int    `json:"url_pending_status" env:"URL_PENDING_STATUS"`
+	URLPendingMessage string `json:"url_pending_message" env:"URL_PENDING_MESSAGE"`
+
+	URLRedirectStatus int           `json:"url_redirect_status" env:"URL_REDIRECT_STATUS"`
+	URLRedirectCache  string        `json:"url_redirect_cache" env:"URL_REDIRECT_CACHE"`
+	URLRedirectMaxAge time.Duration `json:"url_redirect_max_age" env:"URL_REDIRECT_MAX_AGE"`
 }
 
 // prepareConf prepres shortener app config.
@@ -266,21 +471,25 @@ func prepareConf() (config, error) {
 	flag.Parse()
 
 	envConfigFile := os.Getenv("CONFIG")
-	if len(confFile) == 0 && len(envConfigFile) == 0 {
-		return conf, nil
-	}
-
 	if len(confFile) == 0 && len(envConfigFile) != 0 {
 		confFile = envConfigFile
 	}
 
-	b, err := os.ReadFile(confFile)
-	if err != nil {
-		return config{}, fmt.Errorf("failed to read config file")
+	if len(confFile) != 0 {
+		b, err := os.ReadFile(confFile)
+		if err != nil {
+			return config{}, fmt.Errorf("failed to read config file")
+		}
+
+		if err = json.Unmarshal(b, &conf); err != nil {
+			return config{}, fmt.Errorf("failed to unmarshal config file")
+		}
 	}
 
-	if err = json.Unmarshal(b, &conf); err != nil {
-		return config{}, fmt.Errorf("failed to unmarshal config file")
+	// The storage and service settings are passed to the non-empty sub-configs,
+	// so their env vars are read here, overriding the config file.
+	if err := env.Parse(&conf); err != nil {
+		return config{}, fmt.Errorf("failed to read config env: %v", err)
 	}
 
 	return conf, nil
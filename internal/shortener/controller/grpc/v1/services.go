@@ -5,6 +5,7 @@ import (
 	"context"
 
 	"github.com/alukart32/shortener-url/internal/shortener/services"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
@@ -13,8 +14,13 @@ import (
 	urlspb "github.com/alukart32/shortener-url/pkg/proto/v1/urls"
 )
 
+// tokenVerifier defines the JWT token verifier.
+type tokenVerifier interface {
+	VerifyToken(tokenString string) (string, error)
+}
+
 // RegServices adds gRPC services to the gRPC server.
-func RegServices(srv *grpc.Server, servs *services.Services) {
+func RegServices(srv *grpc.Server, servs *services.Services, tokens tokenVerifier) {
 	// Set urls services.
 	urlspb.RegisterURLsShortenerServer(srv, newURLsShortenerService(
 		servs.Provider,
@@ -22,7 +28,9 @@ func RegServices(srv *grpc.Server, servs *services.Services) {
 	))
 	urlspb.RegisterURLsProviderServer(srv, newURLsProviderService(
 		servs.Provider,
+		tokens,
 	))
+	urlspb.RegisterURLsEditorServer(srv, newURLsEditorService(servs.Editor))
 	urlspb.RegisterURLsDeleterServer(srv, newURLsDeleterService(servs.Deleter))
 
 	// Set stat service.
@@ -56,3 +64,18 @@ func getUserIDFromCtx(ctx context.Context) string {
 	}
 	return userID
 }
+
+// getUserIDFromToken gets the optional userID from the bearer token of the
+// method request, which skips the auth. The missing or invalid token gives
+// no userID.
+func getUserIDFromToken(ctx context.Context, tokens tokenVerifier) string {
+	token, err := auth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		return ""
+	}
+	userID, err := tokens.VerifyToken(token)
+	if err != nil {
+		return ""
+	}
+	return userID
+}
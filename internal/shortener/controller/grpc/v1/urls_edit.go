@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
+	pb "github.com/alukart32/shortener-url/pkg/proto/v1/urls"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// editor defines the editor of the shortened URL destinations.
+type editor interface {
+	Edit(context.Context, string, string, string) (models.URLChange, error)
+	History(context.Context, string, string) ([]models.URLChange, error)
+	Rollback(context.Context, string, string, int) (models.URLChange, error)
+}
+
+// urlsEditorService is a representation of the proto URLsEditorServer.
+type urlsEditorService struct {
+	pb.UnimplementedURLsEditorServer
+	editor editor
+}
+
+// newURLsEditorService returns a new urlsEditorService.
+func newURLsEditorService(editor editor) *urlsEditorService {
+	return &urlsEditorService{editor: editor}
+}
+
+// EditURL changes the destination of the shortened URL.
+func (s *urlsEditorService) EditURL(ctx context.Context, in *pb.EditURLRequest) (*pb.EditURLResponse, error) {
+	if len(in.Slug) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty slug")
+	}
+
+	change, err := s.editor.Edit(ctx, getUserIDFromCtx(ctx), in.Slug, in.Raw)
+	if err != nil {
+		return nil, editStatus(err)
+	}
+	return &pb.EditURLResponse{Change: newURLChange(change)}, nil
+}
+
+// ListURLChanges lists the destination changes of the shortened URL.
+func (s *urlsEditorService) ListURLChanges(ctx context.Context, in *pb.ListURLChangesRequest) (*pb.ListURLChangesResponse, error) {
+	if len(in.Slug) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty slug")
+	}
+
+	history, err := s.editor.History(ctx, getUserIDFromCtx(ctx), in.Slug)
+	if err != nil {
+		return nil, editStatus(err)
+	}
+
+	changes := make([]*pb.URLChange, len(history))
+	for i, c := range history {
+		changes[i] = newURLChange(c)
+	}
+	return &pb.ListURLChangesResponse{Changes: changes}, nil
+}
+
+// RollbackURL changes the destination of the shortened URL back to the one of the version.
+func (s *urlsEditorService) RollbackURL(ctx context.Context, in *pb.RollbackURLRequest) (*pb.RollbackURLResponse, error) {
+	if len(in.Slug) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty slug")
+	}
+
+	change, err := s.editor.Rollback(ctx, getUserIDFromCtx(ctx), in.Slug, int(in.Version))
+	if err != nil {
+		return nil, editStatus(err)
+	}
+	return &pb.RollbackURLResponse{Change: newURLChange(change)}, nil
+}
+
+// newURLChange returns the proto change of the URL destination.
+func newURLChange(c models.URLChange) *pb.URLChange {
+	return &pb.URLChange{
+		Version:   int32(c.Version),
+		OldRaw:    c.Old,
+		NewRaw:    c.New,
+		UserId:    c.UserID,
+		ChangedAt: timestamppb.New(c.ChangedAt),
+	}
+}
+
+// editStatus returns the status error of the editing error.
+func editStatus(err error) error {
+	switch {
+	case errors.Is(err, shorturl.ErrRejected):
+		return rejectedStatus(err)
+	case errors.Is(err, shorturl.ErrInvalidDestination),
+		errors.Is(err, shorturl.ErrUnknownVersion):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, shorturl.ErrURLNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, shorturl.ErrNotOwner):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, shorturl.ErrUniqueViolation):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, shorturl.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
+	pb "github.com/alukart32/shortener-url/pkg/proto/v1/urls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type editorMock struct {
+	EditFn     func(context.Context, string, string, string) (models.URLChange, error)
+	HistoryFn  func(context.Context, string, string) ([]models.URLChange, error)
+	RollbackFn func(context.Context, string, string, int) (models.URLChange, error)
+}
+
+func (m *editorMock) Edit(ctx context.Context, userID, slug, raw string) (models.URLChange, error) {
+	if m != nil && m.EditFn != nil {
+		return m.EditFn(ctx, userID, slug, raw)
+	}
+	return models.URLChange{}, fmt.Errorf("unable to edit")
+}
+
+func (m *editorMock) History(ctx context.Context, userID, slug string) ([]models.URLChange, error) {
+	if m != nil && m.HistoryFn != nil {
+		return m.HistoryFn(ctx, userID, slug)
+	}
+	return nil, fmt.Errorf("unable to get history")
+}
+
+func (m *editorMock) Rollback(ctx context.Context, userID, slug string, version int) (models.URLChange, error) {
+	if m != nil && m.RollbackFn != nil {
+		return m.RollbackFn(ctx, userID, slug, version)
+	}
+	return models.URLChange{}, fmt.Errorf("unable to rollback")
+}
+
+func TestURLsEditorService_EditURL(t *testing.T) {
+	changedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	edited := func(err error) editor {
+		return &editorMock{
+			EditFn: func(_ context.Context, userID, slug, raw string) (models.URLChange, error) {
+				if err != nil {
+					return models.URLChange{}, err
+				}
+				return models.URLChange{Slug: slug, Version: 2, Old: "http://example.com/a",
+					New: raw, UserID: userID, ChangedAt: changedAt}, nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		in     *pb.EditURLRequest
+		editor editor
+		want   *pb.URLChange
+		code   codes.Code
+	}{
+		{
+			name:   "Destination is changed, status code: OK",
+			in:     &pb.EditURLRequest{Slug: "slug", Raw: "http://example.com/b"},
+			editor: edited(nil),
+			want: &pb.URLChange{Version: 2, OldRaw: "http://example.com/a",
+				NewRaw: "http://example.com/b", UserId: "1"},
+			code: codes.OK,
+		},
+		{
+			name: "Empty slug, status code: InvalidArgument",
+			in:   &pb.EditURLRequest{Raw: "http://example.com/b"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:   "Invalid destination, status code: InvalidArgument",
+			in:     &pb.EditURLRequest{Slug: "slug", Raw: "example"},
+			editor: edited(shorturl.ErrInvalidDestination),
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "Rejected destination, status code: InvalidArgument",
+			in:     &pb.EditURLRequest{Slug: "slug", Raw: "ftp://example.com/b"},
+			editor: edited(fmt.Errorf("%w: %w", shorturl.ErrRejected, &shorturl.Rejection{Reason: "scheme"})),
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "URL not found, status code: NotFound",
+			in:     &pb.EditURLRequest{Slug: "slug", Raw: "http://example.com/b"},
+			editor: edited(shorturl.ErrURLNotFound),
+			code:   codes.NotFound,
+		},
+		{
+			name:   "URL of another user, status code: PermissionDenied",
+			in:     &pb.EditURLRequest{Slug: "slug", Raw: "http://example.com/b"},
+			editor: edited(shorturl.ErrNotOwner),
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "Destination of another URL, status code: AlreadyExists",
+			in:     &pb.EditURLRequest{Slug: "slug", Raw: "http://example.com/b"},
+			editor: edited(shorturl.ErrUniqueViolation),
+			code:   codes.AlreadyExists,
+		},
+		{
+			name:   "Edit error, status code: Internal",
+			in:     &pb.EditURLRequest{Slug: "slug", Raw: "http://example.com/b"},
+			editor: &editorMock{},
+			code:   codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, closer := urlsEditorClient(context.Background(), tt.editor)
+			defer closer()
+
+			reqCtx := metadata.NewOutgoingContext(
+				context.Background(),
+				metadata.Pairs("user_id", "1"))
+			resp, err := client.EditURL(reqCtx, tt.in)
+			if e, ok := status.FromError(err); ok {
+				assert.EqualValues(t, tt.code, e.Code(),
+					"Expected status code: %d, got %d", tt.code, e.Code())
+			} else {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if tt.want == nil {
+				return
+			}
+			require.NotNil(t, resp.Change)
+			assert.Equal(t, tt.want.Version, resp.Change.Version)
+			assert.Equal(t, tt.want.OldRaw, resp.Change.OldRaw)
+			assert.Equal(t, tt.want.NewRaw, resp.Change.NewRaw)
+			assert.Equal(t, tt.want.UserId, resp.Change.UserId)
+			assert.Equal(t, changedAt, resp.Change.ChangedAt.AsTime())
+		})
+	}
+}
+
+func TestURLsEditorService_ListURLChanges(t *testing.T) {
+	client, closer := urlsEditorClient(context.Background(), &editorMock{
+		HistoryFn: func(_ context.Context, userID, slug string) ([]models.URLChange, error) {
+			if slug != "slug" {
+				return nil, shorturl.ErrNotOwner
+			}
+			return []models.URLChange{
+				{Slug: slug, Version: 2, Old: "http://example.com/a", New: "http://example.com/b", UserID: userID},
+				{Slug: slug, Version: 3, Old: "http://example.com/b", New: "http://example.com/c", UserID: userID},
+			}, nil
+		},
+	})
+	defer closer()
+
+	reqCtx := metadata.NewOutgoingContext(
+		context.Background(),
+		metadata.Pairs("user_id", "1"))
+	resp, err := client.ListURLChanges(reqCtx, &pb.ListURLChangesRequest{Slug: "slug"})
+	require.NoError(t, err)
+	require.Len(t, resp.Changes, 2)
+	assert.EqualValues(t, 3, resp.Changes[1].Version)
+	assert.Equal(t, "http://example.com/c", resp.Changes[1].NewRaw)
+
+	_, err = client.ListURLChanges(reqCtx, &pb.ListURLChangesRequest{Slug: "other"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestURLsEditorService_RollbackURL(t *testing.T) {
+	client, closer := urlsEditorClient(context.Background(), &editorMock{
+		RollbackFn: func(_ context.Context, _, slug string, version int) (models.URLChange, error) {
+			if version > 3 {
+				return models.URLChange{}, shorturl.ErrUnknownVersion
+			}
+			if version == 2 {
+				return models.URLChange{}, shorturl.ErrVersionConflict
+			}
+			return models.URLChange{Slug: slug, Version: 4, New: "http://example.com/a"}, nil
+		},
+	})
+	defer closer()
+
+	reqCtx := metadata.NewOutgoingContext(
+		context.Background(),
+		metadata.Pairs("user_id", "1"))
+	resp, err := client.RollbackURL(reqCtx, &pb.RollbackURLRequest{Slug: "slug", Version: 1})
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, resp.Change.Version)
+	assert.Equal(t, "http://example.com/a", resp.Change.NewRaw)
+
+	_, err = client.RollbackURL(reqCtx, &pb.RollbackURLRequest{Slug: "slug", Version: 5})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.RollbackURL(reqCtx, &pb.RollbackURLRequest{Slug: "slug", Version: 2})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+}
+
+func urlsEditorClient(
+	ctx context.Context,
+	e editor,
+) (pb.URLsEditorClient, func()) {
+	buffer := 1024 * 1024
+	lis := bufconn.Listen(buffer)
+
+	baseServer := grpc.NewServer()
+	pb.RegisterURLsEditorServer(
+		baseServer,
+		newURLsEditorService(e),
+	)
+	go func() {
+		if err := baseServer.Serve(lis); err != nil {
+			log.Printf("error serving server: %v", err)
+		}
+	}()
+
+	conn, err := grpc.DialContext(ctx, "",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("error connecting to server: %v", err)
+	}
+
+	closer := func() {
+		err := lis.Close()
+		if err != nil {
+			log.Printf("error closing listener: %v", err)
+		}
+		baseServer.Stop()
+	}
+
+	client := pb.NewURLsEditorClient(conn)
+	return client, closer
+}
@@ -2,11 +2,14 @@ package v1
 
 import (
 	"context"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
 	pb "github.com/alukart32/shortener-url/pkg/proto/v1/urls"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // provider defines the shortened URL provider.
@@ -19,16 +22,20 @@ type provider interface {
 type urlsProviderService struct {
 	pb.UnimplementedURLsProviderServer
 	provider provider
+	tokens   tokenVerifier
 }
 
 // newURLsProviderService returns a new urlsProviderService.
-func newURLsProviderService(provider provider) *urlsProviderService {
+func newURLsProviderService(provider provider, tokens tokenVerifier) *urlsProviderService {
 	return &urlsProviderService{
 		provider: provider,
+		tokens:   tokens,
 	}
 }
 
-// GetShortenedURL gets a shortened URL by the slug value.
+// GetShortenedURL gets a shortened URL by the slug value. The method skips the
+// auth, so the owner is recognized by the optional bearer token. The pending and
+// the password-protected URLs are got by their owner only.
 func (s *urlsProviderService) GetShortenedURL(ctx context.Context, in *pb.GetShortenedURLRequest) (*pb.GetShortenedURLResponse, error) {
 	if len(in.Slug) == 0 {
 		return nil, status.Errorf(codes.InvalidArgument, "empty slug")
@@ -44,15 +51,55 @@ func (s *urlsProviderService) GetShortenedURL(ctx context.Context, in *pb.GetSho
 	if shortenedURL.IsDeleted {
 		return nil, status.Error(codes.Unknown, "shortened URL was deleted")
 	}
+	userID := getUserIDFromToken(ctx, s.tokens)
+	byOwner := len(userID) != 0 && shortenedURL.UserID == userID
+	now := time.Now()
+	if shortenedURL.Expired(now) {
+		return nil, status.Errorf(codes.NotFound, "shortened URL by %v has expired", in.Slug)
+	}
+	if shortenedURL.Deactivated(now) {
+		return nil, status.Errorf(codes.NotFound, "shortened URL by %v has been deactivated", in.Slug)
+	}
+	if shortenedURL.Pending(now) && !byOwner {
+		return nil, status.Errorf(codes.FailedPrecondition, "shortened URL by %v is not active until %v",
+			in.Slug, shortenedURL.ActivateAt.UTC().Format(time.RFC3339))
+	}
+	if shortenedURL.Flagged() {
+		return nil, withReason(
+			status.New(codes.FailedPrecondition, "shortened URL destination is blocked"),
+			shorturl.RejectedThreat, shortenedURL.Threat)
+	}
+	if shortenedURL.Protected() && !byOwner {
+		return nil, status.Errorf(codes.PermissionDenied, "shortened URL by %v is password-protected", in.Slug)
+	}
 
 	var response pb.GetShortenedURLResponse
 	response.ShortUrl = &pb.GetShortenedURLResponse_ShortenedURL{
-		UserId:    shortenedURL.UserID,
-		CorrId:    shortenedURL.CorrID,
-		Raw:       shortenedURL.Raw,
-		Slug:      shortenedURL.Slug,
-		Value:     shortenedURL.Value,
-		IsDeleted: shortenedURL.IsDeleted,
+		UserId:         shortenedURL.UserID,
+		CorrId:         shortenedURL.CorrID,
+		Raw:            shortenedURL.Raw,
+		Slug:           shortenedURL.Slug,
+		Value:          shortenedURL.Value,
+		IsDeleted:      shortenedURL.IsDeleted,
+		MaxVisits:      int32(shortenedURL.MaxVisits),
+		Protected:      shortenedURL.Protected(),
+		RedirectStatus: int32(shortenedURL.RedirectStatus),
+		CachePolicy:    shortenedURL.CachePolicy,
+		QueryMode:      shortenedURL.QueryMode,
+		FixedParams:    models.DecodeParams(shortenedURL.FixedParams),
+		ForwardPath:    shortenedURL.ForwardPath,
+	}
+	if left := shortenedURL.VisitsLeft(); left >= 0 {
+		response.ShortUrl.VisitsLeft = int32(left)
+	}
+	if !shortenedURL.ExpiresAt.IsZero() {
+		response.ShortUrl.ExpiresAt = timestamppb.New(shortenedURL.ExpiresAt)
+	}
+	if !shortenedURL.ActivateAt.IsZero() {
+		response.ShortUrl.ActivateAt = timestamppb.New(shortenedURL.ActivateAt)
+	}
+	if !shortenedURL.DeactivateAt.IsZero() {
+		response.ShortUrl.DeactivateAt = timestamppb.New(shortenedURL.DeactivateAt)
 	}
 	return &response, nil
 }
@@ -73,8 +120,19 @@ func (s *urlsProviderService) ListURLs(ctx context.Context, in *pb.ListURLsReque
 	list := make([]*pb.ListURLsResponse_URL, len(urls))
 	for i, u := range urls {
 		list[i] = &pb.ListURLsResponse_URL{
-			Raw:      u.Raw,
-			ShortUrl: u.Value,
+			Raw:       u.Raw,
+			ShortUrl:  u.Value,
+			MaxVisits: int32(u.MaxVisits),
+			Protected: u.Protected(),
+		}
+		if left := u.VisitsLeft(); left >= 0 {
+			list[i].VisitsLeft = int32(left)
+		}
+		if !u.ActivateAt.IsZero() {
+			list[i].ActivateAt = timestamppb.New(u.ActivateAt)
+		}
+		if !u.DeactivateAt.IsZero() {
+			list[i].DeactivateAt = timestamppb.New(u.DeactivateAt)
 		}
 	}
 
@@ -5,17 +5,22 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
 	pb "github.com/alukart32/shortener-url/pkg/proto/v1/urls"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type providerMock struct {
@@ -37,19 +42,56 @@ func (m *providerMock) CollectByUser(ctx context.Context, userID string) ([]mode
 	return nil, fmt.Errorf("unable to collect URLs.")
 }
 
+type tokenVerifierMock struct {
+	VerifyTokenFn func(string) (string, error)
+}
+
+func (m *tokenVerifierMock) VerifyToken(tokenString string) (string, error) {
+	if m != nil && m.VerifyTokenFn != nil {
+		return m.VerifyTokenFn(tokenString)
+	}
+	return "", fmt.Errorf("unable to verify the token")
+}
+
+// testTokens verifies the tokens like "token_<userID>".
+var testTokens = &tokenVerifierMock{
+	VerifyTokenFn: func(tokenString string) (string, error) {
+		if userID, ok := strings.CutPrefix(tokenString, "token_"); ok {
+			return userID, nil
+		}
+		return "", fmt.Errorf("invalid token")
+	},
+}
+
 func TestURLsProviderService_GetShortenedURL(t *testing.T) {
 	type services struct {
 		provider provider
 	}
 	type want struct {
-		data *pb.GetShortenedURLResponse
-		code codes.Code
+		data   *pb.GetShortenedURLResponse
+		code   codes.Code
+		reason string
+	}
+	protectedURL := func(ctx context.Context, s string) (models.ShortenedURL, error) {
+		url := models.NewShortenedURL("1", "1", "http://example.com/doc",
+			"tmp_slug", "http://localhost:8080/tmp_slug")
+		url.PasswordHash = "hash"
+		return url, nil
+	}
+	scheduledURL := func(ctx context.Context, s string) (models.ShortenedURL, error) {
+		url := models.NewShortenedURL("1", "1", "http://example.com/doc",
+			"tmp_slug", "http://localhost:8080/tmp_slug")
+		url.ActivateAt = time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)
+		url.DeactivateAt = time.Date(2101, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return url, nil
 	}
 	tests := []struct {
-		want want
-		serv services
-		req  *pb.GetShortenedURLRequest
-		name string
+		want   want
+		serv   services
+		req    *pb.GetShortenedURLRequest
+		token  string
+		userID string
+		name   string
 	}{
 		{
 			name: "URL by 112Sd exists, status code: Ok",
@@ -122,19 +164,248 @@ func TestURLsProviderService_GetShortenedURL(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "URL by 112Sd expires later, status code: Ok",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			want: want{
+				data: &pb.GetShortenedURLResponse{
+					ShortUrl: &pb.GetShortenedURLResponse_ShortenedURL{
+						UserId:    "1",
+						CorrId:    "1",
+						Raw:       "http://example.com/query_1",
+						Slug:      "tmp_slug",
+						Value:     "http://localhost:8080/tmp_slug",
+						ExpiresAt: &timestamppb.Timestamp{Seconds: 1893456000},
+					},
+				},
+				code: codes.OK,
+			},
+			serv: services{
+				provider: &providerMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.ExpiresAt = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd has expired, status code: NotFound",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			want: want{
+				code: codes.NotFound,
+			},
+			serv: services{
+				provider: &providerMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.ExpiresAt = time.Now().Add(-time.Second)
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd has visits left, status code: Ok",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			want: want{
+				data: &pb.GetShortenedURLResponse{
+					ShortUrl: &pb.GetShortenedURLResponse_ShortenedURL{
+						UserId:     "1",
+						CorrId:     "1",
+						Raw:        "http://example.com/query_1",
+						Slug:       "tmp_slug",
+						Value:      "http://localhost:8080/tmp_slug",
+						MaxVisits:  3,
+						VisitsLeft: 1,
+					},
+				},
+				code: codes.OK,
+			},
+			serv: services{
+				provider: &providerMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.MaxVisits = 3
+						url.Visits = 2
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd is protected, status code: PermissionDenied",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			token: "token_2",
+			want: want{
+				code: codes.PermissionDenied,
+			},
+			serv: services{
+				provider: &providerMock{GetBySlugFn: protectedURL},
+			},
+		},
+		{
+			name: "URL by 112Sd is protected, invalid token, status code: PermissionDenied",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			token: "1",
+			want: want{
+				code: codes.PermissionDenied,
+			},
+			serv: services{
+				provider: &providerMock{GetBySlugFn: protectedURL},
+			},
+		},
+		{
+			name: "URL by 112Sd is protected, user_id without token, status code: PermissionDenied",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			userID: "1",
+			want: want{
+				code: codes.PermissionDenied,
+			},
+			serv: services{
+				provider: &providerMock{GetBySlugFn: protectedURL},
+			},
+		},
+		{
+			name: "URL by 112Sd is protected, got by owner, status code: Ok",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			token: "token_1",
+			want: want{
+				data: &pb.GetShortenedURLResponse{
+					ShortUrl: &pb.GetShortenedURLResponse_ShortenedURL{
+						UserId:    "1",
+						CorrId:    "1",
+						Raw:       "http://example.com/doc",
+						Slug:      "tmp_slug",
+						Value:     "http://localhost:8080/tmp_slug",
+						Protected: true,
+					},
+				},
+				code: codes.OK,
+			},
+			serv: services{
+				provider: &providerMock{GetBySlugFn: protectedURL},
+			},
+		},
+		{
+			name: "URL by 112Sd is not active yet, status code: FailedPrecondition",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			token: "token_2",
+			want: want{
+				code: codes.FailedPrecondition,
+			},
+			serv: services{
+				provider: &providerMock{GetBySlugFn: scheduledURL},
+			},
+		},
+		{
+			name: "URL by 112Sd is not active yet, got by owner, status code: Ok",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			token: "token_1",
+			want: want{
+				data: &pb.GetShortenedURLResponse{
+					ShortUrl: &pb.GetShortenedURLResponse_ShortenedURL{
+						UserId:       "1",
+						CorrId:       "1",
+						Raw:          "http://example.com/doc",
+						Slug:         "tmp_slug",
+						Value:        "http://localhost:8080/tmp_slug",
+						ActivateAt:   &timestamppb.Timestamp{Seconds: 4102444800},
+						DeactivateAt: &timestamppb.Timestamp{Seconds: 4133980800},
+					},
+				},
+				code: codes.OK,
+			},
+			serv: services{
+				provider: &providerMock{GetBySlugFn: scheduledURL},
+			},
+		},
+		{
+			name: "URL by 112Sd has been deactivated, status code: NotFound",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			token: "token_1",
+			want: want{
+				code: codes.NotFound,
+			},
+			serv: services{
+				provider: &providerMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/doc",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.DeactivateAt = time.Now().Add(-time.Second)
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd is blocked, status code: FailedPrecondition",
+			req: &pb.GetShortenedURLRequest{
+				Slug: "112Sd",
+			},
+			want: want{
+				code:   codes.FailedPrecondition,
+				reason: shorturl.RejectedThreat,
+			},
+			serv: services{
+				provider: &providerMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://evil.com/login",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.Threat = "evil.com"
+						return url, nil
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client, closer :=
-				urlsProviderClient(context.Background(), tt.serv.provider)
+				urlsProviderClient(context.Background(), tt.serv.provider, testTokens)
 			defer closer()
 
-			resp, err := client.GetShortenedURL(context.Background(), tt.req)
+			md := metadata.MD{}
+			if len(tt.token) != 0 {
+				md.Set("authorization", "bearer "+tt.token)
+			}
+			if len(tt.userID) != 0 {
+				md.Set("user_id", tt.userID)
+			}
+			reqCtx := metadata.NewOutgoingContext(context.Background(), md)
+			resp, err := client.GetShortenedURL(reqCtx, tt.req)
 			if err != nil {
 				if e, ok := status.FromError(err); ok {
 					assert.EqualValues(t, tt.want.code, e.Code(),
 						"Expected status code: %d, got %d", tt.want.code, e.Code())
+					if len(tt.want.reason) != 0 {
+						assertRejection(t, e, tt.want.reason)
+					}
 					return
 				} else {
 					t.Fatalf("failed to parse: %v", err)
@@ -237,6 +508,78 @@ func TestURLsProviderService_ListURLs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "List visit-limited URLs, status code: Ok",
+			userID: "1",
+			want: want{
+				data: &pb.ListURLsResponse{
+					CollectedUrls: []*pb.ListURLsResponse_URL{
+						{
+							Raw:        "http://demo.com/1",
+							ShortUrl:   "http://localhost:8080/slug1",
+							MaxVisits:  2,
+							VisitsLeft: 1,
+						},
+						{
+							Raw:      "http://demo.com/2",
+							ShortUrl: "http://localhost:8080/slug2",
+						},
+					},
+				},
+				code: codes.OK,
+			},
+			serv: services{
+				provider: &providerMock{
+					CollectByUserFn: func(ctx context.Context, userID string) ([]models.ShortenedURL, error) {
+						records := []models.ShortenedURL{
+							{
+								Raw:       "http://demo.com/1",
+								Value:     "http://localhost:8080/slug1",
+								MaxVisits: 2,
+								Visits:    1,
+							},
+							{
+								Raw:   "http://demo.com/2",
+								Value: "http://localhost:8080/slug2",
+							},
+						}
+						return records, nil
+					},
+				},
+			},
+		},
+		{
+			name:   "List scheduled URLs, status code: Ok",
+			userID: "1",
+			want: want{
+				data: &pb.ListURLsResponse{
+					CollectedUrls: []*pb.ListURLsResponse_URL{
+						{
+							Raw:          "http://demo.com/1",
+							ShortUrl:     "http://localhost:8080/slug1",
+							ActivateAt:   &timestamppb.Timestamp{Seconds: 4102444800},
+							DeactivateAt: &timestamppb.Timestamp{Seconds: 4133980800},
+						},
+					},
+				},
+				code: codes.OK,
+			},
+			serv: services{
+				provider: &providerMock{
+					CollectByUserFn: func(ctx context.Context, userID string) ([]models.ShortenedURL, error) {
+						records := []models.ShortenedURL{
+							{
+								Raw:          "http://demo.com/1",
+								Value:        "http://localhost:8080/slug1",
+								ActivateAt:   time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+								DeactivateAt: time.Date(2101, time.January, 1, 0, 0, 0, 0, time.UTC),
+							},
+						}
+						return records, nil
+					},
+				},
+			},
+		},
 		{
 			name:   "No URLs, status code: Unknown",
 			userID: "1",
@@ -256,7 +599,7 @@ func TestURLsProviderService_ListURLs(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client, closer :=
-				urlsProviderClient(context.Background(), tt.serv.provider)
+				urlsProviderClient(context.Background(), tt.serv.provider, testTokens)
 			defer closer()
 
 			reqCtx := metadata.NewOutgoingContext(
@@ -273,7 +616,11 @@ func TestURLsProviderService_ListURLs(t *testing.T) {
 				}
 			}
 
-			assert.EqualValues(t, len(tt.want.data.CollectedUrls), len(resp.CollectedUrls))
+			require.EqualValues(t, len(tt.want.data.CollectedUrls), len(resp.CollectedUrls))
+			for i, v := range resp.CollectedUrls {
+				assert.Equal(t, tt.want.data.CollectedUrls[i].MaxVisits, v.MaxVisits)
+				assert.Equal(t, tt.want.data.CollectedUrls[i].VisitsLeft, v.VisitsLeft)
+			}
 		})
 	}
 }
@@ -281,6 +628,7 @@ func TestURLsProviderService_ListURLs(t *testing.T) {
 func urlsProviderClient(
 	ctx context.Context,
 	prov provider,
+	tokens tokenVerifier,
 ) (pb.URLsProviderClient, func()) {
 	buffer := 1024 * 1024
 	lis := bufconn.Listen(buffer)
@@ -288,7 +636,7 @@ func urlsProviderClient(
 	baseServer := grpc.NewServer()
 	pb.RegisterURLsProviderServer(
 		baseServer,
-		newURLsProviderService(prov),
+		newURLsProviderService(prov, tokens),
 	)
 	go func() {
 		if err := baseServer.Serve(lis); err != nil {
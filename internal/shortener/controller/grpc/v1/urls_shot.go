@@ -3,23 +3,26 @@ package v1
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
 	pb "github.com/alukart32/shortener-url/pkg/proto/v1/urls"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // getterByURL defines the shortened URL provider by raw URL.
 type getterByURL interface {
-	GetByURL(context.Context, string) (models.ShortenedURL, error)
+	GetByURL(context.Context, string, string) (models.ShortenedURL, error)
 }
 
 // shortener defines the url shortener.
 type shortener interface {
 	Short(context.Context, models.URL) (string, error)
-	Batch(context.Context, []models.URL) ([]models.ShortenedURL, error)
+	Batch(context.Context, []models.URL) ([]models.BatchedURL, error)
 }
 
 // urlsShortenerService is a representation of the proto URLsShortenerServer.
@@ -40,17 +43,45 @@ func newURLsShortenerService(
 	}
 }
 
-// ShortURL shortens the URL. If the URL has been shortened, its shortened form is returned.
+// ShortURL shortens the URL with the optional custom alias. If the URL has been shortened,
+// its shortened form is returned.
 func (s *urlsShortenerService) ShortURL(ctx context.Context, in *pb.ShortURLRequest) (*pb.ShortURLResponse, error) {
 	var response pb.ShortURLResponse
 
 	userID := getUserIDFromCtx(ctx)
 
-	shortenedURL, err := s.shortener.Short(ctx, models.NewURL(userID, "", in.Raw))
+	url := models.NewURL(userID, "", in.Raw)
+	url.Alias = in.Alias
+	withExpiry(&url, in.ExpiresAt, in.Ttl)
+	url.MaxVisits = int(in.MaxVisits)
+	url.Password = in.Password
+	url.RedirectStatus = int(in.RedirectStatus)
+	url.CachePolicy = in.CachePolicy
+	url.QueryMode = in.QueryMode
+	url.FixedParams = models.EncodeParams(in.FixedParams)
+	url.ForwardPath = in.ForwardPath
+	withWindow(&url, in.ActivateAt, in.DeactivateAt)
+
+	shortenedURL, err := s.shortener.Short(ctx, url)
 	if err != nil {
+		if errors.Is(err, shorturl.ErrAliasTaken) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		if errors.Is(err, shorturl.ErrInvalidAlias) ||
+			errors.Is(err, shorturl.ErrInvalidExpiry) ||
+			errors.Is(err, shorturl.ErrInvalidMaxVisits) ||
+			errors.Is(err, shorturl.ErrInvalidPassword) ||
+			errors.Is(err, shorturl.ErrInvalidRedirect) ||
+			errors.Is(err, shorturl.ErrInvalidQuery) ||
+			errors.Is(err, shorturl.ErrInvalidWindow) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, shorturl.ErrRejected) {
+			return nil, rejectedStatus(err)
+		}
 		if errors.Is(err, shorturl.ErrUniqueViolation) {
-			// Get an existing shortened URL.
-			shortURL, err := s.provider.GetByURL(ctx, in.Raw)
+			// Get the existing shortened URL of the user.
+			shortURL, err := s.provider.GetByURL(ctx, userID, in.Raw)
 			if err != nil {
 				return nil, status.Error(codes.Internal, err.Error())
 			}
@@ -68,7 +99,7 @@ func (s *urlsShortenerService) ShortURL(ctx context.Context, in *pb.ShortURLRequ
 	return &response, nil
 }
 
-// BatchURLs shortens a list of URLs.
+// BatchURLs shortens a list of URLs. Each URL says whether it was created or already existed.
 func (s *urlsShortenerService) BatchURLs(ctx context.Context, in *pb.BatchURLsRequest) (*pb.BatchURLsResponse, error) {
 	var response pb.BatchURLsResponse
 
@@ -77,25 +108,105 @@ func (s *urlsShortenerService) BatchURLs(ctx context.Context, in *pb.BatchURLsRe
 	urlsToBatch := make([]models.URL, len(in.Url))
 	for i, v := range in.Url {
 		urlsToBatch[i] = models.NewURL(userID, v.CorrId, v.Raw)
+		withExpiry(&urlsToBatch[i], v.ExpiresAt, v.Ttl)
+		urlsToBatch[i].MaxVisits = int(v.MaxVisits)
+		urlsToBatch[i].Password = v.Password
+		urlsToBatch[i].RedirectStatus = int(v.RedirectStatus)
+		urlsToBatch[i].CachePolicy = v.CachePolicy
+		urlsToBatch[i].QueryMode = v.QueryMode
+		urlsToBatch[i].FixedParams = models.EncodeParams(v.FixedParams)
+		urlsToBatch[i].ForwardPath = v.ForwardPath
+		withWindow(&urlsToBatch[i], v.ActivateAt, v.DeactivateAt)
 	}
 	urls, err := s.shortener.Batch(ctx, urlsToBatch)
 	if err != nil {
+		if errors.Is(err, shorturl.ErrRejected) {
+			return nil, rejectedStatus(err)
+		}
 		if errors.Is(err, shorturl.ErrEmptyBatch) ||
-			errors.Is(err, shorturl.ErrInvalidCreation) {
+			errors.Is(err, shorturl.ErrInvalidCreation) ||
+			errors.Is(err, shorturl.ErrInvalidExpiry) ||
+			errors.Is(err, shorturl.ErrInvalidMaxVisits) ||
+			errors.Is(err, shorturl.ErrInvalidPassword) ||
+			errors.Is(err, shorturl.ErrInvalidRedirect) ||
+			errors.Is(err, shorturl.ErrInvalidQuery) ||
+			errors.Is(err, shorturl.ErrInvalidWindow) {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
+		if errors.Is(err, shorturl.ErrUniqueViolation) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
 
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	respData := make([]*pb.BatchURLsResponse_URL, len(urls))
 	for i, u := range urls {
+		status := pb.BatchURLsResponse_CREATED
+		if u.Status == models.BatchExisted {
+			status = pb.BatchURLsResponse_EXISTED
+		}
 		respData[i] = &pb.BatchURLsResponse_URL{
 			CorrId:   u.CorrID,
 			ShortUrl: u.Value,
+			Status:   status,
 		}
 	}
 
 	response.BatchedUrls = respData
 	return &response, nil
 }
+
+// maxTTL is the longest TTL in seconds the duration can hold.
+const maxTTL = int64(1<<63-1) / int64(time.Second)
+
+// withExpiry sets the optional expiry of the URL. The TTL is in seconds,
+// the TTL longer than the duration can hold is treated as invalid.
+func withExpiry(url *models.URL, expiresAt *timestamppb.Timestamp, ttl int64) {
+	if expiresAt != nil {
+		url.ExpiresAt = expiresAt.AsTime()
+	}
+	if ttl > maxTTL {
+		url.TTL = -1
+		return
+	}
+	url.TTL = time.Duration(ttl) * time.Second
+}
+
+// withWindow sets the optional activation window of the URL.
+func withWindow(url *models.URL, activateAt, deactivateAt *timestamppb.Timestamp) {
+	if activateAt != nil {
+		url.ActivateAt = activateAt.AsTime()
+	}
+	if deactivateAt != nil {
+		url.DeactivateAt = deactivateAt.AsTime()
+	}
+}
+
+// rejectionDomain is the domain of the rejection error details.
+const rejectionDomain = "shortener"
+
+// rejectedStatus returns the InvalidArgument status of the URL rejected by the policy.
+// The rejection reason is reported by the ErrorInfo details.
+func rejectedStatus(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+
+	var rej *shorturl.Rejection
+	if !errors.As(err, &rej) {
+		return st.Err()
+	}
+	return withReason(st, rej.Reason, rej.Detail)
+}
+
+// withReason returns the status error with the ErrorInfo details of the reason.
+func withReason(st *status.Status, reason, detail string) error {
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   rejectionDomain,
+		Metadata: map[string]string{"detail": detail},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -5,23 +5,28 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
 	pb "github.com/alukart32/shortener-url/pkg/proto/v1/urls"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type shortenerMock struct {
 	ShortFn func(context.Context, models.URL) (string, error)
-	BatchFn func(context.Context, []models.URL) ([]models.ShortenedURL, error)
+	BatchFn func(context.Context, []models.URL) ([]models.BatchedURL, error)
 }
 
 func (m *shortenerMock) Short(ctx context.Context, url models.URL) (string, error) {
@@ -31,7 +36,7 @@ func (m *shortenerMock) Short(ctx context.Context, url models.URL) (string, erro
 	return "", fmt.Errorf("unable to short URL")
 }
 
-func (m *shortenerMock) Batch(ctx context.Context, urls []models.URL) ([]models.ShortenedURL, error) {
+func (m *shortenerMock) Batch(ctx context.Context, urls []models.URL) ([]models.BatchedURL, error) {
 	if m != nil && m.BatchFn != nil {
 		return m.BatchFn(ctx, urls)
 	}
@@ -39,12 +44,12 @@ func (m *shortenerMock) Batch(ctx context.Context, urls []models.URL) ([]models.
 }
 
 type getterByURLMock struct {
-	GetByURLFn func(context.Context, string) (models.ShortenedURL, error)
+	GetByURLFn func(context.Context, string, string) (models.ShortenedURL, error)
 }
 
-func (m *getterByURLMock) GetByURL(ctx context.Context, raw string) (models.ShortenedURL, error) {
+func (m *getterByURLMock) GetByURL(ctx context.Context, userID, raw string) (models.ShortenedURL, error) {
 	if m != nil && m.GetByURLFn != nil {
-		return m.GetByURLFn(ctx, raw)
+		return m.GetByURLFn(ctx, userID, raw)
 	}
 	return models.ShortenedURL{}, fmt.Errorf("unable to short URL")
 }
@@ -59,8 +64,9 @@ func TestURLsShortenerService_ShortURL(t *testing.T) {
 		userID string
 	}
 	type want struct {
-		data *pb.ShortURLResponse
-		code codes.Code
+		data   *pb.ShortURLResponse
+		code   codes.Code
+		reason string
 	}
 	tests := []struct {
 		req  req
@@ -111,13 +117,410 @@ func TestURLsShortenerService_ShortURL(t *testing.T) {
 					},
 				},
 				getter: &getterByURLMock{
-					GetByURLFn: func(_ context.Context, s string) (models.ShortenedURL, error) {
+					GetByURLFn: func(_ context.Context, userID, s string) (models.ShortenedURL, error) {
+						if userID != "1" {
+							return models.ShortenedURL{}, fmt.Errorf("unexpected user: %v", userID)
+						}
 						return models.NewShortenedURL("1", "1", "https//go-dev",
 							"tmp_slug", "http//localhost:8080/tmp_slug"), nil
 					},
 				},
 			},
 		},
+		{
+			name: "New URL with alias, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:   "http://demo.com",
+					Alias: "spring-sale",
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/spring-sale",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if u.Alias != "spring-sale" {
+							return "", fmt.Errorf("unexpected alias: %v", u.Alias)
+						}
+						return "https://localhost:8080/spring-sale", nil
+					},
+				},
+			},
+		},
+		{
+			name: "New URL with TTL, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw: "http://demo.com",
+					Ttl: 3600,
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/slug",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if u.TTL != time.Hour || !u.ExpiresAt.IsZero() {
+							return "", fmt.Errorf("unexpected expiry: %v", u)
+						}
+						return "https://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "New URL with expiry, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:       "http://demo.com",
+					ExpiresAt: timestamppb.New(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)),
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/slug",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if !u.ExpiresAt.Equal(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)) || u.TTL != 0 {
+							return "", fmt.Errorf("unexpected expiry: %v", u)
+						}
+						return "https://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "New one-time URL, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:       "http://demo.com",
+					MaxVisits: 1,
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/slug",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if u.MaxVisits != 1 {
+							return "", fmt.Errorf("unexpected max visits: %v", u.MaxVisits)
+						}
+						return "https://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid max visits, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:       "http://demo.com",
+					MaxVisits: -1,
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidMaxVisits
+					},
+				},
+			},
+		},
+		{
+			name: "New protected URL, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:      "http://demo.com/doc",
+					Password: "secret",
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/slug",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if u.Password != "secret" {
+							return "", fmt.Errorf("unexpected password: %v", u.Password)
+						}
+						return "https://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid password, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:      "http://demo.com/doc",
+					Password: "long",
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidPassword
+					},
+				},
+			},
+		},
+		{
+			name: "New scheduled URL, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:          "http://demo.com/doc",
+					ActivateAt:   &timestamppb.Timestamp{Seconds: 4102444800},
+					DeactivateAt: &timestamppb.Timestamp{Seconds: 4133980800},
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/slug",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if !u.ActivateAt.Equal(time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)) ||
+							!u.DeactivateAt.Equal(time.Date(2101, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+							return "", fmt.Errorf("unexpected window: %v - %v", u.ActivateAt, u.DeactivateAt)
+						}
+						return "https://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid activation window, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:          "http://demo.com/doc",
+					DeactivateAt: &timestamppb.Timestamp{Seconds: 1},
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidWindow
+					},
+				},
+			},
+		},
+		{
+			name: "New permanent URL, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:            "http://demo.com/seo",
+					RedirectStatus: http.StatusPermanentRedirect,
+					CachePolicy:    models.CachePublic,
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/slug",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if u.RedirectStatus != http.StatusPermanentRedirect || u.CachePolicy != models.CachePublic {
+							return "", fmt.Errorf("unexpected redirect: %d %s", u.RedirectStatus, u.CachePolicy)
+						}
+						return "https://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "New URL forwarding the query, status code: Ok",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:         "http://demo.com/docs",
+					QueryMode:   models.QueryOverride,
+					FixedParams: map[string]string{"utm_source": "newsletter"},
+					ForwardPath: true,
+				},
+			},
+			want: want{
+				code: codes.OK,
+				data: &pb.ShortURLResponse{
+					ShortUrl: "https://localhost:8080/slug",
+				},
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(ctx context.Context, u models.URL) (string, error) {
+						if u.QueryMode != models.QueryOverride || u.FixedParams != "utm_source=newsletter" || !u.ForwardPath {
+							return "", fmt.Errorf("unexpected forwarding: %s %s %t", u.QueryMode, u.FixedParams, u.ForwardPath)
+						}
+						return "https://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid query forwarding, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:       "http://demo.com/docs",
+					QueryMode: "append",
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidQuery
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid redirect, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:         "http://demo.com/seo",
+					CachePolicy: "forever",
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidRedirect
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid expiry, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw: "http://demo.com",
+					Ttl: -1,
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidExpiry
+					},
+				},
+			},
+		},
+		{
+			name: "Taken alias, status code: AlreadyExists",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:   "http://demo.com",
+					Alias: "spring-sale",
+				},
+			},
+			want: want{
+				code: codes.AlreadyExists,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrAliasTaken
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid alias, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw:   "http://demo.com",
+					Alias: "api",
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidAlias
+					},
+				},
+			},
+		},
+		{
+			name: "Rejected URL, status code: InvalidArgument",
+			req: req{
+				userID: "1",
+				in: &pb.ShortURLRequest{
+					Raw: "http://localhost:8080/slug",
+				},
+			},
+			want: want{
+				code:   codes.InvalidArgument,
+				reason: shorturl.RejectedSelf,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", fmt.Errorf("%w: %w", shorturl.ErrRejected,
+							&shorturl.Rejection{Reason: shorturl.RejectedSelf, Detail: "localhost"})
+					},
+				},
+			},
+		},
 		{
 			name: "Empty request, status code: InvalidArgument",
 			req: req{
@@ -153,6 +556,9 @@ func TestURLsShortenerService_ShortURL(t *testing.T) {
 				if e, ok := status.FromError(err); ok {
 					assert.EqualValues(t, tt.want.code, e.Code(),
 						"Expected status code: %d, got %d", tt.want.code, e.Code())
+					if len(tt.want.reason) != 0 {
+						assertRejection(t, e, tt.want.reason)
+					}
 					return
 				} else {
 					t.Fatalf("failed to parse: %v", err)
@@ -165,13 +571,24 @@ func TestURLsShortenerService_ShortURL(t *testing.T) {
 	}
 }
 
+func assertRejection(t *testing.T, st *status.Status, reason string) {
+	t.Helper()
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok, "Expected ErrorInfo details, got %T", st.Details()[0])
+	assert.Equal(t, reason, info.Reason)
+	assert.Equal(t, rejectionDomain, info.Domain)
+}
+
 func TestURLsShortenerService_BatchURLs(t *testing.T) {
 	type services struct {
 		shortsrv shortener
 	}
 	type want struct {
-		data *pb.BatchURLsResponse
-		code codes.Code
+		data   *pb.BatchURLsResponse
+		code   codes.Code
+		reason string
 	}
 	tests := []struct {
 		want   want
@@ -225,6 +642,7 @@ func TestURLsShortenerService_BatchURLs(t *testing.T) {
 						{
 							CorrId:   "2",
 							ShortUrl: "http://localhost:8080/slug2",
+							Status:   pb.BatchURLsResponse_EXISTED,
 						},
 						{
 							CorrId:   "3",
@@ -252,35 +670,56 @@ func TestURLsShortenerService_BatchURLs(t *testing.T) {
 			},
 			serv: services{
 				shortsrv: &shortenerMock{
-					BatchFn: func(ctx context.Context, urls []models.URL) ([]models.ShortenedURL, error) {
-						records := []models.ShortenedURL{
+					BatchFn: func(ctx context.Context, urls []models.URL) ([]models.BatchedURL, error) {
+						records := []models.BatchedURL{
 							{
-								CorrID: "1",
-								Value:  "http://localhost:8080/slug1",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "1",
+									Value:  "http://localhost:8080/slug1",
+								},
+								Status: models.BatchCreated,
 							},
 							{
-								CorrID: "2",
-								Value:  "http://localhost:8080/slug2",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "2",
+									Value:  "http://localhost:8080/slug2",
+								},
+								Status: models.BatchExisted,
 							},
 							{
-								CorrID: "3",
-								Value:  "http://localhost:8080/slug3",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "3",
+									Value:  "http://localhost:8080/slug3",
+								},
+								Status: models.BatchCreated,
 							},
 							{
-								CorrID: "4",
-								Value:  "http://localhost:8080/slug4",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "4",
+									Value:  "http://localhost:8080/slug4",
+								},
+								Status: models.BatchCreated,
 							},
 							{
-								CorrID: "5",
-								Value:  "http://localhost:8080/slug5",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "5",
+									Value:  "http://localhost:8080/slug5",
+								},
+								Status: models.BatchCreated,
 							},
 							{
-								CorrID: "6",
-								Value:  "http://localhost:8080/slug6",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "6",
+									Value:  "http://localhost:8080/slug6",
+								},
+								Status: models.BatchCreated,
 							},
 							{
-								CorrID: "7",
-								Value:  "http://localhost:8080/slug7",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "7",
+									Value:  "http://localhost:8080/slug7",
+								},
+								Status: models.BatchCreated,
 							},
 						}
 						return records, nil
@@ -299,7 +738,7 @@ func TestURLsShortenerService_BatchURLs(t *testing.T) {
 			},
 			serv: services{
 				shortsrv: &shortenerMock{
-					BatchFn: func(ctx context.Context, u []models.URL) ([]models.ShortenedURL, error) {
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
 						return nil, shorturl.ErrEmptyBatch
 					},
 				},
@@ -329,12 +768,102 @@ func TestURLsShortenerService_BatchURLs(t *testing.T) {
 			},
 			serv: services{
 				shortsrv: &shortenerMock{
-					BatchFn: func(ctx context.Context, u []models.URL) ([]models.ShortenedURL, error) {
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
 						return nil, shorturl.ErrInvalidCreation
 					},
 				},
 			},
 		},
+		{
+			name:   "Invalid expiry, status code: InvalidArgument",
+			userID: "1",
+			req: &pb.BatchURLsRequest{
+				Url: []*pb.BatchURLsRequest_URL{
+					{
+						CorrId: "1",
+						Raw:    "http://demo.com/1",
+						Ttl:    -60,
+					},
+				},
+			},
+			want: want{
+				code: codes.InvalidArgument,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						if u[0].TTL != -time.Minute {
+							return nil, fmt.Errorf("unexpected expiry: %v", u[0])
+						}
+						return nil, shorturl.ErrInvalidExpiry
+					},
+				},
+			},
+		},
+		{
+			name:   "Rejected URL, status code: InvalidArgument",
+			userID: "1",
+			req: &pb.BatchURLsRequest{
+				Url: []*pb.BatchURLsRequest_URL{
+					{
+						CorrId: "1",
+						Raw:    "ftp://demo.com/1",
+					},
+				},
+			},
+			want: want{
+				code:   codes.InvalidArgument,
+				reason: shorturl.RejectedScheme,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						return nil, fmt.Errorf("%w: %w", shorturl.ErrRejected,
+							&shorturl.Rejection{Reason: shorturl.RejectedScheme, Detail: "ftp"})
+					},
+				},
+			},
+		},
+		{
+			name:   "URLs have been shortened, status code: AlreadyExists",
+			userID: "1",
+			req: &pb.BatchURLsRequest{
+				Url: []*pb.BatchURLsRequest_URL{
+					{
+						CorrId: "1",
+						Raw:    "http://demo.com/1",
+					},
+				},
+			},
+			want: want{
+				code: codes.AlreadyExists,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						return nil, shorturl.ErrUniqueViolation
+					},
+				},
+			},
+		},
+		{
+			name:   "Failed to save URLs, status code: Internal",
+			userID: "1",
+			req: &pb.BatchURLsRequest{
+				Url: []*pb.BatchURLsRequest_URL{
+					{
+						CorrId: "1",
+						Raw:    "http://demo.com/1",
+					},
+				},
+			},
+			want: want{
+				code: codes.Internal,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -351,13 +880,20 @@ func TestURLsShortenerService_BatchURLs(t *testing.T) {
 				if e, ok := status.FromError(err); ok {
 					assert.EqualValues(t, tt.want.code, e.Code(),
 						"Expected status code: %d, got %d", tt.want.code, e.Code())
+					if len(tt.want.reason) != 0 {
+						assertRejection(t, e, tt.want.reason)
+					}
 					return
 				} else {
 					t.Fatalf("failed to parse: %v", err)
 				}
 			}
 
-			assert.EqualValues(t, len(tt.want.data.BatchedUrls), len(resp.BatchedUrls))
+			require.EqualValues(t, len(tt.want.data.BatchedUrls), len(resp.BatchedUrls))
+			for i, v := range resp.BatchedUrls {
+				assert.Equal(t, tt.want.data.BatchedUrls[i].CorrId, v.CorrId)
+				assert.Equal(t, tt.want.data.BatchedUrls[i].Status, v.Status)
+			}
 		})
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
@@ -13,19 +14,38 @@ import (
 
 // batcher defines the URLs Batcher.
 type batcher interface {
-	Batch(context.Context, []models.URL) ([]models.ShortenedURL, error)
+	Batch(context.Context, []models.URL) ([]models.BatchedURL, error)
 }
 
 // batchURLsRequest defines the item in a request for the batch urls route.
+// The shortened URL expires at the optional expires_at or after the optional ttl in seconds,
+// it redirects only from the optional activate_at until the optional deactivate_at.
+// It redirects at most the optional max_visits times and is protected by the optional password.
+// It redirects with the optional redirect_status and cache_policy. The redirect forwards
+// the query by the optional query_mode, sets the optional fixed_params and forwards the path
+// after the slug if the optional forward_path is set.
 type batchURLsRequest struct {
-	CorrID string `json:"correlation_id"`
-	RawURL string `json:"original_url"`
+	CorrID         string            `json:"correlation_id"`
+	RawURL         string            `json:"original_url"`
+	ExpiresAt      time.Time         `json:"expires_at,omitempty"`
+	TTL            int64             `json:"ttl,omitempty"`
+	ActivateAt     time.Time         `json:"activate_at,omitempty"`
+	DeactivateAt   time.Time         `json:"deactivate_at,omitempty"`
+	MaxVisits      int               `json:"max_visits,omitempty"`
+	Password       string            `json:"password,omitempty"`
+	RedirectStatus int               `json:"redirect_status,omitempty"`
+	CachePolicy    string            `json:"cache_policy,omitempty"`
+	QueryMode      string            `json:"query_mode,omitempty"`
+	FixedParams    map[string]string `json:"fixed_params,omitempty"`
+	ForwardPath    bool              `json:"forward_path,omitempty"`
 }
 
 // batchURLsResponse defines the item in a response for the batch urls route.
+// The status says whether the URL was created or already existed.
 type batchURLsResponse struct {
-	CorrID       string `json:"correlation_id"`
-	ShortenedURL string `json:"short_url"`
+	CorrID       string             `json:"correlation_id"`
+	ShortenedURL string             `json:"short_url"`
+	Status       models.BatchStatus `json:"status"`
 }
 
 // batchURLs returns a new handler for the batch URLs route.
@@ -45,24 +65,57 @@ func batchURLs(batcher batcher) userHandler {
 		urlsToBatch := make([]models.URL, len(reqData))
 		for i, v := range reqData {
 			urlsToBatch[i] = models.NewURL(userID, v.CorrID, v.RawURL)
+			urlsToBatch[i].ExpiresAt = v.ExpiresAt
+			urlsToBatch[i].TTL = ttl(v.TTL)
+			urlsToBatch[i].ActivateAt = v.ActivateAt
+			urlsToBatch[i].DeactivateAt = v.DeactivateAt
+			urlsToBatch[i].MaxVisits = v.MaxVisits
+			urlsToBatch[i].Password = v.Password
+			urlsToBatch[i].RedirectStatus = v.RedirectStatus
+			urlsToBatch[i].CachePolicy = v.CachePolicy
+			urlsToBatch[i].QueryMode = v.QueryMode
+			urlsToBatch[i].FixedParams = models.EncodeParams(v.FixedParams)
+			urlsToBatch[i].ForwardPath = v.ForwardPath
 		}
 		urls, err := batcher.Batch(c.Request.Context(), urlsToBatch)
 		if err != nil {
+			if rejected(c, err) {
+				return
+			}
 			if errors.Is(err, shorturl.ErrEmptyBatch) ||
 				errors.Is(err, shorturl.ErrInvalidCreation) {
 				c.Status(http.StatusBadRequest)
 				return
 			}
+			if errors.Is(err, shorturl.ErrInvalidExpiry) ||
+				errors.Is(err, shorturl.ErrInvalidWindow) ||
+				errors.Is(err, shorturl.ErrInvalidMaxVisits) ||
+				errors.Is(err, shorturl.ErrInvalidPassword) ||
+				errors.Is(err, shorturl.ErrInvalidRedirect) ||
+				errors.Is(err, shorturl.ErrInvalidQuery) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, shorturl.ErrUniqueViolation) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
 
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
+		// The batch is a conflict if all the URLs already existed.
+		respStatus := http.StatusConflict
 		respData := make([]batchURLsResponse, len(urls))
 		for i, u := range urls {
 			respData[i] = batchURLsResponse{
 				CorrID:       u.CorrID,
 				ShortenedURL: u.Value,
+				Status:       u.Status,
+			}
+			if u.Status == models.BatchCreated {
+				respStatus = http.StatusCreated
 			}
 		}
 
@@ -72,6 +125,6 @@ func batchURLs(batcher batcher) userHandler {
 			return
 		}
 
-		c.Data(http.StatusCreated, "application/json; charset=utf-8", respBody)
+		c.Data(respStatus, "application/json; charset=utf-8", respBody)
 	}
 }
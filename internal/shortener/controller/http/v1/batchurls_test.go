@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
@@ -17,10 +18,10 @@ import (
 )
 
 type batcherMock struct {
-	BatchFn func(context.Context, []models.URL) ([]models.ShortenedURL, error)
+	BatchFn func(context.Context, []models.URL) ([]models.BatchedURL, error)
 }
 
-func (m *batcherMock) Batch(ctx context.Context, urls []models.URL) ([]models.ShortenedURL, error) {
+func (m *batcherMock) Batch(ctx context.Context, urls []models.URL) ([]models.BatchedURL, error) {
 	if m != nil && m.BatchFn != nil {
 		return m.BatchFn(ctx, urls)
 	}
@@ -36,6 +37,8 @@ func TestBatchURLRoute_Batch(t *testing.T) {
 	}
 	type want struct {
 		contentType string
+		reason      string
+		statuses    []models.BatchStatus
 		code        int
 	}
 	tests := []struct {
@@ -69,26 +72,76 @@ func TestBatchURLRoute_Batch(t *testing.T) {
 			want: want{
 				code:        http.StatusCreated,
 				contentType: "application/json; charset=utf-8",
+				statuses: []models.BatchStatus{
+					models.BatchCreated,
+					models.BatchExisted,
+					models.BatchCreated,
+					models.BatchCreated,
+				},
 			},
 			serv: services{
 				batcher: &batcherMock{
-					BatchFn: func(ctx context.Context, u []models.URL) ([]models.ShortenedURL, error) {
-						records := []models.ShortenedURL{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						records := []models.BatchedURL{
 							{
-								CorrID: "0001",
-								Raw:    "http://example.com/query_1",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "0001",
+									Raw:    "http://example.com/query_1",
+								},
+								Status: models.BatchCreated,
 							},
 							{
-								CorrID: "0002",
-								Raw:    "http://example.com/query_2",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "0002",
+									Raw:    "http://example.com/query_2",
+								},
+								Status: models.BatchExisted,
 							},
 							{
-								CorrID: "0003",
-								Raw:    "http://example.com/query_3",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "0003",
+									Raw:    "http://example.com/query_3",
+								},
+								Status: models.BatchCreated,
 							},
 							{
-								CorrID: "0004",
-								Raw:    "http://example.com/query_4",
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "0004",
+									Raw:    "http://example.com/query_4",
+								},
+								Status: models.BatchCreated,
+							},
+						}
+						return records, nil
+					},
+				},
+			},
+		},
+		{
+			name: "All URLs existed, status code: Conflict",
+			req: request{
+				body: []batchURLsRequest{
+					{
+						CorrID: "0001",
+						RawURL: "http://example.com/query_1",
+					},
+				},
+			},
+			want: want{
+				code:        http.StatusConflict,
+				contentType: "application/json; charset=utf-8",
+				statuses:    []models.BatchStatus{models.BatchExisted},
+			},
+			serv: services{
+				batcher: &batcherMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						records := []models.BatchedURL{
+							{
+								ShortenedURL: models.ShortenedURL{
+									CorrID: "0001",
+									Raw:    "http://example.com/query_1",
+								},
+								Status: models.BatchExisted,
 							},
 						}
 						return records, nil
@@ -107,7 +160,7 @@ func TestBatchURLRoute_Batch(t *testing.T) {
 			},
 			serv: services{
 				batcher: &batcherMock{
-					BatchFn: func(ctx context.Context, u []models.URL) ([]models.ShortenedURL, error) {
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
 						return nil, shorturl.ErrEmptyBatch
 					},
 				},
@@ -137,12 +190,145 @@ func TestBatchURLRoute_Batch(t *testing.T) {
 			},
 			serv: services{
 				batcher: &batcherMock{
-					BatchFn: func(ctx context.Context, u []models.URL) ([]models.ShortenedURL, error) {
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
 						return nil, shorturl.ErrInvalidCreation
 					},
 				},
 			},
 		},
+		{
+			name: "Expiring and visit-limited URLs, status code: Created",
+			req: request{
+				body: []batchURLsRequest{
+					{
+						CorrID: "0001",
+						RawURL: "http://example.com/query_1",
+						TTL:    60,
+					},
+					{
+						CorrID:    "0002",
+						RawURL:    "http://example.com/query_2",
+						ExpiresAt: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						CorrID:    "0003",
+						RawURL:    "http://example.com/query_3",
+						MaxVisits: 5,
+					},
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+				statuses:    []models.BatchStatus{models.BatchCreated, models.BatchCreated, models.BatchCreated},
+			},
+			serv: services{
+				batcher: &batcherMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						if u[0].TTL != time.Minute ||
+							!u[1].ExpiresAt.Equal(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)) ||
+							u[2].MaxVisits != 5 {
+							return nil, fmt.Errorf("unexpected limits: %v", u)
+						}
+						batched := make([]models.BatchedURL, len(u))
+						for i, v := range u {
+							batched[i] = models.NewBatchedURL(models.ShortenedURL{
+								CorrID: v.CorrID,
+								Raw:    v.Raw,
+							}, models.BatchCreated)
+						}
+						return batched, nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid expiry, status code: BadRequest",
+			req: request{
+				body: []batchURLsRequest{
+					{
+						CorrID: "0001",
+						RawURL: "http://example.com/query_1",
+						TTL:    -60,
+					},
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				batcher: &batcherMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						return nil, shorturl.ErrInvalidExpiry
+					},
+				},
+			},
+		},
+		{
+			name: "Rejected URL, status code: UnprocessableEntity",
+			req: request{
+				body: []batchURLsRequest{
+					{
+						CorrID: "0001",
+						RawURL: "http://10.0.0.1/query_1",
+					},
+				},
+			},
+			want: want{
+				code:        http.StatusUnprocessableEntity,
+				contentType: "application/json; charset=utf-8",
+				reason:      shorturl.RejectedAddress,
+			},
+			serv: services{
+				batcher: &batcherMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						return nil, fmt.Errorf("%w: %w", shorturl.ErrRejected,
+							&shorturl.Rejection{Reason: shorturl.RejectedAddress, Detail: "10.0.0.1"})
+					},
+				},
+			},
+		},
+		{
+			name: "URLs have been shortened, status code: Conflict",
+			req: request{
+				body: []batchURLsRequest{
+					{
+						CorrID: "0001",
+						RawURL: "http://example.com/query_1",
+					},
+				},
+			},
+			want: want{
+				code:        http.StatusConflict,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				batcher: &batcherMock{
+					BatchFn: func(ctx context.Context, u []models.URL) ([]models.BatchedURL, error) {
+						return nil, shorturl.ErrUniqueViolation
+					},
+				},
+			},
+		},
+		{
+			name: "Failed to save URLs, status code: InternalServerError",
+			req: request{
+				body: []batchURLsRequest{
+					{
+						CorrID: "0001",
+						RawURL: "http://example.com/query_1",
+					},
+				},
+			},
+			want: want{
+				code:        http.StatusInternalServerError,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				batcher: &batcherMock{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -165,12 +351,20 @@ func TestBatchURLRoute_Batch(t *testing.T) {
 			require.NoError(t, err)
 
 			if len(respBody) > 0 {
+				assert.EqualValues(t, tt.want.contentType, resp.Header.Get("Content-Type"))
+			}
+			if len(tt.want.reason) != 0 {
+				assertRejection(t, respBody, tt.want.reason)
+			}
+			if tt.want.statuses != nil {
 				var batchedURLs []batchURLsResponse
 				err = json.Unmarshal(respBody, &batchedURLs)
 				require.NoError(t, err)
 
-				assert.EqualValues(t, len(tt.req.body), len(batchedURLs))
-				assert.EqualValues(t, tt.want.contentType, resp.Header.Get("Content-Type"))
+				require.EqualValues(t, len(tt.req.body), len(batchedURLs))
+				for i, v := range batchedURLs {
+					assert.Equal(t, tt.want.statuses[i], v.Status)
+				}
 			}
 
 		})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/gin-gonic/gin"
@@ -15,9 +16,22 @@ type collector interface {
 }
 
 // collectURLsResponse defines the response to the CollectURLs request.
+// The visits left are set for the visit-limited URLs only, the activation window
+// is set for the URLs that redirect within the window only. The redirect status and
+// the cache policy are set for the URLs shortened with their own redirect only, so are
+// the query forwarding and the path forwarding.
 type collectURLsResponse struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
+	ShortURL       string            `json:"short_url"`
+	OriginalURL    string            `json:"original_url"`
+	VisitsLeft     *int              `json:"visits_left,omitempty"`
+	ActivateAt     *time.Time        `json:"activate_at,omitempty"`
+	DeactivateAt   *time.Time        `json:"deactivate_at,omitempty"`
+	Protected      bool              `json:"protected,omitempty"`
+	RedirectStatus int               `json:"redirect_status,omitempty"`
+	CachePolicy    string            `json:"cache_policy,omitempty"`
+	QueryMode      string            `json:"query_mode,omitempty"`
+	FixedParams    map[string]string `json:"fixed_params,omitempty"`
+	ForwardPath    bool              `json:"forward_path,omitempty"`
 }
 
 // collectURLs returns a new handler for the collect URLs route.
@@ -37,8 +51,23 @@ func collectURLs(collector collector) userHandler {
 		list := make([]collectURLsResponse, len(urls))
 		for i, u := range urls {
 			list[i] = collectURLsResponse{
-				ShortURL:    u.Value,
-				OriginalURL: u.Raw,
+				ShortURL:       u.Value,
+				OriginalURL:    u.Raw,
+				Protected:      u.Protected(),
+				RedirectStatus: u.RedirectStatus,
+				CachePolicy:    u.CachePolicy,
+				QueryMode:      u.QueryMode,
+				FixedParams:    models.DecodeParams(u.FixedParams),
+				ForwardPath:    u.ForwardPath,
+			}
+			if left := u.VisitsLeft(); left >= 0 {
+				list[i].VisitsLeft = &left
+			}
+			if !u.ActivateAt.IsZero() {
+				list[i].ActivateAt = &urls[i].ActivateAt
+			}
+			if !u.DeactivateAt.IsZero() {
+				list[i].DeactivateAt = &urls[i].DeactivateAt
 			}
 		}
 
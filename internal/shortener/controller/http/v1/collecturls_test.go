@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,10 @@ func TestCollectURLs(t *testing.T) {
 		data        []collectURLsResponse
 		code        int
 	}
+
+	launch := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sunset := launch.Add(24 * time.Hour)
+
 	tests := []struct {
 		name string
 		serv services
@@ -70,6 +75,86 @@ func TestCollectURLs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "List visit-limited URLs, status code: Ok",
+			want: want{
+				code:        http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				data: []collectURLsResponse{
+					{
+						OriginalURL: "http://example.com/query_1",
+						VisitsLeft:  func(v int) *int { return &v }(2),
+					},
+					{
+						OriginalURL: "http://example.com/query_2",
+					},
+					{
+						OriginalURL: "http://example.com/query_3",
+						VisitsLeft:  func(v int) *int { return &v }(0),
+						Protected:   true,
+					},
+				},
+			},
+			serv: services{
+				collector: &collectorMock{
+					CollectByUserFn: func(ctx context.Context, s string) (
+						[]models.ShortenedURL, error) {
+						records := []models.ShortenedURL{
+							{
+								Raw:       "http://example.com/query_1",
+								MaxVisits: 3,
+								Visits:    1,
+							},
+							{
+								Raw: "http://example.com/query_2",
+							},
+							{
+								Raw:          "http://example.com/query_3",
+								MaxVisits:    1,
+								Visits:       1,
+								PasswordHash: "hash",
+							},
+						}
+						return records, nil
+					},
+				},
+			},
+		},
+		{
+			name: "List scheduled URLs, status code: Ok",
+			want: want{
+				code:        http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				data: []collectURLsResponse{
+					{
+						OriginalURL:  "http://example.com/launch",
+						ActivateAt:   &launch,
+						DeactivateAt: &sunset,
+					},
+					{
+						OriginalURL: "http://example.com/query_2",
+					},
+				},
+			},
+			serv: services{
+				collector: &collectorMock{
+					CollectByUserFn: func(ctx context.Context, s string) (
+						[]models.ShortenedURL, error) {
+						records := []models.ShortenedURL{
+							{
+								Raw:          "http://example.com/launch",
+								ActivateAt:   launch,
+								DeactivateAt: sunset,
+							},
+							{
+								Raw: "http://example.com/query_2",
+							},
+						}
+						return records, nil
+					},
+				},
+			},
+		},
 		{
 			name: "No URLs, status code: NoContent",
 			want: want{
@@ -111,7 +196,13 @@ func TestCollectURLs(t *testing.T) {
 				err = json.Unmarshal(respBody, &collectedURLs)
 				require.NoError(t, err)
 
-				assert.EqualValues(t, len(tt.want.data), len(collectedURLs))
+				require.EqualValues(t, len(tt.want.data), len(collectedURLs))
+				for i, v := range collectedURLs {
+					assert.Equal(t, tt.want.data[i].VisitsLeft, v.VisitsLeft)
+					assert.Equal(t, tt.want.data[i].Protected, v.Protected)
+					assert.Equal(t, tt.want.data[i].ActivateAt, v.ActivateAt)
+					assert.Equal(t, tt.want.data[i].DeactivateAt, v.DeactivateAt)
+				}
 				assert.EqualValues(t, tt.want.contentType, resp.Header.Get("Content-Type"))
 			}
 		})
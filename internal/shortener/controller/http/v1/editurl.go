@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
+	"github.com/gin-gonic/gin"
+)
+
+// editor defines the editor of the shortened URL destinations.
+type editor interface {
+	Edit(context.Context, string, string, string) (models.URLChange, error)
+	History(context.Context, string, string) ([]models.URLChange, error)
+	Rollback(context.Context, string, string, int) (models.URLChange, error)
+}
+
+// editURLRequest is a request to change the destination of the URL.
+type editURLRequest struct {
+	URL string `json:"url"`
+}
+
+// rollbackURLRequest is a request to change the destination of the URL back to the version.
+type rollbackURLRequest struct {
+	Version int `json:"version"`
+}
+
+// urlChangeResponse defines the change of the URL destination.
+type urlChangeResponse struct {
+	Version   int       `json:"version"`
+	Old       string    `json:"old_url"`
+	New       string    `json:"new_url"`
+	UserID    string    `json:"user_id"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// newURLChangeResponse returns the response of the URL change.
+func newURLChangeResponse(c models.URLChange) urlChangeResponse {
+	return urlChangeResponse{
+		Version:   c.Version,
+		Old:       c.Old,
+		New:       c.New,
+		UserID:    c.UserID,
+		ChangedAt: c.ChangedAt,
+	}
+}
+
+// editURL returns a new handler for the edit URL route.
+func editURL(editor editor) userHandler {
+	return func(c *gin.Context, userID string) {
+		reqBody, err := readReqBody(c.Request)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+			return
+		}
+
+		var reqData editURLRequest
+		if err := json.Unmarshal(reqBody, &reqData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			return
+		}
+
+		change, err := editor.Edit(c.Request.Context(), userID, c.Param("slug"), reqData.URL)
+		if err != nil {
+			editFailed(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, newURLChangeResponse(change))
+	}
+}
+
+// urlHistory returns a new handler for the URL history route.
+func urlHistory(editor editor) userHandler {
+	return func(c *gin.Context, userID string) {
+		history, err := editor.History(c.Request.Context(), userID, c.Param("slug"))
+		if err != nil {
+			editFailed(c, err)
+			return
+		}
+
+		if len(history) == 0 {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		list := make([]urlChangeResponse, len(history))
+		for i, change := range history {
+			list[i] = newURLChangeResponse(change)
+		}
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// rollbackURL returns a new handler for the rollback URL route.
+func rollbackURL(editor editor) userHandler {
+	return func(c *gin.Context, userID string) {
+		reqBody, err := readReqBody(c.Request)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+			return
+		}
+
+		var reqData rollbackURLRequest
+		if err := json.Unmarshal(reqBody, &reqData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			return
+		}
+
+		change, err := editor.Rollback(c.Request.Context(), userID, c.Param("slug"), reqData.Version)
+		if err != nil {
+			editFailed(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, newURLChangeResponse(change))
+	}
+}
+
+// editFailed responds with the status of the editing error.
+func editFailed(c *gin.Context, err error) {
+	if rejected(c, err) {
+		return
+	}
+
+	respStatus := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, shorturl.ErrInvalidDestination),
+		errors.Is(err, shorturl.ErrUnknownVersion):
+		respStatus = http.StatusBadRequest
+	case errors.Is(err, shorturl.ErrURLNotFound):
+		respStatus = http.StatusNotFound
+	case errors.Is(err, shorturl.ErrNotOwner):
+		respStatus = http.StatusForbidden
+	case errors.Is(err, shorturl.ErrUniqueViolation),
+		errors.Is(err, shorturl.ErrVersionConflict):
+		respStatus = http.StatusConflict
+	}
+	c.JSON(respStatus, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,302 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type editorMock struct {
+	EditFn     func(context.Context, string, string, string) (models.URLChange, error)
+	HistoryFn  func(context.Context, string, string) ([]models.URLChange, error)
+	RollbackFn func(context.Context, string, string, int) (models.URLChange, error)
+}
+
+func (m *editorMock) Edit(ctx context.Context, userID, slug, raw string) (models.URLChange, error) {
+	if m != nil && m.EditFn != nil {
+		return m.EditFn(ctx, userID, slug, raw)
+	}
+	return models.URLChange{}, fmt.Errorf("unable to edit")
+}
+
+func (m *editorMock) History(ctx context.Context, userID, slug string) ([]models.URLChange, error) {
+	if m != nil && m.HistoryFn != nil {
+		return m.HistoryFn(ctx, userID, slug)
+	}
+	return nil, fmt.Errorf("unable to get history")
+}
+
+func (m *editorMock) Rollback(ctx context.Context, userID, slug string, version int) (models.URLChange, error) {
+	if m != nil && m.RollbackFn != nil {
+		return m.RollbackFn(ctx, userID, slug, version)
+	}
+	return models.URLChange{}, fmt.Errorf("unable to rollback")
+}
+
+var testChangedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestEditURLRoute_Edit(t *testing.T) {
+	type want struct {
+		code int
+		data string
+	}
+	edited := func(err error) editor {
+		return &editorMock{
+			EditFn: func(_ context.Context, userID, slug, raw string) (models.URLChange, error) {
+				if err != nil {
+					return models.URLChange{}, err
+				}
+				return models.URLChange{Slug: slug, Version: 2, Old: "http://example.com/a",
+					New: raw, UserID: "user", ChangedAt: testChangedAt}, nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		req    string
+		editor editor
+		want   want
+	}{
+		{
+			name:   "Destination is changed, status code: OK",
+			req:    `{"url": "http://example.com/b"}`,
+			editor: edited(nil),
+			want: want{
+				code: http.StatusOK,
+				data: `{"version":2,"old_url":"http://example.com/a","new_url":"http://example.com/b",` +
+					`"user_id":"user","changed_at":"2024-01-01T00:00:00Z"}`,
+			},
+		},
+		{
+			name: "Invalid request, status code: BadRequest",
+			req:  `{"url":`,
+			want: want{
+				code: http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "Invalid destination, status code: BadRequest",
+			req:    `{"url": "example"}`,
+			editor: edited(shorturl.ErrInvalidDestination),
+			want: want{
+				code: http.StatusBadRequest,
+				data: `{"error":"invalid destination"}`,
+			},
+		},
+		{
+			name:   "Rejected destination, status code: UnprocessableEntity",
+			req:    `{"url": "ftp://example.com/b"}`,
+			editor: edited(fmt.Errorf("%w: %w", shorturl.ErrRejected, &shorturl.Rejection{Reason: "scheme"})),
+			want: want{
+				code: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name:   "URL not found, status code: NotFound",
+			req:    `{"url": "http://example.com/b"}`,
+			editor: edited(shorturl.ErrURLNotFound),
+			want: want{
+				code: http.StatusNotFound,
+			},
+		},
+		{
+			name:   "URL of another user, status code: Forbidden",
+			req:    `{"url": "http://example.com/b"}`,
+			editor: edited(shorturl.ErrNotOwner),
+			want: want{
+				code: http.StatusForbidden,
+			},
+		},
+		{
+			name:   "Destination of another URL, status code: Conflict",
+			req:    `{"url": "http://example.com/b"}`,
+			editor: edited(shorturl.ErrUniqueViolation),
+			want: want{
+				code: http.StatusConflict,
+			},
+		},
+		{
+			name:   "Edit error, status code: InternalServerError",
+			req:    `{"url": "http://example.com/b"}`,
+			editor: &editorMock{},
+			want: want{
+				code: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup gin.Router.
+			r := setupGin()
+			r.PATCH("/api/user/urls/:slug", authWrap(editURL(tt.editor)))
+
+			w := httptest.NewRecorder()
+			// Prepare the request.
+			req := textReq(t, "/api/user/urls/slug", http.MethodPatch, bytes.NewBufferString(tt.req))
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.want.code, resp.StatusCode,
+				"Expected response status code: %d, got %d", tt.want.code, resp.StatusCode)
+			if len(tt.want.data) != 0 {
+				assert.JSONEq(t, tt.want.data, string(respBody))
+			}
+		})
+	}
+}
+
+func TestEditURLRoute_History(t *testing.T) {
+	type want struct {
+		code int
+		data string
+	}
+	tests := []struct {
+		name   string
+		editor editor
+		want   want
+	}{
+		{
+			name: "History of the URL, status code: OK",
+			editor: &editorMock{
+				HistoryFn: func(_ context.Context, _, slug string) ([]models.URLChange, error) {
+					return []models.URLChange{
+						{Slug: slug, Version: 2, Old: "http://example.com/a", New: "http://example.com/b",
+							UserID: "user", ChangedAt: testChangedAt},
+					}, nil
+				},
+			},
+			want: want{
+				code: http.StatusOK,
+				data: `[{"version":2,"old_url":"http://example.com/a","new_url":"http://example.com/b",` +
+					`"user_id":"user","changed_at":"2024-01-01T00:00:00Z"}]`,
+			},
+		},
+		{
+			name: "URL has never been edited, status code: NoContent",
+			editor: &editorMock{
+				HistoryFn: func(context.Context, string, string) ([]models.URLChange, error) {
+					return nil, nil
+				},
+			},
+			want: want{
+				code: http.StatusNoContent,
+			},
+		},
+		{
+			name: "URL of another user, status code: Forbidden",
+			editor: &editorMock{
+				HistoryFn: func(context.Context, string, string) ([]models.URLChange, error) {
+					return nil, shorturl.ErrNotOwner
+				},
+			},
+			want: want{
+				code: http.StatusForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup gin.Router.
+			r := setupGin()
+			r.GET("/api/user/urls/:slug/history", authWrap(urlHistory(tt.editor)))
+
+			w := httptest.NewRecorder()
+			// Prepare the request.
+			req := textReq(t, "/api/user/urls/slug/history", http.MethodGet, nil)
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.want.code, resp.StatusCode,
+				"Expected response status code: %d, got %d", tt.want.code, resp.StatusCode)
+			if len(tt.want.data) != 0 {
+				assert.JSONEq(t, tt.want.data, string(respBody))
+			}
+		})
+	}
+}
+
+func TestEditURLRoute_Rollback(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    string
+		editor editor
+		code   int
+	}{
+		{
+			name: "Rollback to the version, status code: OK",
+			req:  `{"version": 1}`,
+			editor: &editorMock{
+				RollbackFn: func(_ context.Context, _, slug string, version int) (models.URLChange, error) {
+					assert.Equal(t, "slug", slug)
+					assert.Equal(t, 1, version)
+					return models.URLChange{Slug: slug, Version: 3}, nil
+				},
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "Unknown version, status code: BadRequest",
+			req:  `{"version": 5}`,
+			editor: &editorMock{
+				RollbackFn: func(context.Context, string, string, int) (models.URLChange, error) {
+					return models.URLChange{}, shorturl.ErrUnknownVersion
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "URL changed concurrently, status code: Conflict",
+			req:  `{"version": 1}`,
+			editor: &editorMock{
+				RollbackFn: func(context.Context, string, string, int) (models.URLChange, error) {
+					return models.URLChange{}, shorturl.ErrVersionConflict
+				},
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name: "Invalid request, status code: BadRequest",
+			req:  `{"version": "one"}`,
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup gin.Router.
+			r := setupGin()
+			r.POST("/api/user/urls/:slug/rollback", authWrap(rollbackURL(tt.editor)))
+
+			w := httptest.NewRecorder()
+			// Prepare the request.
+			req := textReq(t, "/api/user/urls/slug/rollback", http.MethodPost, bytes.NewBufferString(tt.req))
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.code, resp.StatusCode,
+				"Expected response status code: %d, got %d", tt.code, resp.StatusCode)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package v1
 
 import (
 	"context"
+	"errors"
+	"html/template"
 	"net/http"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,8 +17,34 @@ type getterBySlug interface {
 	GetBySlug(context.Context, string) (models.ShortenedURL, error)
 }
 
-// New returns a new handler for the get URL by slug route.
-func getBySlug(provider getterBySlug) gin.HandlerFunc {
+// pendingResponder defines the response to the shortened URL that is not active yet.
+type pendingResponder interface {
+	Respond(c *gin.Context, activateAt time.Time)
+}
+
+// redirectResponder defines the redirect to the destination of the shortened URL.
+type redirectResponder interface {
+	Redirect(c *gin.Context, shortenedURL models.ShortenedURL)
+}
+
+// visitor defines the visits counter of the visit-limited shortened URLs.
+type visitor interface {
+	Visit(context.Context, string) (int, error)
+}
+
+// New returns a new handler for the get URL by slug route. Each redirect
+// of the visit-limited URL is counted, the URL with no visits left is gone.
+// The password-protected URL is served as the unlock form until it is unlocked.
+// The URL redirects within its activation window only, it is gone after the window.
+// The redirect status and caching headers follow the redirect of the URL.
+// The path after the slug is found only for the URL that forwards the path.
+func getBySlug(
+	provider getterBySlug,
+	visitor visitor,
+	unlocker unlocker,
+	pending pendingResponder,
+	redirect redirectResponder,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		slug := c.Param("slug")
 
@@ -33,13 +63,77 @@ func getBySlug(provider getterBySlug) gin.HandlerFunc {
 			c.Status(http.StatusNotFound)
 			return
 		}
+		if !shortenedURL.ForwardPath && len(forwardedPath(c.Param("path"))) != 0 {
+			c.Status(http.StatusNotFound)
+			return
+		}
 
-		if shortenedURL.IsDeleted {
+		now := time.Now()
+		if shortenedURL.IsDeleted || shortenedURL.Expired(now) || shortenedURL.Deactivated(now) {
 			c.Status(http.StatusGone)
 			return
 		}
 
-		c.Header("Location", shortenedURL.Raw)
-		c.Status(http.StatusTemporaryRedirect)
+		if shortenedURL.Pending(now) {
+			pending.Respond(c, shortenedURL.ActivateAt)
+			return
+		}
+
+		if shortenedURL.Flagged() {
+			blocked(c, shortenedURL)
+			return
+		}
+
+		if shortenedURL.Protected() {
+			token, _ := c.Cookie(unlockCookie)
+			if !unlocker.Unlocked(shortenedURL, token) {
+				locked(c, "")
+				return
+			}
+		}
+
+		if shortenedURL.MaxVisits > 0 {
+			if _, err = visitor.Visit(c.Request.Context(), slug); err != nil {
+				if errors.Is(err, shorturl.ErrVisitsExhausted) {
+					c.Status(http.StatusGone)
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		redirect.Redirect(c, shortenedURL)
 	}
 }
+
+// interstitial is the page of the blocked destination. The destination is shown
+// as text, not as a link.
+var interstitial = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Blocked destination</title></head>
+<body>
+<h1>This link has been blocked</h1>
+<p>The destination of the short link is on the list of phishing and malware sites:</p>
+<p><code>{{.}}</code></p>
+</body>
+</html>
+`))
+
+// blocked responds that the destination is on the threat blocklist instead of redirecting:
+// the interstitial page for browsers and the rejection reason otherwise.
+func blocked(c *gin.Context, shortenedURL models.ShortenedURL) {
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		c.Status(http.StatusUnavailableForLegalReasons)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := interstitial.Execute(c.Writer, shortenedURL.Raw); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusUnavailableForLegalReasons, gin.H{
+		"error":  "destination is blocked",
+		"reason": shorturl.RejectedThreat,
+	})
+}
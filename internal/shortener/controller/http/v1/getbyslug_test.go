@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,19 +26,62 @@ func (m *getterBySlugMock) GetBySlug(ctx context.Context, slug string) (models.S
 	return models.ShortenedURL{}, fmt.Errorf("unable to get an URL using slug")
 }
 
+type visitorMock struct {
+	VisitFn func(context.Context, string) (int, error)
+}
+
+func (m *visitorMock) Visit(ctx context.Context, slug string) (int, error) {
+	if m != nil && m.VisitFn != nil {
+		return m.VisitFn(ctx, slug)
+	}
+	return 0, fmt.Errorf("unable to visit")
+}
+
 func TestGetBySlugRoute_GetBySlug(t *testing.T) {
 	type services struct {
-		getter getterBySlug
+		getter   getterBySlug
+		visitor  visitor
+		unlocker unlocker
 	}
 	type request struct {
 		api    string
 		method string
+		accept string
+		cookie string
 	}
 	type want struct {
 		contentType string
 		data        string
 		code        int
 	}
+
+	pending, err := Pending(PendingConfig{Status: http.StatusForbidden, Message: "Coming soon"})
+	require.NoError(t, err)
+	redirect, err := Redirect(RedirectConfig{Status: http.StatusTemporaryRedirect})
+	require.NoError(t, err)
+
+	windowURL := func(activateAt, deactivateAt time.Time) func(context.Context, string) (models.ShortenedURL, error) {
+		return func(ctx context.Context, s string) (models.ShortenedURL, error) {
+			url := models.NewShortenedURL("1", "1", "http://example.com/launch",
+				"tmp_slug", "http://localhost:8080/tmp_slug")
+			url.ActivateAt = activateAt
+			url.DeactivateAt = deactivateAt
+			return url, nil
+		}
+	}
+
+	protectedURL := func(ctx context.Context, s string) (models.ShortenedURL, error) {
+		url := models.NewShortenedURL("1", "1", "http://example.com/doc",
+			"tmp_slug", "http://localhost:8080/tmp_slug")
+		url.PasswordHash = "hash"
+		return url, nil
+	}
+	tokenUnlocker := &unlockerMock{
+		UnlockedFn: func(_ models.ShortenedURL, token string) bool {
+			return token == "token"
+		},
+	}
+
 	tests := []struct {
 		name              string
 		req               request
@@ -65,6 +110,70 @@ func TestGetBySlugRoute_GetBySlug(t *testing.T) {
 			},
 			locationHeaderSet: true,
 		},
+		{
+			name: "Path and query are forwarded, status code: TemporaryRedirect",
+			req: request{
+				api:    "/112Sd/docs/page?ref=newsletter",
+				method: http.MethodGet,
+			},
+			want: want{
+				data: "http://example.com/guide/docs/page?ref=newsletter&utm_source=short",
+				code: http.StatusTemporaryRedirect,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("", "1", "http://example.com/guide",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.QueryMode = models.QueryMerge
+						url.FixedParams = "utm_source=short"
+						url.ForwardPath = true
+						return url, nil
+					},
+				},
+			},
+			locationHeaderSet: true,
+		},
+		{
+			name: "Path is not forwarded, status code: NotFound",
+			req: request{
+				api:    "/112Sd/docs/page",
+				method: http.MethodGet,
+			},
+			want: want{
+				code: http.StatusNotFound,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						return models.NewShortenedURL("", "1", "http://example.com/guide",
+							"tmp_slug", "http://localhost:8080/tmp_slug"), nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd redirects permanently, status code: MovedPermanently",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				data: "http://example.com/query_1",
+				code: http.StatusMovedPermanently,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("", "1", "http://example.com/query_1",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.RedirectStatus = http.StatusMovedPermanently
+						return url, nil
+					},
+				},
+			},
+			locationHeaderSet: true,
+		},
 		{
 			name: "URL by 112Sd was deleted, status code: Gone",
 			req: request{
@@ -86,6 +195,309 @@ func TestGetBySlugRoute_GetBySlug(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "URL by 112Sd has expired, status code: Gone",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code:        http.StatusGone,
+				contentType: "text/plain; charset=utf-8",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.ExpiresAt = time.Now().Add(-time.Second)
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd is marked as expired, status code: Gone",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code:        http.StatusGone,
+				contentType: "text/plain; charset=utf-8",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.IsExpired = true
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd expires later, status code: TemporaryRedirect",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				data:        "http://example.com/query_1",
+				code:        http.StatusTemporaryRedirect,
+				contentType: "text/plain; charset=utf-8",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.ExpiresAt = time.Now().Add(time.Hour)
+						return url, nil
+					},
+				},
+			},
+			locationHeaderSet: true,
+		},
+		{
+			name: "URL by 112Sd has visits left, status code: TemporaryRedirect",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				data:        "http://example.com/query_1",
+				code:        http.StatusTemporaryRedirect,
+				contentType: "text/plain; charset=utf-8",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"112Sd", "http://localhost:8080/112Sd")
+						url.MaxVisits = 1
+						return url, nil
+					},
+				},
+				visitor: &visitorMock{
+					VisitFn: func(ctx context.Context, s string) (int, error) {
+						if s != "112Sd" {
+							return 0, fmt.Errorf("unexpected slug: %v", s)
+						}
+						return 0, nil
+					},
+				},
+			},
+			locationHeaderSet: true,
+		},
+		{
+			name: "URL by 112Sd has no visits left, status code: Gone",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code:        http.StatusGone,
+				contentType: "text/plain; charset=utf-8",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"112Sd", "http://localhost:8080/112Sd")
+						url.MaxVisits = 1
+						url.Visits = 1
+						return url, nil
+					},
+				},
+				visitor: &visitorMock{
+					VisitFn: func(ctx context.Context, s string) (int, error) {
+						return 0, shorturl.ErrVisitsExhausted
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd failed to count visit, status code: InternalServerError",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://example.com/query_1",
+							"112Sd", "http://localhost:8080/112Sd")
+						url.MaxVisits = 1
+						return url, nil
+					},
+				},
+				visitor: &visitorMock{},
+			},
+		},
+		{
+			name: "URL by 112Sd is blocked, status code: UnavailableForLegalReasons",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code:        http.StatusUnavailableForLegalReasons,
+				contentType: "application/json; charset=utf-8",
+				data:        shorturl.RejectedThreat,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://evil.com/login",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.Threat = "evil.com"
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd is blocked, interstitial page",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+				accept: "text/html,application/xhtml+xml",
+			},
+			want: want{
+				code:        http.StatusUnavailableForLegalReasons,
+				contentType: "text/html; charset=utf-8",
+				data:        "http://evil.com/login",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url := models.NewShortenedURL("1", "1", "http://evil.com/login",
+							"tmp_slug", "http://localhost:8080/tmp_slug")
+						url.Threat = "evil.com"
+						return url, nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd is not active yet, status code: Forbidden",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code:        http.StatusForbidden,
+				contentType: "application/json; charset=utf-8",
+				data:        "Coming soon",
+			},
+			serv: services{
+				getter: &getterBySlugMock{GetBySlugFn: windowURL(time.Now().Add(time.Hour), time.Time{})},
+			},
+		},
+		{
+			name: "URL by 112Sd is not active yet, pending page",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+				accept: "text/html",
+			},
+			want: want{
+				code:        http.StatusForbidden,
+				contentType: "text/html; charset=utf-8",
+				data:        "Coming soon",
+			},
+			serv: services{
+				getter: &getterBySlugMock{GetBySlugFn: windowURL(time.Now().Add(time.Hour), time.Time{})},
+			},
+		},
+		{
+			name: "URL by 112Sd is active, status code: TemporaryRedirect",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				data: "http://example.com/launch",
+				code: http.StatusTemporaryRedirect,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: windowURL(time.Now().Add(-time.Hour), time.Now().Add(time.Hour)),
+				},
+			},
+			locationHeaderSet: true,
+		},
+		{
+			name: "URL by 112Sd has been deactivated, status code: Gone",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code: http.StatusGone,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: windowURL(time.Now().Add(-time.Hour), time.Now().Add(-time.Minute)),
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd is protected, status code: Unauthorized",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+			},
+			want: want{
+				code:        http.StatusUnauthorized,
+				contentType: "application/json; charset=utf-8",
+				data:        "password required",
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: tokenUnlocker,
+			},
+		},
+		{
+			name: "URL by 112Sd is protected, unlock form",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+				accept: "text/html,application/xhtml+xml",
+				cookie: "invalid",
+			},
+			want: want{
+				code:        http.StatusUnauthorized,
+				contentType: "text/html; charset=utf-8",
+				data:        `<form method="post">`,
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: tokenUnlocker,
+			},
+		},
+		{
+			name: "URL by 112Sd is unlocked, status code: TemporaryRedirect",
+			req: request{
+				api:    "/112Sd",
+				method: http.MethodGet,
+				cookie: "token",
+			},
+			want: want{
+				data: "http://example.com/doc",
+				code: http.StatusTemporaryRedirect,
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: tokenUnlocker,
+			},
+			locationHeaderSet: true,
+		},
 		{
 			name: "URL doesn't exist, status code: NotFound",
 			req: request{
@@ -109,24 +521,41 @@ func TestGetBySlugRoute_GetBySlug(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup gin.Router.
 			r := setupGin()
-			r.GET("/:slug", getBySlug(tt.serv.getter))
+			r.GET("/:slug", getBySlug(tt.serv.getter, tt.serv.visitor, tt.serv.unlocker, pending, redirect))
+			r.GET("/:slug/*path", getBySlug(tt.serv.getter, tt.serv.visitor, tt.serv.unlocker, pending, redirect))
 
 			w := httptest.NewRecorder()
 			// Prepare the request.
 			req, err := http.NewRequest("GET", tt.req.api, nil)
 			require.NoError(t, err)
+			if len(tt.req.accept) != 0 {
+				req.Header.Set("Accept", tt.req.accept)
+			}
+			if len(tt.req.cookie) != 0 {
+				req.AddCookie(&http.Cookie{Name: unlockCookie, Value: tt.req.cookie})
+			}
 
 			r.ServeHTTP(w, req)
 			resp := w.Result()
 			defer resp.Body.Close()
 
-			// Discard response body.
-			_, err = io.Copy(io.Discard, resp.Body)
+			respBody, err := io.ReadAll(resp.Body)
 			require.NoError(t, err)
 
 			require.Equal(t, tt.want.code, resp.StatusCode,
 				"Expected response status code: %d, got %d", tt.want.code, resp.StatusCode)
 
+			if tt.want.code == http.StatusUnavailableForLegalReasons || tt.want.code == http.StatusUnauthorized ||
+				tt.want.code == http.StatusForbidden {
+				assert.Empty(t, resp.Header.Get("Location"))
+				assert.Equal(t, tt.want.contentType, resp.Header.Get("Content-Type"))
+				assert.Contains(t, string(respBody), tt.want.data)
+			}
+
+			if tt.want.code == http.StatusForbidden {
+				assert.Equal(t, "3600", resp.Header.Get("Retry-After"))
+			}
+
 			if tt.locationHeaderSet {
 				assert.Equal(t, tt.want.data, resp.Header.Get("Location"),
 					"Expected Location Header: %s, got %s", tt.want.data, resp.Header.Get("Location"))
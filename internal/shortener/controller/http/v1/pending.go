@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/gin-gonic/gin"
+)
+
+// PendingConfig represents the configuration of the response to the shortened URL
+// that is not active yet.
+type PendingConfig struct {
+	// Status is the status code of the response, it is a client or server error.
+	Status int `env:"URL_PENDING_STATUS" envDefault:"403"`
+
+	// Message is the message of the response.
+	Message string `env:"URL_PENDING_MESSAGE" envDefault:"This link is not yet available."`
+}
+
+// Empty checks on being empty.
+func (c PendingConfig) Empty() bool {
+	return c.Status == 0 &&
+		len(c.Message) == 0
+}
+
+// Default pending response if none is configured.
+const (
+	defaultPendingStatus  = http.StatusForbidden
+	defaultPendingMessage = "This link is not yet available."
+)
+
+// pending responds to the shortened URL that is not active yet.
+type pending struct {
+	status  int
+	message string
+}
+
+// Pending returns a new pending response.
+func Pending(cfg PendingConfig) (*pending, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if cfg.Status == 0 {
+		cfg.Status = defaultPendingStatus
+	}
+	if cfg.Status < http.StatusBadRequest || cfg.Status > 599 {
+		return nil, fmt.Errorf("pending status %d is not an error", cfg.Status)
+	}
+	if len(cfg.Message) == 0 {
+		cfg.Message = defaultPendingMessage
+	}
+
+	return &pending{
+		status:  cfg.Status,
+		message: cfg.Message,
+	}, nil
+}
+
+// pendingPage is the page of the shortened URL that is not active yet.
+var pendingPage = template.Must(template.New("pending").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Not yet available</title></head>
+<body>
+<h1>{{.Message}}</h1>
+<p>The link is available from <time datetime="{{.ActivateAt}}">{{.ActivateAt}}</time>.</p>
+</body>
+</html>
+`))
+
+// Respond responds that the shortened URL is active from activateAt instead of redirecting:
+// the page for browsers and the message otherwise. The client is told to retry
+// when the URL is active.
+func (p *pending) Respond(c *gin.Context, activateAt time.Time) {
+	activateAt = activateAt.UTC()
+	retryAfter := math.Ceil(time.Until(activateAt).Seconds())
+	c.Header("Retry-After", strconv.FormatFloat(math.Max(retryAfter, 1), 'f', 0, 64))
+
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		c.Status(p.status)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		err := pendingPage.Execute(c.Writer, struct {
+			Message    string
+			ActivateAt string
+		}{
+			Message:    p.message,
+			ActivateAt: activateAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(p.status, gin.H{
+		"error":       p.message,
+		"activate_at": activateAt.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     PendingConfig
+		status  int
+		message string
+		wantErr bool
+	}{
+		{
+			name:    "Default response",
+			cfg:     PendingConfig{Message: "Soon"},
+			status:  http.StatusForbidden,
+			message: "Soon",
+		},
+		{
+			name:    "Configured response",
+			cfg:     PendingConfig{Status: http.StatusServiceUnavailable, Message: "Coming soon"},
+			status:  http.StatusServiceUnavailable,
+			message: "Coming soon",
+		},
+		{
+			name:    "Default message",
+			cfg:     PendingConfig{Status: http.StatusLocked},
+			status:  http.StatusLocked,
+			message: defaultPendingMessage,
+		},
+		{
+			name:    "Status is not an error",
+			cfg:     PendingConfig{Status: http.StatusOK},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid status",
+			cfg:     PendingConfig{Status: 600},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Pending(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			activateAt := time.Now().Add(90 * time.Second).Truncate(time.Second)
+			r := setupGin()
+			r.GET("/:slug", func(c *gin.Context) {
+				p.Respond(c, activateAt)
+			})
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/112Sd", nil)
+			require.NoError(t, err)
+			r.ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+			assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+			var body struct {
+				Error      string    `json:"error"`
+				ActivateAt time.Time `json:"activate_at"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.message, body.Error)
+			assert.True(t, activateAt.Equal(body.ActivateAt))
+		})
+	}
+}
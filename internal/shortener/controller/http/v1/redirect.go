@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/caarlos0/env/v6"
+	"github.com/gin-gonic/gin"
+)
+
+// RedirectConfig represents the configuration of the redirect to the destination
+// of the shortened URL. It is the default of the URLs shortened without their own
+// redirect status or cache policy.
+type RedirectConfig struct {
+	// Status is the status code of the redirect: 301, 302, 307 or 308.
+	Status int `env:"URL_REDIRECT_STATUS" envDefault:"307"`
+
+	// Cache is the cache policy of the redirect: no-store, private or public.
+	// The permanent redirects are public and the temporary ones are not stored if it is empty.
+	Cache string `env:"URL_REDIRECT_CACHE" envDefault:""`
+
+	// MaxAge is how long the cacheable redirect is cached for.
+	MaxAge time.Duration `env:"URL_REDIRECT_MAX_AGE" envDefault:"1h"`
+}
+
+// Empty checks on being empty.
+func (c RedirectConfig) Empty() bool {
+	return c.Status == 0 &&
+		len(c.Cache) == 0 &&
+		c.MaxAge == 0
+}
+
+// Default redirect if none is configured.
+const (
+	defaultRedirectStatus = http.StatusTemporaryRedirect
+	defaultRedirectMaxAge = time.Hour
+)
+
+// redirect redirects to the destination of the shortened URL.
+type redirect struct {
+	status int
+	cache  string
+	maxAge time.Duration
+}
+
+// Redirect returns a new redirect.
+func Redirect(cfg RedirectConfig) (*redirect, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if cfg.Status == 0 {
+		cfg.Status = defaultRedirectStatus
+	}
+	if !models.ValidRedirectStatus(cfg.Status) {
+		return nil, fmt.Errorf("redirect status %d is not supported", cfg.Status)
+	}
+	if len(cfg.Cache) != 0 && !models.ValidCachePolicy(cfg.Cache) {
+		return nil, fmt.Errorf("unknown redirect cache policy %q", cfg.Cache)
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = defaultRedirectMaxAge
+	}
+	if cfg.MaxAge < time.Second {
+		return nil, fmt.Errorf("redirect max age %v is less than a second", cfg.MaxAge)
+	}
+
+	return &redirect{
+		status: cfg.Status,
+		cache:  cfg.Cache,
+		maxAge: cfg.MaxAge,
+	}, nil
+}
+
+// Redirect redirects to the destination of the shortened URL with its status
+// and cache policy, the configured ones are used if the URL has none. The destination
+// gets the path after the slug and the query of the request forwarded by the URL.
+//
+// The visit-limited and the password-protected URLs are never cached, since every
+// click of them must reach the shortener. The cacheable redirect is cached no longer
+// than the URL works.
+func (r *redirect) Redirect(c *gin.Context, shortenedURL models.ShortenedURL) {
+	status := shortenedURL.RedirectStatus
+	if status == 0 {
+		status = r.status
+	}
+
+	now := time.Now()
+	maxAge := r.maxAge
+	for _, until := range []time.Time{shortenedURL.ExpiresAt, shortenedURL.DeactivateAt} {
+		if !until.IsZero() && until.Sub(now) < maxAge {
+			maxAge = until.Sub(now)
+		}
+	}
+
+	policy := r.cachePolicy(shortenedURL, status)
+	if maxAge < time.Second || shortenedURL.MaxVisits > 0 || shortenedURL.Protected() {
+		policy = models.CacheNoStore
+	}
+
+	if policy == models.CacheNoStore {
+		c.Header("Cache-Control", models.CacheNoStore)
+		c.Header("Expires", "0")
+	} else {
+		maxAge = maxAge.Truncate(time.Second)
+		c.Header("Cache-Control", policy+", max-age="+strconv.FormatInt(int64(maxAge.Seconds()), 10))
+		c.Header("Expires", now.Add(maxAge).UTC().Format(http.TimeFormat))
+	}
+
+	c.Header("Location", destination(shortenedURL, c.Param("path"), c.Request.URL.Query()))
+	c.Status(status)
+}
+
+// cachePolicy returns the cache policy of the redirect with the status: the one of the URL,
+// the configured one or the one of the status. The permanent redirects are public,
+// the temporary ones are not stored.
+func (r *redirect) cachePolicy(shortenedURL models.ShortenedURL, status int) string {
+	switch {
+	case len(shortenedURL.CachePolicy) != 0:
+		return shortenedURL.CachePolicy
+	case len(r.cache) != 0:
+		return r.cache
+	case status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect:
+		return models.CachePublic
+	}
+	return models.CacheNoStore
+}
+
+// destination returns the destination of the shortened URL requested with the path suffix
+// and the query. The fixed parameters of the URL are set on the raw URL, then the query
+// is forwarded by the query mode of the URL. The suffix is appended to the path of the raw
+// URL if the URL forwards the path, it never climbs above that path.
+func destination(shortenedURL models.ShortenedURL, suffix string, query url.Values) string {
+	suffix = forwardedPath(suffix)
+	if !shortenedURL.ForwardPath {
+		suffix = ""
+	}
+	forwardQuery := len(query) != 0 &&
+		(shortenedURL.QueryMode == models.QueryMerge || shortenedURL.QueryMode == models.QueryOverride)
+	if len(suffix) == 0 && len(shortenedURL.FixedParams) == 0 && !forwardQuery {
+		return shortenedURL.Raw
+	}
+
+	dest, err := url.Parse(shortenedURL.Raw)
+	if err != nil {
+		return shortenedURL.Raw
+	}
+	if len(suffix) != 0 {
+		dest.Path = strings.TrimSuffix(dest.Path, "/") + suffix
+		dest.RawPath = ""
+	}
+
+	values := dest.Query()
+	fixed, _ := url.ParseQuery(shortenedURL.FixedParams)
+	for k, v := range fixed {
+		values[k] = v
+	}
+	if forwardQuery {
+		for k, v := range query {
+			if _, ok := values[k]; ok && shortenedURL.QueryMode == models.QueryMerge {
+				continue
+			}
+			values[k] = v
+		}
+	}
+	dest.RawQuery = values.Encode()
+	return dest.String()
+}
+
+// forwardedPath returns the cleaned path suffix after the slug, it is empty if there
+// is nothing to forward. The trailing slash of the suffix is kept.
+func forwardedPath(suffix string) string {
+	if len(strings.Trim(suffix, "/")) == 0 {
+		return ""
+	}
+	cleaned := path.Clean("/" + suffix)
+	if cleaned == "/" {
+		return ""
+	}
+	if strings.HasSuffix(suffix, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
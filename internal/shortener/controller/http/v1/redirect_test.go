@@ -0,0 +1,274 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirect(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RedirectConfig
+		wantErr bool
+	}{
+		{
+			name: "Default redirect",
+			cfg:  RedirectConfig{MaxAge: time.Minute},
+		},
+		{
+			name: "Configured redirect",
+			cfg:  RedirectConfig{Status: http.StatusFound, Cache: models.CachePrivate, MaxAge: time.Minute},
+		},
+		{
+			name:    "Status is not a redirect",
+			cfg:     RedirectConfig{Status: http.StatusSeeOther},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown cache policy",
+			cfg:     RedirectConfig{Cache: "forever"},
+			wantErr: true,
+		},
+		{
+			name:    "Max age is less than a second",
+			cfg:     RedirectConfig{MaxAge: time.Millisecond},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Redirect(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRedirect_Redirect(t *testing.T) {
+	type want struct {
+		code  int
+		cache string
+	}
+	tests := []struct {
+		name string
+		cfg  RedirectConfig
+		url  func(*models.ShortenedURL)
+		want want
+	}{
+		{
+			name: "Default temporary redirect is not stored",
+			cfg:  RedirectConfig{Status: http.StatusTemporaryRedirect},
+			want: want{code: http.StatusTemporaryRedirect, cache: "no-store"},
+		},
+		{
+			name: "Default permanent redirect is public",
+			cfg:  RedirectConfig{Status: http.StatusPermanentRedirect},
+			want: want{code: http.StatusPermanentRedirect, cache: "public, max-age=3600"},
+		},
+		{
+			name: "Configured cache policy",
+			cfg:  RedirectConfig{Status: http.StatusFound, Cache: models.CachePrivate, MaxAge: time.Minute},
+			want: want{code: http.StatusFound, cache: "private, max-age=60"},
+		},
+		{
+			name: "Redirect of the URL",
+			cfg:  RedirectConfig{Status: http.StatusTemporaryRedirect},
+			url: func(u *models.ShortenedURL) {
+				u.RedirectStatus = http.StatusMovedPermanently
+				u.CachePolicy = models.CachePrivate
+			},
+			want: want{code: http.StatusMovedPermanently, cache: "private, max-age=3600"},
+		},
+		{
+			name: "Tracking URL",
+			cfg:  RedirectConfig{Status: http.StatusMovedPermanently},
+			url: func(u *models.ShortenedURL) {
+				u.RedirectStatus = http.StatusFound
+				u.CachePolicy = models.CacheNoStore
+			},
+			want: want{code: http.StatusFound, cache: "no-store"},
+		},
+		{
+			name: "Cached until the URL expires",
+			cfg:  RedirectConfig{Status: http.StatusMovedPermanently},
+			url: func(u *models.ShortenedURL) {
+				u.ExpiresAt = time.Now().Add(10*time.Minute + 500*time.Millisecond)
+			},
+			want: want{code: http.StatusMovedPermanently, cache: "public, max-age=600"},
+		},
+		{
+			name: "Cached until the URL is deactivated",
+			cfg:  RedirectConfig{Status: http.StatusMovedPermanently},
+			url: func(u *models.ShortenedURL) {
+				u.ExpiresAt = time.Now().Add(time.Hour / 2)
+				u.DeactivateAt = time.Now().Add(5*time.Minute + 500*time.Millisecond)
+			},
+			want: want{code: http.StatusMovedPermanently, cache: "public, max-age=300"},
+		},
+		{
+			name: "Visit-limited URL is not stored",
+			cfg:  RedirectConfig{Status: http.StatusMovedPermanently},
+			url: func(u *models.ShortenedURL) {
+				u.MaxVisits = 3
+				u.CachePolicy = models.CachePublic
+			},
+			want: want{code: http.StatusMovedPermanently, cache: "no-store"},
+		},
+		{
+			name: "Password-protected URL is not stored",
+			cfg:  RedirectConfig{Status: http.StatusMovedPermanently},
+			url: func(u *models.ShortenedURL) {
+				u.PasswordHash = "hash"
+			},
+			want: want{code: http.StatusMovedPermanently, cache: "no-store"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Redirect(tt.cfg)
+			require.NoError(t, err)
+
+			url := models.NewShortenedURL("1", "1", "http://example.com/doc",
+				"slug", "http://localhost:8080/slug")
+			if tt.url != nil {
+				tt.url(&url)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/slug", nil)
+			r.Redirect(c, url)
+			c.Writer.WriteHeaderNow()
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.want.code, resp.StatusCode)
+			assert.Equal(t, "http://example.com/doc", resp.Header.Get("Location"))
+			assert.Equal(t, tt.want.cache, resp.Header.Get("Cache-Control"))
+
+			expires := resp.Header.Get("Expires")
+			if tt.want.cache == models.CacheNoStore {
+				assert.Equal(t, "0", expires)
+				return
+			}
+			at, err := http.ParseTime(expires)
+			require.NoError(t, err)
+			assert.True(t, at.After(time.Now()), "expires at %v", at)
+		})
+	}
+}
+
+func TestDestination(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		url    func(*models.ShortenedURL)
+		suffix string
+		query  string
+		want   string
+	}{
+		{
+			name:  "Query is dropped by default",
+			raw:   "http://example.com/doc?lang=en",
+			query: "ref=newsletter",
+			want:  "http://example.com/doc?lang=en",
+		},
+		{
+			name: "Query is dropped",
+			raw:  "http://example.com/doc",
+			url: func(u *models.ShortenedURL) {
+				u.QueryMode = models.QueryDrop
+			},
+			query: "ref=newsletter",
+			want:  "http://example.com/doc",
+		},
+		{
+			name: "Merged query keeps the destination parameters",
+			raw:  "http://example.com/doc?lang=en",
+			url: func(u *models.ShortenedURL) {
+				u.QueryMode = models.QueryMerge
+			},
+			query: "lang=de&ref=newsletter",
+			want:  "http://example.com/doc?lang=en&ref=newsletter",
+		},
+		{
+			name: "Overridden query replaces the destination parameters",
+			raw:  "http://example.com/doc?lang=en",
+			url: func(u *models.ShortenedURL) {
+				u.QueryMode = models.QueryOverride
+			},
+			query: "lang=de&ref=newsletter",
+			want:  "http://example.com/doc?lang=de&ref=newsletter",
+		},
+		{
+			name: "Fixed parameters are set",
+			raw:  "http://example.com/doc?utm_source=old",
+			url: func(u *models.ShortenedURL) {
+				u.FixedParams = "utm_medium=email&utm_source=newsletter"
+			},
+			query: "ref=x",
+			want:  "http://example.com/doc?utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name: "Merged query keeps the fixed parameters",
+			raw:  "http://example.com/doc",
+			url: func(u *models.ShortenedURL) {
+				u.QueryMode = models.QueryMerge
+				u.FixedParams = "utm_source=newsletter"
+			},
+			query: "utm_source=spam",
+			want:  "http://example.com/doc?utm_source=newsletter",
+		},
+		{
+			name: "Path is forwarded",
+			raw:  "http://example.com/guide/?lang=en",
+			url: func(u *models.ShortenedURL) {
+				u.ForwardPath = true
+			},
+			suffix: "/docs/page",
+			want:   "http://example.com/guide/docs/page?lang=en",
+		},
+		{
+			name: "Path never climbs above the destination",
+			raw:  "http://example.com/guide",
+			url: func(u *models.ShortenedURL) {
+				u.ForwardPath = true
+			},
+			suffix: "/../../admin/",
+			want:   "http://example.com/guide/admin/",
+		},
+		{
+			name:   "Path is not forwarded",
+			raw:    "http://example.com/guide",
+			suffix: "/docs/page",
+			want:   "http://example.com/guide",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortenedURL := models.NewShortenedURL("1", "1", tt.raw, "slug", "http://localhost:8080/slug")
+			if tt.url != nil {
+				tt.url(&shortenedURL)
+			}
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, destination(shortenedURL, tt.suffix, query))
+		})
+	}
+}
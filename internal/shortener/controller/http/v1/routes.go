@@ -27,14 +27,21 @@ func SetRoutes(
 	g *gin.Engine,
 	auth authHandler,
 	subnetValidator validateSubnetHandler,
+	pending pendingResponder,
+	redirect redirectResponder,
 	servs *services.Services,
 ) {
 	// Add short URLs handler.
 	g.POST("/", auth.Handle((short(servs.Shortener, servs.Provider))))
 	g.POST("/api/shorten", auth.Handle(shorten(servs.Shortener, servs.Provider)))
 
-	// Add get by slug handler.
-	g.GET("/:slug", getBySlug(servs.Provider))
+	// Add get by slug handlers, the path after the slug is forwarded.
+	g.GET("/:slug", getBySlug(servs.Provider, servs.Visitor, servs.Unlocker, pending, redirect))
+	g.GET("/:slug/*path", getBySlug(servs.Provider, servs.Visitor, servs.Unlocker, pending, redirect))
+
+	// Add unlock the password-protected URL handlers.
+	g.POST("/:slug", unlock(servs.Provider, servs.Unlocker, pending))
+	g.POST("/:slug/*path", unlock(servs.Provider, servs.Unlocker, pending))
 
 	// Add collect URLs handler.
 	g.GET("/api/user/urls", auth.Handle(collectURLs(servs.Provider)))
@@ -42,6 +49,11 @@ func SetRoutes(
 	// Add delete URLs handler.
 	g.DELETE("/api/user/urls", auth.Handle(deleteURLs(servs.Deleter)))
 
+	// Add edit URL handlers.
+	g.PATCH("/api/user/urls/:slug", auth.Handle(editURL(servs.Editor)))
+	g.GET("/api/user/urls/:slug/history", auth.Handle(urlHistory(servs.Editor)))
+	g.POST("/api/user/urls/:slug/rollback", auth.Handle(rollbackURL(servs.Editor)))
+
 	// Add batch URLs handler.
 	g.POST("/api/shorten/batch", auth.Handle(batchURLs(servs.Shortener)))
 
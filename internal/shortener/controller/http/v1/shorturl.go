@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
@@ -18,7 +19,7 @@ type shortener interface {
 
 // getterByURL defines the shortened URL provider by raw url.
 type getterByURL interface {
-	GetByURL(context.Context, string) (models.ShortenedURL, error)
+	GetByURL(context.Context, string, string) (models.ShortenedURL, error)
 }
 
 // short returns a new handler for the short URL route.
@@ -33,9 +34,12 @@ func short(shortener shortener, provider getterByURL) userHandler {
 		shortenedURL, err := shortener.Short(c.Request.Context(),
 			models.NewURL(userID, "", string(reqData)))
 		if err != nil {
+			if rejected(c, err) {
+				return
+			}
 			if errors.Is(err, shorturl.ErrUniqueViolation) {
-				// Get an existing shortened URL.
-				shortURL, err := provider.GetByURL(c.Request.Context(), string(reqData))
+				// Get the existing shortened URL of the user.
+				shortURL, err := provider.GetByURL(c.Request.Context(), userID, string(reqData))
 				if err != nil {
 					c.String(http.StatusInternalServerError, err.Error())
 				}
@@ -54,9 +58,27 @@ func short(shortener shortener, provider getterByURL) userHandler {
 	}
 }
 
-// shortURLRequest is a request to short the URL.
+// shortURLRequest is a request to short the URL. The optional alias is the custom slug.
+// The shortened URL expires at the optional expires_at or after the optional ttl in seconds,
+// it redirects only from the optional activate_at until the optional deactivate_at.
+// It redirects at most the optional max_visits times and is protected by the optional password.
+// It redirects with the optional redirect_status and cache_policy. The redirect forwards
+// the query by the optional query_mode, sets the optional fixed_params and forwards the path
+// after the slug if the optional forward_path is set.
 type shortURLRequest struct {
-	URL string `json:"url"`
+	URL            string            `json:"url"`
+	Alias          string            `json:"alias,omitempty"`
+	ExpiresAt      time.Time         `json:"expires_at,omitempty"`
+	TTL            int64             `json:"ttl,omitempty"`
+	ActivateAt     time.Time         `json:"activate_at,omitempty"`
+	DeactivateAt   time.Time         `json:"deactivate_at,omitempty"`
+	MaxVisits      int               `json:"max_visits,omitempty"`
+	Password       string            `json:"password,omitempty"`
+	RedirectStatus int               `json:"redirect_status,omitempty"`
+	CachePolicy    string            `json:"cache_policy,omitempty"`
+	QueryMode      string            `json:"query_mode,omitempty"`
+	FixedParams    map[string]string `json:"fixed_params,omitempty"`
+	ForwardPath    bool              `json:"forward_path,omitempty"`
 }
 
 // shortURLResponse is a response to short the URL.
@@ -79,12 +101,42 @@ func shorten(shortener shortener, provider getterByURL) userHandler {
 			return
 		}
 
-		shortenURL, err := shortener.Short(c.Request.Context(),
-			models.NewURL(userID, "", reqData.URL))
+		url := models.NewURL(userID, "", reqData.URL)
+		url.Alias = reqData.Alias
+		url.ExpiresAt = reqData.ExpiresAt
+		url.TTL = ttl(reqData.TTL)
+		url.ActivateAt = reqData.ActivateAt
+		url.DeactivateAt = reqData.DeactivateAt
+		url.MaxVisits = reqData.MaxVisits
+		url.Password = reqData.Password
+		url.RedirectStatus = reqData.RedirectStatus
+		url.CachePolicy = reqData.CachePolicy
+		url.QueryMode = reqData.QueryMode
+		url.FixedParams = models.EncodeParams(reqData.FixedParams)
+		url.ForwardPath = reqData.ForwardPath
+
+		shortenURL, err := shortener.Short(c.Request.Context(), url)
 		if err != nil {
+			if errors.Is(err, shorturl.ErrAliasTaken) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, shorturl.ErrInvalidAlias) ||
+				errors.Is(err, shorturl.ErrInvalidExpiry) ||
+				errors.Is(err, shorturl.ErrInvalidWindow) ||
+				errors.Is(err, shorturl.ErrInvalidMaxVisits) ||
+				errors.Is(err, shorturl.ErrInvalidPassword) ||
+				errors.Is(err, shorturl.ErrInvalidRedirect) ||
+				errors.Is(err, shorturl.ErrInvalidQuery) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if rejected(c, err) {
+				return
+			}
 			if errors.Is(err, shorturl.ErrUniqueViolation) {
-				// Get an existing shortened URL.
-				shortenedURL, err := provider.GetByURL(c.Request.Context(), reqData.URL)
+				// Get the existing shortened URL of the user.
+				shortenedURL, err := provider.GetByURL(c.Request.Context(), userID, reqData.URL)
 				if err != nil {
 					c.String(http.StatusInternalServerError, err.Error())
 				}
@@ -116,3 +168,25 @@ func shorten(shortener shortener, provider getterByURL) userHandler {
 		c.Data(http.StatusCreated, "application/json; charset=utf-8", respBody)
 	}
 }
+
+// maxTTL is the longest TTL in seconds the duration can hold.
+const maxTTL = int64(1<<63-1) / int64(time.Second)
+
+// ttl converts the TTL in seconds to the duration. The TTL longer than
+// the duration can hold is treated as invalid.
+func ttl(seconds int64) time.Duration {
+	if seconds > maxTTL {
+		return -1
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// rejected responds with the rejection reason if the URL was rejected by the policy.
+func rejected(c *gin.Context, err error) bool {
+	var rej *shorturl.Rejection
+	if !errors.Is(err, shorturl.ErrRejected) || !errors.As(err, &rej) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "reason": rej.Reason})
+	return true
+}
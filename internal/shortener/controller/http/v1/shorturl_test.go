@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
@@ -28,12 +29,12 @@ func (m *shortenerMock) Short(ctx context.Context, url models.URL) (string, erro
 }
 
 type getterByURLMock struct {
-	GetByURLFn func(context.Context, string) (models.ShortenedURL, error)
+	GetByURLFn func(context.Context, string, string) (models.ShortenedURL, error)
 }
 
-func (m *getterByURLMock) GetByURL(ctx context.Context, raw string) (models.ShortenedURL, error) {
+func (m *getterByURLMock) GetByURL(ctx context.Context, userID, raw string) (models.ShortenedURL, error) {
 	if m != nil && m.GetByURLFn != nil {
-		return m.GetByURLFn(ctx, raw)
+		return m.GetByURLFn(ctx, userID, raw)
 	}
 	return models.ShortenedURL{}, fmt.Errorf("unable to short URL")
 }
@@ -48,6 +49,7 @@ func TestShort(t *testing.T) {
 	}
 	type want struct {
 		contentType string
+		reason      string
 		code        int
 	}
 	tests := []struct {
@@ -89,13 +91,32 @@ func TestShort(t *testing.T) {
 					},
 				},
 				getter: &getterByURLMock{
-					GetByURLFn: func(_ context.Context, s string) (models.ShortenedURL, error) {
+					GetByURLFn: func(_ context.Context, _, s string) (models.ShortenedURL, error) {
 						return models.NewShortenedURL("1", "1", "https//go-dev",
 							"tmp_slug", "http//localhost:8080/tmp_slug"), nil
 					},
 				},
 			},
 		},
+		{
+			name: "Rejected URL, status code: UnprocessableEntity",
+			req: request{
+				body: "ftp://demo.com",
+			},
+			want: want{
+				code:        http.StatusUnprocessableEntity,
+				contentType: "application/json; charset=utf-8",
+				reason:      shorturl.RejectedScheme,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", fmt.Errorf("%w: %w", shorturl.ErrRejected,
+							&shorturl.Rejection{Reason: shorturl.RejectedScheme, Detail: "ftp"})
+					},
+				},
+			},
+		},
 		{
 			name: "Empty request, status code: BadRequest",
 			want: want{
@@ -136,6 +157,9 @@ func TestShort(t *testing.T) {
 			if tt.want.code == http.StatusCreated || tt.want.code == http.StatusConflict {
 				require.NotEmpty(t, respBody)
 			}
+			if len(tt.want.reason) != 0 {
+				assertRejection(t, respBody, tt.want.reason)
+			}
 		})
 	}
 }
@@ -150,6 +174,7 @@ func TestShorten(t *testing.T) {
 	}
 	type want struct {
 		contentType string
+		reason      string
 		code        int
 	}
 	tests := []struct {
@@ -195,13 +220,383 @@ func TestShorten(t *testing.T) {
 					},
 				},
 				provider: &getterByURLMock{
-					GetByURLFn: func(_ context.Context, s string) (models.ShortenedURL, error) {
+					GetByURLFn: func(_ context.Context, _, s string) (models.ShortenedURL, error) {
 						return models.NewShortenedURL("", "1", "https//go-dev",
 							"tmp_slug", "http://localhost:8080/tmp_slug"), nil
 					},
 				},
 			},
 		},
+		{
+			name: "New URL with alias, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL:   "https://demo.com",
+					Alias: "spring-sale",
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if u.Alias != "spring-sale" {
+							return "", fmt.Errorf("unexpected alias: %v", u.Alias)
+						}
+						return "http://localhost:8080/spring-sale", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Taken alias, status code: Conflict",
+			req: request{
+				body: shortURLRequest{
+					URL:   "https://demo.com",
+					Alias: "spring-sale",
+				},
+			},
+			want: want{
+				code:        http.StatusConflict,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrAliasTaken
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid alias, status code: BadRequest",
+			req: request{
+				body: shortURLRequest{
+					URL:   "https://demo.com",
+					Alias: "api",
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidAlias
+					},
+				},
+			},
+		},
+		{
+			name: "New URL with TTL, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL: "https://demo.com",
+					TTL: 3600,
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if u.TTL != time.Hour || !u.ExpiresAt.IsZero() {
+							return "", fmt.Errorf("unexpected expiry: %v", u)
+						}
+						return "http://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "New URL with expiry, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL:       "https://demo.com",
+					ExpiresAt: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if !u.ExpiresAt.Equal(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+							return "", fmt.Errorf("unexpected expiry: %v", u)
+						}
+						return "http://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "New one-time URL, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL:       "https://demo.com",
+					MaxVisits: 1,
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if u.MaxVisits != 1 {
+							return "", fmt.Errorf("unexpected max visits: %v", u.MaxVisits)
+						}
+						return "http://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid max visits, status code: BadRequest",
+			req: request{
+				body: shortURLRequest{
+					URL:       "https://demo.com",
+					MaxVisits: -1,
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidMaxVisits
+					},
+				},
+			},
+		},
+		{
+			name: "New scheduled URL, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL:          "https://demo.com/launch",
+					ActivateAt:   time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+					DeactivateAt: time.Date(2030, time.February, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if u.ActivateAt.Month() != time.January || u.DeactivateAt.Month() != time.February {
+							return "", fmt.Errorf("unexpected window: %v - %v", u.ActivateAt, u.DeactivateAt)
+						}
+						return "http://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid window, status code: BadRequest",
+			req: request{
+				body: shortURLRequest{
+					URL:          "https://demo.com/launch",
+					DeactivateAt: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidWindow
+					},
+				},
+			},
+		},
+		{
+			name: "New permanent URL, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL:            "https://demo.com/seo",
+					RedirectStatus: http.StatusMovedPermanently,
+					CachePolicy:    models.CachePublic,
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if u.RedirectStatus != http.StatusMovedPermanently || u.CachePolicy != models.CachePublic {
+							return "", fmt.Errorf("unexpected redirect: %d %s", u.RedirectStatus, u.CachePolicy)
+						}
+						return "http://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid redirect, status code: BadRequest",
+			req: request{
+				body: shortURLRequest{
+					URL:            "https://demo.com/seo",
+					RedirectStatus: http.StatusOK,
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidRedirect
+					},
+				},
+			},
+		},
+		{
+			name: "New URL forwarding the query, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL:         "https://demo.com/docs",
+					QueryMode:   models.QueryMerge,
+					FixedParams: map[string]string{"utm_source": "newsletter", "utm_medium": "email"},
+					ForwardPath: true,
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if u.QueryMode != models.QueryMerge || !u.ForwardPath ||
+							u.FixedParams != "utm_medium=email&utm_source=newsletter" {
+							return "", fmt.Errorf("unexpected forwarding: %s %s %t", u.QueryMode, u.FixedParams, u.ForwardPath)
+						}
+						return "http://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid query forwarding, status code: BadRequest",
+			req: request{
+				body: shortURLRequest{
+					URL:       "https://demo.com/docs",
+					QueryMode: "append",
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidQuery
+					},
+				},
+			},
+		},
+		{
+			name: "New protected URL, status code: Created",
+			req: request{
+				body: shortURLRequest{
+					URL:      "https://demo.com/doc",
+					Password: "secret",
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						if u.Password != "secret" {
+							return "", fmt.Errorf("unexpected password: %v", u.Password)
+						}
+						return "http://localhost:8080/slug", nil
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid password, status code: BadRequest",
+			req: request{
+				body: shortURLRequest{
+					URL:      "https://demo.com/doc",
+					Password: "long",
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidPassword
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid expiry, status code: BadRequest",
+			req: request{
+				body: shortURLRequest{
+					URL: "https://demo.com",
+					TTL: -1,
+				},
+			},
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", shorturl.ErrInvalidExpiry
+					},
+				},
+			},
+		},
+		{
+			name: "Self link, status code: UnprocessableEntity",
+			req: request{
+				body: shortURLRequest{
+					URL: "http://localhost:8080/slug",
+				},
+			},
+			want: want{
+				code:        http.StatusUnprocessableEntity,
+				contentType: "application/json; charset=utf-8",
+				reason:      shorturl.RejectedSelf,
+			},
+			serv: services{
+				shortsrv: &shortenerMock{
+					ShortFn: func(_ context.Context, u models.URL) (string, error) {
+						return "", fmt.Errorf("%w: %w", shorturl.ErrRejected,
+							&shorturl.Rejection{Reason: shorturl.RejectedSelf, Detail: "localhost"})
+					},
+				},
+			},
+		},
 		{
 			name: "Empty request, status code: BadRequest",
 			req: request{
@@ -241,15 +636,31 @@ func TestShorten(t *testing.T) {
 			assert.EqualValues(t, tt.want.contentType, resp.Header.Get("Content-Type"),
 				"Expected Content-Type Header: %s, got %s", tt.want.contentType, resp.Header.Get("Content-Type"))
 
+			resBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
 			if tt.want.code == http.StatusCreated || tt.want.code == http.StatusConflict {
-				resBody, err := io.ReadAll(resp.Body)
-				require.NoError(t, err)
 				assert.NotEmpty(t, resBody)
 			}
+			if len(tt.want.reason) != 0 {
+				assertRejection(t, resBody, tt.want.reason)
+			}
 		})
 	}
 }
 
+// assertRejection checks the rejection reason of the response body.
+func assertRejection(t *testing.T, body []byte, reason string) {
+	t.Helper()
+
+	var got struct {
+		Error  string `json:"error"`
+		Reason string `json:"reason"`
+	}
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, reason, got.Reason)
+	assert.NotEmpty(t, got.Error)
+}
+
 // jsonReq prepares http.Request with a JSON body.
 func shortURLJSONReq(t *testing.T, api, method string, reqBody shortURLRequest) *http.Request {
 	data, err := json.Marshal(reqBody)
@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
+	"github.com/gin-gonic/gin"
+)
+
+// unlockCookie is the name of the cookie with the unlock token of the protected URL.
+// The cookie path is the short URL path, so each unlocked URL has its own token.
+const unlockCookie = "unlock"
+
+// unlocker defines the unlocker of the password-protected shortened URLs.
+type unlocker interface {
+	Unlock(models.ShortenedURL, string) (string, error)
+	Unlocked(models.ShortenedURL, string) bool
+	TTL() time.Duration
+}
+
+// New returns a new handler for the unlock form of the password-protected URL.
+// The short-lived unlock token is set as a cookie on the right password, then
+// the browser is redirected back to the short URL. The URL is unlocked within
+// its activation window only, the cookie unlocks every path forwarded by the URL.
+func unlock(provider getterBySlug, unlocker unlocker, pending pendingResponder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("slug")
+
+		if len(slug) == 0 {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+
+		shortenedURL, err := provider.GetBySlug(c.Request.Context(), slug)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if shortenedURL.Empty() {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if !shortenedURL.ForwardPath && len(forwardedPath(c.Param("path"))) != 0 {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		now := time.Now()
+		if shortenedURL.IsDeleted || shortenedURL.Expired(now) || shortenedURL.Deactivated(now) {
+			c.Status(http.StatusGone)
+			return
+		}
+
+		if shortenedURL.Pending(now) {
+			pending.Respond(c, shortenedURL.ActivateAt)
+			return
+		}
+
+		if shortenedURL.Flagged() {
+			blocked(c, shortenedURL)
+			return
+		}
+
+		if shortenedURL.Protected() {
+			token, err := unlocker.Unlock(shortenedURL, c.PostForm("password"))
+			if err != nil {
+				if errors.Is(err, shorturl.ErrWrongPassword) {
+					locked(c, "Wrong password, try again.")
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(unlockCookie, token, int(unlocker.TTL().Seconds()),
+				"/"+slug, "", c.Request.TLS != nil, true)
+		}
+
+		c.Header("Location", c.Request.URL.RequestURI())
+		c.Status(http.StatusSeeOther)
+	}
+}
+
+// unlockForm is the page of the password-protected URL. The form is posted
+// to the short URL itself.
+var unlockForm = template.Must(template.New("unlock").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Protected link</title></head>
+<body>
+<h1>This link is password-protected</h1>
+{{if .}}<p>{{.}}</p>{{end}}
+<form method="post">
+<input type="password" name="password" autofocus required>
+<button type="submit">Unlock</button>
+</form>
+</body>
+</html>
+`))
+
+// locked responds that the password is required instead of redirecting:
+// the unlock form for browsers and the error otherwise.
+func locked(c *gin.Context, message string) {
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		c.Status(http.StatusUnauthorized)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := unlockForm.Execute(c.Writer, message); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	if len(message) == 0 {
+		message = "password required"
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+}
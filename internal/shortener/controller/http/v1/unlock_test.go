@@ -0,0 +1,284 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/services/shorturl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unlockerMock struct {
+	UnlockFn   func(models.ShortenedURL, string) (string, error)
+	UnlockedFn func(models.ShortenedURL, string) bool
+}
+
+func (m *unlockerMock) Unlock(url models.ShortenedURL, password string) (string, error) {
+	if m != nil && m.UnlockFn != nil {
+		return m.UnlockFn(url, password)
+	}
+	return "", fmt.Errorf("unable to unlock")
+}
+
+func (m *unlockerMock) Unlocked(url models.ShortenedURL, token string) bool {
+	if m != nil && m.UnlockedFn != nil {
+		return m.UnlockedFn(url, token)
+	}
+	return false
+}
+
+func (m *unlockerMock) TTL() time.Duration {
+	return time.Minute
+}
+
+func TestUnlockRoute_Unlock(t *testing.T) {
+	type services struct {
+		getter   getterBySlug
+		unlocker unlocker
+	}
+	type request struct {
+		api      string
+		password string
+		accept   string
+	}
+	type want struct {
+		code     int
+		location string
+		cookie   string
+		data     string
+	}
+
+	pending, err := Pending(PendingConfig{Status: http.StatusForbidden, Message: "Coming soon"})
+	require.NoError(t, err)
+
+	protectedURL := func(ctx context.Context, s string) (models.ShortenedURL, error) {
+		url := models.NewShortenedURL("1", "1", "http://example.com/doc",
+			s, "http://localhost:8080/"+s)
+		url.PasswordHash = "hash"
+		return url, nil
+	}
+	passwordUnlocker := &unlockerMock{
+		UnlockFn: func(_ models.ShortenedURL, password string) (string, error) {
+			if password != "secret" {
+				return "", shorturl.ErrWrongPassword
+			}
+			return "token", nil
+		},
+	}
+
+	tests := []struct {
+		name string
+		req  request
+		serv services
+		want want
+	}{
+		{
+			name: "Right password, status code: SeeOther",
+			req: request{
+				api:      "/112Sd",
+				password: "secret",
+			},
+			want: want{
+				code:     http.StatusSeeOther,
+				location: "/112Sd",
+				cookie:   "token",
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: passwordUnlocker,
+			},
+		},
+		{
+			name: "Right password, the query is kept",
+			req: request{
+				api:      "/112Sd?ref=mail",
+				password: "secret",
+			},
+			want: want{
+				code:     http.StatusSeeOther,
+				location: "/112Sd?ref=mail",
+				cookie:   "token",
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: passwordUnlocker,
+			},
+		},
+		{
+			name: "Wrong password, status code: Unauthorized",
+			req: request{
+				api:      "/112Sd",
+				password: "guess",
+			},
+			want: want{
+				code: http.StatusUnauthorized,
+				data: "Wrong password",
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: passwordUnlocker,
+			},
+		},
+		{
+			name: "Wrong password, unlock form",
+			req: request{
+				api:      "/112Sd",
+				password: "guess",
+				accept:   "text/html",
+			},
+			want: want{
+				code: http.StatusUnauthorized,
+				data: `<form method="post">`,
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: passwordUnlocker,
+			},
+		},
+		{
+			name: "Unprotected URL, status code: SeeOther",
+			req: request{
+				api: "/112Sd",
+			},
+			want: want{
+				code:     http.StatusSeeOther,
+				location: "/112Sd",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						return models.NewShortenedURL("1", "1", "http://example.com/doc",
+							s, "http://localhost:8080/"+s), nil
+					},
+				},
+			},
+		},
+		{
+			name: "URL by 112Sd was deleted, status code: Gone",
+			req: request{
+				api:      "/112Sd",
+				password: "secret",
+			},
+			want: want{
+				code: http.StatusGone,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url, _ := protectedURL(ctx, s)
+						url.SetDeleted()
+						return url, nil
+					},
+				},
+				unlocker: passwordUnlocker,
+			},
+		},
+		{
+			name: "URL by 112Sd is not active yet, status code: Forbidden",
+			req: request{
+				api:      "/112Sd",
+				password: "secret",
+			},
+			want: want{
+				code: http.StatusForbidden,
+				data: "Coming soon",
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						url, _ := protectedURL(ctx, s)
+						url.ActivateAt = time.Now().Add(time.Hour)
+						return url, nil
+					},
+				},
+				unlocker: passwordUnlocker,
+			},
+		},
+		{
+			name: "URL doesn't exist, status code: NotFound",
+			req: request{
+				api:      "/any_slug",
+				password: "secret",
+			},
+			want: want{
+				code: http.StatusNotFound,
+			},
+			serv: services{
+				getter: &getterBySlugMock{
+					GetBySlugFn: func(ctx context.Context, s string) (models.ShortenedURL, error) {
+						return models.ShortenedURL{}, nil
+					},
+				},
+			},
+		},
+		{
+			name: "Failed to unlock, status code: InternalServerError",
+			req: request{
+				api:      "/112Sd",
+				password: "secret",
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+			},
+			serv: services{
+				getter:   &getterBySlugMock{GetBySlugFn: protectedURL},
+				unlocker: &unlockerMock{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup gin.Router.
+			r := setupGin()
+			r.POST("/:slug", unlock(tt.serv.getter, tt.serv.unlocker, pending))
+
+			w := httptest.NewRecorder()
+			// Prepare the request.
+			form := url.Values{"password": {tt.req.password}}
+			req, err := http.NewRequest(http.MethodPost, tt.req.api, strings.NewReader(form.Encode()))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if len(tt.req.accept) != 0 {
+				req.Header.Set("Accept", tt.req.accept)
+			}
+
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.want.code, resp.StatusCode,
+				"Expected response status code: %d, got %d", tt.want.code, resp.StatusCode)
+			assert.Equal(t, tt.want.location, resp.Header.Get("Location"))
+			assert.Contains(t, string(respBody), tt.want.data)
+
+			var cookie *http.Cookie
+			for _, v := range resp.Cookies() {
+				if v.Name == unlockCookie {
+					cookie = v
+				}
+			}
+			if len(tt.want.cookie) == 0 {
+				assert.Nil(t, cookie)
+				return
+			}
+			require.NotNil(t, cookie)
+			assert.Equal(t, tt.want.cookie, cookie.Value)
+			assert.Equal(t, "/112Sd", cookie.Path)
+			assert.Equal(t, 60, cookie.MaxAge)
+			assert.True(t, cookie.HttpOnly)
+		})
+	}
+}
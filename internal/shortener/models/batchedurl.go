@@ -0,0 +1,26 @@
+package models
+
+// BatchStatus is a status of the URL in a batch.
+type BatchStatus string
+
+// Batch statuses.
+const (
+	// BatchCreated is for the URL shortened by the batch.
+	BatchCreated BatchStatus = "created"
+	// BatchExisted is for the URL that had been shortened before.
+	BatchExisted BatchStatus = "existed"
+)
+
+// BatchedURL represents the result of batching the URL.
+type BatchedURL struct {
+	ShortenedURL
+	Status BatchStatus
+}
+
+// NewBatchedURL returns a new BatchedURL.
+func NewBatchedURL(url ShortenedURL, status BatchStatus) BatchedURL {
+	return BatchedURL{
+		ShortenedURL: url,
+		Status:       status,
+	}
+}
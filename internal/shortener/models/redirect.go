@@ -0,0 +1,83 @@
+package models
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Cache policies of the redirect of the shortened URL.
+const (
+	// CacheNoStore forbids caching the redirect, every click reaches the shortener.
+	CacheNoStore = "no-store"
+	// CachePrivate lets the browsers cache the redirect.
+	CachePrivate = "private"
+	// CachePublic lets the browsers and the shared caches cache the redirect.
+	CachePublic = "public"
+)
+
+// ValidRedirectStatus checks whether the status is one of the redirect statuses
+// of the shortened URL: 301, 302, 307 or 308.
+func ValidRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// ValidCachePolicy checks whether the policy is one of the cache policies of the redirect.
+func ValidCachePolicy(policy string) bool {
+	switch policy {
+	case CacheNoStore, CachePrivate, CachePublic:
+		return true
+	}
+	return false
+}
+
+// Query modes of the redirect of the shortened URL.
+const (
+	// QueryDrop drops the query of the request, it is the default.
+	QueryDrop = "drop"
+	// QueryMerge adds the request parameters the destination does not have.
+	QueryMerge = "merge"
+	// QueryOverride sets the request parameters on the destination, replacing its ones.
+	QueryOverride = "override"
+)
+
+// ValidQueryMode checks whether the mode is one of the query modes of the redirect.
+func ValidQueryMode(mode string) bool {
+	switch mode {
+	case QueryDrop, QueryMerge, QueryOverride:
+		return true
+	}
+	return false
+}
+
+// EncodeParams returns the encoded query of the fixed parameters of the redirect.
+func EncodeParams(params map[string]string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	values := make(url.Values, len(params))
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// DecodeParams returns the fixed parameters of the redirect of the encoded query.
+// It returns nil if there are none or the query is invalid.
+func DecodeParams(params string) map[string]string {
+	values, err := url.ParseQuery(params)
+	if err != nil || len(values) == 0 {
+		return nil
+	}
+	decoded := make(map[string]string, len(values))
+	for k := range values {
+		decoded[k] = values.Get(k)
+	}
+	return decoded
+}
@@ -2,15 +2,60 @@ package models
 
 import (
 	"fmt"
+	"time"
 )
 
 // ShortenedURL represents the shortened URL.
 type ShortenedURL struct {
-	UserID    string
-	CorrID    string
-	Raw       string
-	Slug      string
-	Value     string
+	UserID string
+	CorrID string
+
+	// Raw is the original URL the short one redirects to.
+	Raw string
+
+	// Canonical is the canonical form of Raw the URLs are deduplicated by.
+	Canonical string
+
+	Slug  string
+	Value string
+
+	// Threat is the blocklist entry the destination matched after it had been shortened.
+	// It is set on reading and is not stored.
+	Threat string
+
+	// ExpiresAt is the time the URL stops working at, the zero time is never.
+	ExpiresAt time.Time
+
+	// IsExpired is set by the expiration sweep, the expired URLs are not collected by user.
+	IsExpired bool
+
+	// ActivateAt and DeactivateAt are the window the URL redirects within,
+	// the zero time leaves it open on that side. The URL is collected regardless of it.
+	ActivateAt   time.Time
+	DeactivateAt time.Time
+
+	// MaxVisits is the number of the redirects of the visit-limited URL, zero is unlimited.
+	MaxVisits int
+
+	// Visits is the number of the counted visits.
+	Visits int
+
+	// PasswordHash is the bcrypt hash of the password, it is empty for the unprotected URL.
+	PasswordHash string
+
+	// RedirectStatus and CachePolicy are chosen at creation, the zero values are the service defaults.
+	RedirectStatus int
+	CachePolicy    string
+
+	// QueryMode is how the query of the request is forwarded.
+	QueryMode string
+
+	// FixedParams is the encoded query set on every redirect.
+	FixedParams string
+
+	// ForwardPath forwards the path after the slug.
+	ForwardPath bool
+
 	IsDeleted bool
 }
 
@@ -31,6 +76,53 @@ func NewShortenedURL(
 	}
 }
 
+// DedupKey returns the URL the shortened URLs are deduplicated by:
+// the canonical form or the raw URL if the canonical one is not set.
+func (s *ShortenedURL) DedupKey() string {
+	if len(s.Canonical) != 0 {
+		return s.Canonical
+	}
+	return s.Raw
+}
+
+// Expired checks whether the shortened URL has expired by the time.
+func (s *ShortenedURL) Expired(now time.Time) bool {
+	return s.IsExpired ||
+		!s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// Pending checks whether the shortened URL is not active yet by the time.
+func (s *ShortenedURL) Pending(now time.Time) bool {
+	return !s.ActivateAt.IsZero() && now.Before(s.ActivateAt)
+}
+
+// Deactivated checks whether the shortened URL has been deactivated by the time.
+func (s *ShortenedURL) Deactivated(now time.Time) bool {
+	return !s.DeactivateAt.IsZero() && !now.Before(s.DeactivateAt)
+}
+
+// VisitsLeft returns the number of the visits left of the visit-limited URL,
+// -1 is returned for the unlimited one.
+func (s *ShortenedURL) VisitsLeft() int {
+	if s.MaxVisits <= 0 {
+		return -1
+	}
+	if s.Visits >= s.MaxVisits {
+		return 0
+	}
+	return s.MaxVisits - s.Visits
+}
+
+// Protected checks whether the shortened URL is password-protected.
+func (s *ShortenedURL) Protected() bool {
+	return len(s.PasswordHash) != 0
+}
+
+// Flagged checks whether the destination is on the threat blocklist.
+func (s *ShortenedURL) Flagged() bool {
+	return len(s.Threat) != 0
+}
+
 // SetDeleted sets IsDeleted as true.
 func (s *ShortenedURL) SetDeleted() {
 	s.IsDeleted = true
@@ -41,15 +133,34 @@ func (s *ShortenedURL) Empty() bool {
 	return len(s.UserID) == 0 &&
 		len(s.CorrID) == 0 &&
 		len(s.Raw) == 0 &&
+		len(s.Canonical) == 0 &&
 		len(s.Value) == 0 &&
 		len(s.Slug) == 0 &&
+		s.ExpiresAt.IsZero() &&
+		!s.IsExpired &&
+		s.ActivateAt.IsZero() &&
+		s.DeactivateAt.IsZero() &&
+		s.MaxVisits == 0 &&
+		s.Visits == 0 &&
+		len(s.PasswordHash) == 0 &&
+		s.RedirectStatus == 0 &&
+		len(s.CachePolicy) == 0 &&
+		len(s.QueryMode) == 0 &&
+		len(s.FixedParams) == 0 &&
+		!s.ForwardPath &&
 		!s.IsDeleted
 }
 
 // String represents ShortURL as a string.
 func (s *ShortenedURL) String() string {
-	return fmt.Sprintf("shortenedURL[userID: %s, corrID: %s, raw: %s, slug: %v, value: %s, deleted: %t]",
-		s.UserID, s.CorrID, s.Raw, s.Slug, s.Value, s.IsDeleted)
+	return fmt.Sprintf("shortenedURL[userID: %s, corrID: %s, raw: %s, canonical: %s, slug: %v, value: %s, "+
+		"expiresAt: %s, expired: %t, activateAt: %s, deactivateAt: %s, maxVisits: %d, visits: %d, "+
+		"protected: %t, redirectStatus: %d, cachePolicy: %s, "+
+		"queryMode: %s, fixedParams: %s, forwardPath: %t, deleted: %t]",
+		s.UserID, s.CorrID, s.Raw, s.Canonical, s.Slug, s.Value,
+		formatTime(s.ExpiresAt), s.IsExpired, formatTime(s.ActivateAt), formatTime(s.DeactivateAt),
+		s.MaxVisits, s.Visits, s.Protected(), s.RedirectStatus, s.CachePolicy,
+		s.QueryMode, s.FixedParams, s.ForwardPath, s.IsDeleted)
 }
 
 // Equals compares ShortenedURLs.
@@ -57,7 +168,20 @@ func (s *ShortenedURL) Equals(s1 ShortenedURL) bool {
 	return s.UserID == s1.UserID &&
 		s.CorrID == s1.CorrID &&
 		s.Raw == s1.Raw &&
+		s.Canonical == s1.Canonical &&
 		s.Value == s1.Value &&
 		s.Slug == s1.Slug &&
+		s.ExpiresAt.Equal(s1.ExpiresAt) &&
+		s.IsExpired == s1.IsExpired &&
+		s.ActivateAt.Equal(s1.ActivateAt) &&
+		s.DeactivateAt.Equal(s1.DeactivateAt) &&
+		s.MaxVisits == s1.MaxVisits &&
+		s.Visits == s1.Visits &&
+		s.PasswordHash == s1.PasswordHash &&
+		s.RedirectStatus == s1.RedirectStatus &&
+		s.CachePolicy == s1.CachePolicy &&
+		s.QueryMode == s1.QueryMode &&
+		s.FixedParams == s1.FixedParams &&
+		s.ForwardPath == s1.ForwardPath &&
 		s.IsDeleted == s1.IsDeleted
 }
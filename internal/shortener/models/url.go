@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 )
 
 // URL is a representation of the URL that should be shortened.
@@ -9,6 +10,38 @@ type URL struct {
 	UserID string
 	CorrID string
 	Raw    string
+
+	// Alias is the optional custom slug.
+	Alias string
+
+	// ExpiresAt or TTL since shortening is the expiry, at most one of them is set.
+	// The URL never expires if neither is set.
+	ExpiresAt time.Time
+	TTL       time.Duration
+
+	// ActivateAt and DeactivateAt are the optional window the URL redirects within,
+	// the zero time leaves it open on that side.
+	ActivateAt   time.Time
+	DeactivateAt time.Time
+
+	// MaxVisits is the number of the redirects, zero is unlimited.
+	MaxVisits int
+
+	// Password is the optional password protecting the URL.
+	Password string
+
+	// RedirectStatus and CachePolicy are optional, the service defaults are used if not set.
+	RedirectStatus int
+	CachePolicy    string
+
+	// QueryMode is how the query of the request is forwarded.
+	QueryMode string
+
+	// FixedParams is the encoded query set on every redirect, e.g. the UTM parameters.
+	FixedParams string
+
+	// ForwardPath appends the path after the slug to the path of Raw.
+	ForwardPath bool
 }
 
 // NewURL returns a new URL.
@@ -22,20 +55,56 @@ func NewURL(userID string, corrID string, raw string) URL {
 
 // String returns URl as string.
 func (u URL) String() string {
-	return fmt.Sprintf("url[userID: %s, corrID: %s, value: %s]",
-		u.UserID, u.CorrID, u.Raw)
+	return fmt.Sprintf("url[userID: %s, corrID: %s, value: %s, alias: %s, expiresAt: %s, ttl: %v, "+
+		"activateAt: %s, deactivateAt: %s, maxVisits: %d, protected: %t, redirectStatus: %d, cachePolicy: %s, "+
+		"queryMode: %s, fixedParams: %s, forwardPath: %t]",
+		u.UserID, u.CorrID, u.Raw, u.Alias, formatTime(u.ExpiresAt), u.TTL,
+		formatTime(u.ActivateAt), formatTime(u.DeactivateAt), u.MaxVisits, len(u.Password) != 0,
+		u.RedirectStatus, u.CachePolicy, u.QueryMode, u.FixedParams, u.ForwardPath)
 }
 
 // Equals compares URLs.
 func (u URL) Equals(url URL) bool {
 	return u.UserID == url.UserID &&
 		u.CorrID == url.CorrID &&
-		u.Raw == url.Raw
+		u.Raw == url.Raw &&
+		u.Alias == url.Alias &&
+		u.ExpiresAt.Equal(url.ExpiresAt) &&
+		u.TTL == url.TTL &&
+		u.ActivateAt.Equal(url.ActivateAt) &&
+		u.DeactivateAt.Equal(url.DeactivateAt) &&
+		u.MaxVisits == url.MaxVisits &&
+		u.Password == url.Password &&
+		u.RedirectStatus == url.RedirectStatus &&
+		u.CachePolicy == url.CachePolicy &&
+		u.QueryMode == url.QueryMode &&
+		u.FixedParams == url.FixedParams &&
+		u.ForwardPath == url.ForwardPath
 }
 
 // Empty checks on being empty.
 func (u URL) Empty() bool {
 	return len(u.UserID) == 0 &&
 		len(u.CorrID) == 0 &&
-		len(u.Raw) == 0
+		len(u.Raw) == 0 &&
+		len(u.Alias) == 0 &&
+		u.ExpiresAt.IsZero() &&
+		u.TTL == 0 &&
+		u.ActivateAt.IsZero() &&
+		u.DeactivateAt.IsZero() &&
+		u.MaxVisits == 0 &&
+		len(u.Password) == 0 &&
+		u.RedirectStatus == 0 &&
+		len(u.CachePolicy) == 0 &&
+		len(u.QueryMode) == 0 &&
+		len(u.FixedParams) == 0 &&
+		!u.ForwardPath
+}
+
+// formatTime formats the time as RFC 3339, the zero time is empty.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
 }
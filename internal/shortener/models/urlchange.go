@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// URLChange represents a change of the shortened URL destination made by its owner.
+//
+// Version is the version of the destination made by the change, the destination
+// the URL was shortened with is version 1. The change with Version set before
+// the edit is made only if it gets that version. Canonical is the canonical form
+// of New, the edited URL is deduplicated by it.
+type URLChange struct {
+	Slug      string
+	Version   int
+	Old       string
+	New       string
+	Canonical string
+	UserID    string
+	ChangedAt time.Time
+}
+
+// DedupKey returns the URL the edited URL is deduplicated by:
+// the canonical form or the new URL if the canonical one is not set.
+func (c *URLChange) DedupKey() string {
+	if len(c.Canonical) != 0 {
+		return c.Canonical
+	}
+	return c.New
+}
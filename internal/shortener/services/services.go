@@ -3,6 +3,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 )
@@ -10,12 +11,12 @@ import (
 // Shortener defines the url Shortener.
 type Shortener interface {
 	Short(context.Context, models.URL) (string, error)
-	Batch(context.Context, []models.URL) ([]models.ShortenedURL, error)
+	Batch(context.Context, []models.URL) ([]models.BatchedURL, error)
 }
 
 // Provider defines the shortened URL Provider.
 type Provider interface {
-	GetByURL(context.Context, string) (models.ShortenedURL, error)
+	GetByURL(context.Context, string, string) (models.ShortenedURL, error)
 	GetBySlug(context.Context, string) (models.ShortenedURL, error)
 	CollectByUser(context.Context, string) ([]models.ShortenedURL, error)
 }
@@ -25,11 +26,35 @@ type Deleter interface {
 	Delete(userID string, slugs []string) error
 }
 
+// Editor defines the editor of the shortened URL destinations.
+type Editor interface {
+	Edit(context.Context, string, string, string) (models.URLChange, error)
+	History(context.Context, string, string) ([]models.URLChange, error)
+	Rollback(context.Context, string, string, int) (models.URLChange, error)
+}
+
 // StatProvider defines the shortened URLs statistics provider.
 type StatProvider interface {
 	Stat(context.Context) (models.Stat, error)
 }
 
+// Visitor defines the visits counter of the visit-limited shortened URLs.
+type Visitor interface {
+	Visit(context.Context, string) (int, error)
+}
+
+// Unlocker defines the unlocker of the password-protected shortened URLs.
+type Unlocker interface {
+	Unlock(models.ShortenedURL, string) (string, error)
+	Unlocked(models.ShortenedURL, string) bool
+	TTL() time.Duration
+}
+
+// Expirer defines the marker of the shortened URLs expired by the time.
+type Expirer interface {
+	Expire(context.Context, time.Time) (int, error)
+}
+
 // Pinger defines the network pinger.
 type Pinger interface {
 	Ping() error
@@ -39,6 +64,9 @@ type Pinger interface {
 type Services struct {
 	Shortener      Shortener
 	Provider       Provider
+	Visitor        Visitor
+	Unlocker       Unlocker
+	Editor         Editor
 	Deleter        Deleter
 	Statistic      StatProvider
 	PostgresPinger Pinger
@@ -48,6 +76,9 @@ type Services struct {
 func NewServices(
 	shortener Shortener,
 	provider Provider,
+	visitor Visitor,
+	unlocker Unlocker,
+	editor Editor,
 	deleter Deleter,
 	statistic StatProvider,
 	pgxPinger Pinger,
@@ -55,6 +86,9 @@ func NewServices(
 	return &Services{
 		Shortener:      shortener,
 		Provider:       provider,
+		Visitor:        visitor,
+		Unlocker:       unlocker,
+		Editor:         editor,
 		Deleter:        deleter,
 		Statistic:      statistic,
 		PostgresPinger: pgxPinger,
@@ -0,0 +1,45 @@
+package shorturl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Alias lengths.
+const (
+	minAliasLength = 3
+	maxAliasLength = maxSlugLength
+)
+
+// reservedAliases are the first path segments of the routes, the aliases
+// and the generated slugs must not shadow them.
+var reservedAliases = map[string]bool{
+	"api":   true,
+	"ping":  true,
+	"debug": true,
+}
+
+// validateAlias checks that the custom alias is of minAliasLength to maxAliasLength
+// letters, digits, hyphens and underscores, starts with a letter or a digit
+// and is not reserved. The reserved words are case-insensitive.
+func validateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return fmt.Errorf("alias length must be from %d to %d", minAliasLength, maxAliasLength)
+	}
+
+	for i := 0; i < len(alias); i++ {
+		c := alias[i]
+		alnum := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+		if i == 0 && !alnum {
+			return fmt.Errorf("alias must start with a letter or a digit")
+		}
+		if !alnum && c != '-' && c != '_' {
+			return fmt.Errorf("invalid alias character: %q", c)
+		}
+	}
+
+	if reservedAliases[strings.ToLower(alias)] {
+		return fmt.Errorf("alias %q is reserved", alias)
+	}
+	return nil
+}
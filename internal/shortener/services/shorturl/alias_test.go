@@ -0,0 +1,41 @@
+package shorturl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		wantErr bool
+	}{
+		{name: "Valid alias", alias: "spring-sale"},
+		{name: "Letters, digits and underscores", alias: "Sale_2024"},
+		{name: "Minimum length", alias: "abc"},
+		{name: "Maximum length", alias: strings.Repeat("a", maxAliasLength)},
+		{name: "Too short", alias: "ab", wantErr: true},
+		{name: "Too long", alias: strings.Repeat("a", maxAliasLength+1), wantErr: true},
+		{name: "Starts with hyphen", alias: "-sale", wantErr: true},
+		{name: "Slash", alias: "spring/sale", wantErr: true},
+		{name: "Space", alias: "spring sale", wantErr: true},
+		{name: "Non-ASCII", alias: "распродажа", wantErr: true},
+		{name: "Reserved", alias: "api", wantErr: true},
+		{name: "Reserved, other case", alias: "Ping", wantErr: true},
+		{name: "Reserved prefix", alias: "api-docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAlias(tt.alias)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package shorturl
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
+	"github.com/caarlos0/env/v6"
+)
+
+// Blocklist entry prefixes. The entry without a prefix is a host.
+const (
+	// BlockHost blocks the host, the host starting with "*." blocks its subdomains.
+	BlockHost = "host:"
+	// BlockHash blocks the URL by the hex SHA-256 hash of its raw or canonical form.
+	BlockHash = "sha256:"
+	// BlockRegex blocks the raw or canonical URL matching the regular expression.
+	BlockRegex = "regex:"
+)
+
+// BlocklistConfig represents the threat blocklist configuration.
+type BlocklistConfig struct {
+	// Path is the path of the blocklist file, one entry per line.
+	// The empty lines and the lines starting with # are skipped.
+	// Nothing is blocked if it is empty.
+	Path string `env:"URL_BLOCKLIST_PATH" envDefault:""`
+
+	// ReloadInterval is the interval of checking the file on change.
+	ReloadInterval time.Duration `env:"URL_BLOCKLIST_RELOAD_INTERVAL" envDefault:"30s"`
+}
+
+// Empty checks on being empty.
+func (c BlocklistConfig) Empty() bool {
+	return len(c.Path) == 0 &&
+		c.ReloadInterval == 0
+}
+
+// defaultBlocklistReloadInterval is the reload interval if none is configured.
+const defaultBlocklistReloadInterval = 30 * time.Second
+
+// blockRules are the parsed blocklist entries.
+type blockRules struct {
+	hosts   hostMatcher
+	hashes  map[string]bool
+	regexps []*regexp.Regexp
+	entries int
+}
+
+// blocklist is the threat blocklist of the phishing and malware destinations.
+// It is reloaded when the file changes.
+type blocklist struct {
+	path  string
+	canon urlCanonicalizer
+
+	mu      sync.RWMutex
+	rules   blockRules
+	modTime time.Time
+	size    int64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Blocklist returns a new blocklist of the configured file. The URLs are also checked
+// by their canonical form if the canonicalizer is not nil.
+func Blocklist(cfg BlocklistConfig, canon urlCanonicalizer) (*blocklist, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if cfg.ReloadInterval < 0 {
+		return nil, fmt.Errorf("negative blocklist reload interval")
+	}
+	if cfg.ReloadInterval == 0 {
+		cfg.ReloadInterval = defaultBlocklistReloadInterval
+	}
+
+	b := &blocklist{
+		path:  cfg.Path,
+		canon: canon,
+		rules: blockRules{hosts: newHostMatcher(nil)},
+		done:  make(chan struct{}),
+	}
+	if len(b.path) == 0 {
+		return b, nil
+	}
+	if _, err := b.reload(); err != nil {
+		return nil, err
+	}
+
+	b.wg.Add(1)
+	go b.reloader(cfg.ReloadInterval)
+
+	return b, nil
+}
+
+// Close stops reloading the blocklist.
+func (b *blocklist) Close() error {
+	select {
+	case <-b.done:
+	default:
+		close(b.done)
+	}
+	b.wg.Wait()
+	return nil
+}
+
+// reloader reloads the changed file periodically until the blocklist is closed.
+// The previous entries are kept if the file fails to reload.
+func (b *blocklist) reloader(interval time.Duration) {
+	defer b.wg.Done()
+
+	logger := zerologx.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			n, err := b.reload()
+			if err != nil {
+				logger.Error().Err(err).Msg("shorturl: failed to reload blocklist")
+				continue
+			}
+			if n >= 0 {
+				logger.Info().Int("entries", n).Msg("shorturl: blocklist reloaded")
+			}
+		}
+	}
+}
+
+// reload reads the file again if it has changed since the last load. It returns
+// the number of the loaded entries or -1 if the file has not changed.
+func (b *blocklist) reload() (int, error) {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat blocklist file: %v", err)
+	}
+
+	b.mu.RLock()
+	unchanged := info.ModTime().Equal(b.modTime) && info.Size() == b.size
+	b.mu.RUnlock()
+	if unchanged {
+		return -1, nil
+	}
+
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read blocklist file: %v", err)
+	}
+	rules, err := parseBlocklist(data)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	b.rules = rules
+	b.modTime, b.size = info.ModTime(), info.Size()
+	b.mu.Unlock()
+
+	return rules.entries, nil
+}
+
+// parseBlocklist parses the blocklist entries.
+func parseBlocklist(data []byte) (blockRules, error) {
+	var (
+		hosts []string
+		rules = blockRules{hashes: make(map[string]bool)}
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if len(entry) == 0 || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(entry, BlockHash):
+			hash := strings.ToLower(strings.TrimPrefix(entry, BlockHash))
+			if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+				return blockRules{}, fmt.Errorf("invalid blocklist hash at line %d", line)
+			}
+			rules.hashes[hash] = true
+		case strings.HasPrefix(entry, BlockRegex):
+			re, err := regexp.Compile(strings.TrimPrefix(entry, BlockRegex))
+			if err != nil {
+				return blockRules{}, fmt.Errorf("invalid blocklist regex at line %d: %v", line, err)
+			}
+			rules.regexps = append(rules.regexps, re)
+		default:
+			host := strings.TrimPrefix(entry, BlockHost)
+			if len(host) == 0 || strings.ContainsAny(host, "/ ") {
+				return blockRules{}, fmt.Errorf("invalid blocklist host at line %d", line)
+			}
+			hosts = append(hosts, host)
+		}
+		rules.entries++
+	}
+	if err := scanner.Err(); err != nil {
+		return blockRules{}, fmt.Errorf("failed to scan blocklist file: %v", err)
+	}
+
+	rules.hosts = newHostMatcher(hosts)
+	return rules, nil
+}
+
+// Check checks the raw URL against the blocklist. The blocked URL is reported
+// as *Rejection with RejectedThreat.
+func (b *blocklist) Check(raw string) error {
+	urls := []string{raw}
+	if b.canon != nil {
+		if canonical, err := b.canon.Canonical(raw); err == nil && canonical != raw {
+			urls = append(urls, canonical)
+		}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if u, err := url.Parse(raw); err == nil {
+		if host := normalizeHost(u.Hostname()); len(host) != 0 && b.rules.hosts.match(host) {
+			return &Rejection{Reason: RejectedThreat, Detail: host}
+		}
+	}
+	for _, v := range urls {
+		sum := sha256.Sum256([]byte(v))
+		if hash := hex.EncodeToString(sum[:]); b.rules.hashes[hash] {
+			return &Rejection{Reason: RejectedThreat, Detail: BlockHash + hash}
+		}
+		for _, re := range b.rules.regexps {
+			if re.MatchString(v) {
+				return &Rejection{Reason: RejectedThreat, Detail: BlockRegex + re.String()}
+			}
+		}
+	}
+	return nil
+}
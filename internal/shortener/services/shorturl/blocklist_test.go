@@ -0,0 +1,205 @@
+package shorturl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func urlHash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeBlocklist(t *testing.T, path, data string, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestBlocklist_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	writeBlocklist(t, path, `# phishing
+evil.com
+host:*.malware.example
+
+sha256:`+urlHash("http://demo.com/phish")+`
+sha256:`+urlHash("http://demo.com/b")+`
+regex:^https?://[^/]+/wp-admin/
+`, time.Now())
+
+	b, err := Blocklist(BlocklistConfig{Path: path, ReloadInterval: time.Hour}, testCanon)
+	require.NoError(t, err)
+	defer b.Close()
+
+	tests := []struct {
+		name   string
+		raw    string
+		detail string
+	}{
+		{
+			name: "Allowed URL",
+			raw:  "http://demo.com",
+		},
+		{
+			name:   "Host is blocked",
+			raw:    "https://EVIL.com/login",
+			detail: "evil.com",
+		},
+		{
+			name: "Subdomain of the exact host is allowed",
+			raw:  "https://www.evil.com/login",
+		},
+		{
+			name:   "Subdomain is blocked by wildcard",
+			raw:    "http://cdn.malware.example/x.exe",
+			detail: "cdn.malware.example",
+		},
+		{
+			name:   "URL hash is blocked",
+			raw:    "http://demo.com/phish",
+			detail: BlockHash + urlHash("http://demo.com/phish"),
+		},
+		{
+			name:   "Canonical URL hash is blocked",
+			raw:    "HTTP://demo.com:80/a/../b",
+			detail: BlockHash + urlHash("http://demo.com/b"),
+		},
+		{
+			name:   "URL is blocked by regex",
+			raw:    "http://demo.com/wp-admin/setup.php",
+			detail: BlockRegex + "^https?://[^/]+/wp-admin/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := b.Check(tt.raw)
+			if len(tt.detail) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var rej *Rejection
+			require.ErrorAs(t, err, &rej)
+			assert.Equal(t, RejectedThreat, rej.Reason)
+			assert.Equal(t, tt.detail, rej.Detail)
+		})
+	}
+}
+
+func TestBlocklist(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		writeBlocklist(t, path, data, time.Now())
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		cfg     BlocklistConfig
+		wantErr bool
+	}{
+		{
+			name: "Valid file",
+			cfg:  BlocklistConfig{Path: write("valid.txt", "evil.com\n")},
+		},
+		{
+			name: "Nothing is blocked without file",
+			cfg:  BlocklistConfig{ReloadInterval: time.Minute},
+		},
+		{
+			name:    "File does not exist",
+			cfg:     BlocklistConfig{Path: filepath.Join(dir, "missing.txt")},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid hash",
+			cfg:     BlocklistConfig{Path: write("hash.txt", "sha256:abc\n")},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid regex",
+			cfg:     BlocklistConfig{Path: write("regex.txt", "regex:[a-\n")},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid host",
+			cfg:     BlocklistConfig{Path: write("host.txt", "http://evil.com/path\n")},
+			wantErr: true,
+		},
+		{
+			name:    "Negative reload interval",
+			cfg:     BlocklistConfig{Path: write("interval.txt", ""), ReloadInterval: -time.Second},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Blocklist(tt.cfg, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, b.Check("http://demo.com"))
+			assert.NoError(t, b.Close())
+			// Close is idempotent.
+			assert.NoError(t, b.Close())
+		})
+	}
+}
+
+func TestBlocklist_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	modTime := time.Now().Add(-time.Hour)
+	writeBlocklist(t, path, "evil.com\n", modTime)
+
+	b, err := Blocklist(BlocklistConfig{Path: path, ReloadInterval: time.Hour}, nil)
+	require.NoError(t, err)
+	defer b.Close()
+
+	require.Error(t, b.Check("http://evil.com"))
+	require.NoError(t, b.Check("http://phish.com"))
+
+	// The unchanged file is not read again.
+	n, err := b.reload()
+	require.NoError(t, err)
+	assert.Equal(t, -1, n)
+
+	writeBlocklist(t, path, "phish.com\n", modTime.Add(time.Minute))
+	n, err = b.reload()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, b.Check("http://evil.com"))
+	assert.Error(t, b.Check("http://phish.com"))
+
+	// The previous entries are kept if the file is invalid.
+	writeBlocklist(t, path, "regex:[a-\n", modTime.Add(2*time.Minute))
+	_, err = b.reload()
+	require.Error(t, err)
+	assert.Error(t, b.Check("http://phish.com"))
+}
+
+func TestBlocklist_Reloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	modTime := time.Now().Add(-time.Hour)
+	writeBlocklist(t, path, "evil.com\n", modTime)
+
+	b, err := Blocklist(BlocklistConfig{Path: path, ReloadInterval: 10 * time.Millisecond}, nil)
+	require.NoError(t, err)
+	defer b.Close()
+
+	writeBlocklist(t, path, "evil.com\nphish.com\n", modTime.Add(time.Minute))
+	assert.Eventually(t, func() bool {
+		return b.Check("http://phish.com") != nil
+	}, time.Second, 10*time.Millisecond)
+}
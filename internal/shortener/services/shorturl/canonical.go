@@ -0,0 +1,263 @@
+package shorturl
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	"golang.org/x/net/idna"
+)
+
+// Canonicalization steps.
+const (
+	// CanonicalLowercase lowercases the scheme and the host.
+	CanonicalLowercase = "lowercase"
+	// CanonicalDefaultPort drops the default port of the scheme.
+	CanonicalDefaultPort = "default-port"
+	// CanonicalDotSegments resolves the dot-segments of the path.
+	CanonicalDotSegments = "dot-segments"
+	// CanonicalIDN converts the internationalized host to punycode.
+	CanonicalIDN = "idn"
+	// CanonicalSortQuery sorts the query parameters.
+	CanonicalSortQuery = "sort-query"
+	// CanonicalStripTracking drops the tracking query parameters.
+	CanonicalStripTracking = "strip-tracking"
+)
+
+// defaultCanonicalSteps are the canonicalization steps if none are configured.
+var defaultCanonicalSteps = []string{
+	CanonicalLowercase,
+	CanonicalDefaultPort,
+	CanonicalDotSegments,
+	CanonicalIDN,
+	CanonicalSortQuery,
+}
+
+// defaultTrackingParams are the tracking query parameters if none are configured.
+var defaultTrackingParams = []string{"utm_*", "gclid", "fbclid", "yclid", "mc_cid", "mc_eid"}
+
+// CanonicalConfig represents the URL canonicalization configuration.
+type CanonicalConfig struct {
+	// Steps are the canonicalization steps applied in order.
+	Steps []string `env:"URL_CANONICAL_STEPS" envSeparator:"," envDefault:"lowercase,default-port,dot-segments,idn,sort-query"`
+
+	// TrackingParams are the query parameters dropped by CanonicalStripTracking.
+	// The name ending with * matches the parameters with the prefix.
+	TrackingParams []string `env:"URL_TRACKING_PARAMS" envSeparator:"," envDefault:"utm_*,gclid,fbclid,yclid,mc_cid,mc_eid"`
+}
+
+// Empty checks on being empty.
+func (c CanonicalConfig) Empty() bool {
+	return len(c.Steps) == 0 &&
+		len(c.TrackingParams) == 0
+}
+
+// canonicalStep is a step of the canonicalization pipeline.
+type canonicalStep func(u *url.URL) error
+
+// canonicalizer canonicalizes the URLs by the pipeline of steps, so the different forms
+// of the same URL are deduplicated.
+type canonicalizer struct {
+	steps    []canonicalStep
+	tracking []string
+}
+
+// Canonicalizer returns a new canonicalizer of the configured steps.
+func Canonicalizer(cfg CanonicalConfig) (*canonicalizer, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if len(cfg.Steps) == 0 {
+		cfg.Steps = defaultCanonicalSteps
+	}
+	if len(cfg.TrackingParams) == 0 {
+		cfg.TrackingParams = defaultTrackingParams
+	}
+
+	c := &canonicalizer{
+		tracking: cfg.TrackingParams,
+	}
+	for _, name := range cfg.Steps {
+		var step canonicalStep
+		switch strings.TrimSpace(name) {
+		case CanonicalLowercase:
+			step = lowercase
+		case CanonicalDefaultPort:
+			step = dropDefaultPort
+		case CanonicalDotSegments:
+			step = resolveDotSegments
+		case CanonicalIDN:
+			step = punycode
+		case CanonicalSortQuery:
+			step = sortQuery
+		case CanonicalStripTracking:
+			step = c.stripTracking
+		default:
+			return nil, fmt.Errorf("unknown canonicalization step: %v", name)
+		}
+		c.steps = append(c.steps, step)
+	}
+
+	return c, nil
+}
+
+// Canonical returns the canonical form of the raw URL.
+func (c *canonicalizer) Canonical(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %v", err)
+	}
+
+	for _, step := range c.steps {
+		if err = step(u); err != nil {
+			return "", err
+		}
+	}
+	return u.String(), nil
+}
+
+// lowercase lowercases the scheme and the host.
+func lowercase(u *url.URL) error {
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return nil
+}
+
+// defaultPorts are the default ports of the schemes.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// dropDefaultPort drops the default port of the scheme.
+func dropDefaultPort(u *url.URL) error {
+	if port := u.Port(); len(port) != 0 && defaultPorts[strings.ToLower(u.Scheme)] == port {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	return nil
+}
+
+// resolveDotSegments resolves the dot-segments of the path as RFC 3986 does.
+// The escaped path is resolved, so the escaped slashes are kept.
+func resolveDotSegments(u *url.URL) error {
+	p := removeDotSegments(u.EscapedPath())
+	path, err := url.PathUnescape(p)
+	if err != nil {
+		return fmt.Errorf("failed to unescape path: %v", err)
+	}
+	u.Path, u.RawPath = path, p
+	return nil
+}
+
+// removeDotSegments removes the "." and ".." segments of the absolute path.
+func removeDotSegments(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		return p
+	}
+
+	segs := strings.Split(p, "/")
+	out := make([]string, 0, len(segs))
+	for i, seg := range segs {
+		last := i == len(segs)-1
+		switch seg {
+		case ".":
+		case "..":
+			// The leading empty segment is the root.
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+		default:
+			out = append(out, seg)
+			continue
+		}
+		// The path ending with a dot-segment is a directory.
+		if last {
+			out = append(out, "")
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+// punycode converts the internationalized host to punycode.
+func punycode(u *url.URL) error {
+	host, port := u.Hostname(), u.Port()
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	ascii, err := idna.ToASCII(host)
+	if err != nil {
+		return fmt.Errorf("failed to convert host to punycode: %v", err)
+	}
+	if len(port) != 0 {
+		ascii += ":" + port
+	}
+	u.Host = ascii
+	return nil
+}
+
+// sortQuery sorts the query parameters by name, the values of the same parameter
+// keep their order. The parameters are sorted as they are written, so the query
+// that does not parse as the form values is kept.
+func sortQuery(u *url.URL) error {
+	if len(u.RawQuery) == 0 {
+		u.ForceQuery = false
+		return nil
+	}
+
+	params := strings.Split(u.RawQuery, "&")
+	sort.SliceStable(params, func(i, j int) bool {
+		return queryName(params[i]) < queryName(params[j])
+	})
+	u.RawQuery = strings.Join(params, "&")
+	return nil
+}
+
+// queryName returns the unescaped name of the query parameter,
+// the name that fails to unescape is returned as it is.
+func queryName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+// stripTracking drops the tracking query parameters, the others keep their order.
+func (c *canonicalizer) stripTracking(u *url.URL) error {
+	if len(u.RawQuery) == 0 {
+		return nil
+	}
+
+	params := strings.Split(u.RawQuery, "&")
+	kept := params[:0]
+	for _, param := range params {
+		if !c.tracked(queryName(param)) {
+			kept = append(kept, param)
+		}
+	}
+	u.RawQuery = strings.Join(kept, "&")
+	return nil
+}
+
+// tracked checks whether the query parameter is a tracking one.
+func (c *canonicalizer) tracked(name string) bool {
+	for _, p := range c.tracking {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			continue
+		}
+		if name == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package shorturl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizer(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CanonicalConfig
+		wantErr bool
+	}{
+		{
+			name: "Default steps",
+			cfg:  CanonicalConfig{Steps: defaultCanonicalSteps},
+		},
+		{
+			name: "Strip tracking",
+			cfg:  CanonicalConfig{Steps: []string{CanonicalStripTracking}, TrackingParams: []string{"ref"}},
+		},
+		{
+			name:    "Unknown step",
+			cfg:     CanonicalConfig{Steps: []string{CanonicalLowercase, "fragment"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Canonicalizer(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, c.steps, len(tt.cfg.Steps))
+		})
+	}
+}
+
+func TestCanonicalizer_Canonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []string
+		raw   string
+		want  string
+	}{
+		{
+			name: "Lowercase scheme and host",
+			raw:  "HTTP://Example.COM/Path",
+			want: "http://example.com/Path",
+		},
+		{
+			name: "Drop default ports",
+			raw:  "http://example.com:80/a",
+			want: "http://example.com/a",
+		},
+		{
+			name: "Drop default https port",
+			raw:  "https://example.com:443/a",
+			want: "https://example.com/a",
+		},
+		{
+			name: "Keep other ports",
+			raw:  "http://example.com:8080/a",
+			want: "http://example.com:8080/a",
+		},
+		{
+			name: "Resolve dot-segments",
+			raw:  "http://example.com/a/./b/../c",
+			want: "http://example.com/a/c",
+		},
+		{
+			name: "Dot-segments above root",
+			raw:  "http://example.com/../../a",
+			want: "http://example.com/a",
+		},
+		{
+			name: "Trailing dot-segment",
+			raw:  "http://example.com/a/b/..",
+			want: "http://example.com/a/",
+		},
+		{
+			name: "Escaped slash is kept",
+			raw:  "http://example.com/a%2Fb/./c",
+			want: "http://example.com/a%2Fb/c",
+		},
+		{
+			name: "IDN to punycode",
+			raw:  "http://bücher.example/a",
+			want: "http://xn--bcher-kva.example/a",
+		},
+		{
+			name: "IDN with port",
+			raw:  "http://Bücher.example:8080/a",
+			want: "http://xn--bcher-kva.example:8080/a",
+		},
+		{
+			name: "IP address",
+			raw:  "http://[::1]:80/a",
+			want: "http://[::1]/a",
+		},
+		{
+			name: "Sort query",
+			raw:  "http://example.com/a?b=2&a=1&b=1",
+			want: "http://example.com/a?a=1&b=2&b=1",
+		},
+		{
+			name: "Sort query with semicolons",
+			raw:  "http://example.com/?b=2;c=3&a=1;b=2",
+			want: "http://example.com/?a=1;b=2&b=2;c=3",
+		},
+		{
+			name: "Query with invalid escape",
+			raw:  "http://example.com/?q=100%",
+			want: "http://example.com/?q=100%",
+		},
+		{
+			name: "Query with semicolon separator",
+			raw:  "http://example.com/?a=1;b=2",
+			want: "http://example.com/?a=1;b=2",
+		},
+		{
+			name: "Encoding is kept",
+			raw:  "http://example.com/a?b=x+y&a=%7e",
+			want: "http://example.com/a?a=%7e&b=x+y",
+		},
+		{
+			name: "Empty query",
+			raw:  "http://example.com/a?",
+			want: "http://example.com/a",
+		},
+		{
+			name: "Tracking is kept by default",
+			raw:  "http://example.com/a?utm_source=x",
+			want: "http://example.com/a?utm_source=x",
+		},
+		{
+			name:  "Strip tracking",
+			steps: append(defaultCanonicalSteps, CanonicalStripTracking),
+			raw:   "HTTP://Example.com:80/a/../b?utm_source=x&id=1&gclid=2",
+			want:  "http://example.com/b?id=1",
+		},
+		{
+			name:  "Strip tracking only",
+			steps: []string{CanonicalStripTracking},
+			raw:   "http://example.com/a?z=1&utm_medium=x&a=2",
+			want:  "http://example.com/a?z=1&a=2",
+		},
+		{
+			name:  "No steps but strip tracking",
+			steps: []string{CanonicalStripTracking},
+			raw:   "HTTP://Example.com:80/a/../b",
+			want:  "http://Example.com:80/a/../b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := tt.steps
+			if steps == nil {
+				steps = defaultCanonicalSteps
+			}
+			c, err := Canonicalizer(CanonicalConfig{Steps: steps})
+			require.NoError(t, err)
+
+			got, err := c.Canonical(tt.raw)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package shorturl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+)
+
+// urlEditor defines the editor of the shortened URL destinations.
+type urlEditor interface {
+	Edit(context.Context, models.URLChange) (models.URLChange, error)
+	History(context.Context, string, string) ([]models.URLChange, error)
+}
+
+// URL editing errors.
+var (
+	ErrURLNotFound        = errors.New("shortened URL not found")
+	ErrNotOwner           = errors.New("shortened URL of another user")
+	ErrInvalidDestination = errors.New("invalid destination")
+	ErrUnknownVersion     = errors.New("unknown version")
+	ErrVersionConflict    = errors.New("shortened URL has been changed concurrently")
+)
+
+// editor changes the destinations of the shortened URLs by their owners.
+// Every change is kept in the history of the URL.
+type editor struct {
+	editor  urlEditor
+	canon   urlCanonicalizer
+	policy  urlPolicy
+	baseURL string
+}
+
+// Editor returns a new editor in front of the storage editor. The new destinations
+// are validated, checked against the policy and canonicalized the way the shortener does it.
+// The destinations are checked by the default rules if the policy is nil.
+func Editor(baseURL string, e urlEditor, canon urlCanonicalizer, policy urlPolicy) (*editor, error) {
+	if e == nil {
+		return nil, fmt.Errorf("url editor is nil")
+	}
+	if canon == nil {
+		return nil, fmt.Errorf("url canonicalizer is nil")
+	}
+
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		p, err := Policy(PolicyRules{}, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		policy = p
+	}
+
+	return &editor{
+		editor:  e,
+		canon:   canon,
+		policy:  policy,
+		baseURL: baseURL,
+	}, nil
+}
+
+// editErr maps the storage errors to the editing errors.
+func editErr(err error) error {
+	switch {
+	case errors.Is(err, shortenedurl.ErrNotFound):
+		return ErrURLNotFound
+	case errors.Is(err, shortenedurl.ErrNotOwner):
+		return ErrNotOwner
+	case errors.Is(err, shortenedurl.ErrUniqueViolation):
+		return ErrUniqueViolation
+	case errors.Is(err, shortenedurl.ErrVersionConflict):
+		return ErrVersionConflict
+	}
+	return err
+}
+
+// Edit changes the destination of the user's URL by slug and returns the change.
+// ErrURLNotFound is returned for the URL that is not found or has been deleted,
+// ErrNotOwner for the URL of another user. The rejected destination is reported
+// as ErrRejected wrapping the *Rejection, the destination of another URL
+// as ErrUniqueViolation.
+func (e *editor) Edit(ctx context.Context, userID, slug, raw string) (models.URLChange, error) {
+	return e.edit(ctx, userID, slug, raw, 0)
+}
+
+// edit changes the destination of the user's URL by slug to the version if it is set.
+func (e *editor) edit(ctx context.Context, userID, slug, raw string, version int) (models.URLChange, error) {
+	if err := validateURL(userID, raw, e.baseURL); err != nil {
+		return models.URLChange{}, ErrInvalidDestination
+	}
+	if err := e.policy.Check(raw); err != nil {
+		return models.URLChange{}, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	canonical, err := e.canon.Canonical(raw)
+	if err != nil {
+		return models.URLChange{}, ErrInvalidDestination
+	}
+
+	change, err := e.editor.Edit(ctx, models.URLChange{
+		Slug:      slug,
+		Version:   version,
+		New:       raw,
+		Canonical: canonical,
+		UserID:    userID,
+		ChangedAt: time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		return models.URLChange{}, editErr(err)
+	}
+	return change, nil
+}
+
+// History returns the destination changes of the user's URL by slug in the version order.
+// ErrURLNotFound is returned for the URL that is not found or has been deleted,
+// ErrNotOwner for the URL of another user.
+func (e *editor) History(ctx context.Context, userID, slug string) ([]models.URLChange, error) {
+	history, err := e.editor.History(ctx, userID, slug)
+	if err != nil {
+		return nil, editErr(err)
+	}
+	return history, nil
+}
+
+// Rollback changes the destination of the user's URL by slug back to the one of the version,
+// the rollback is kept in the history as a new change. ErrUnknownVersion is returned
+// if the URL has no such version, the URL that has never been edited has none.
+// ErrVersionConflict is returned if the URL is changed after its history is read.
+func (e *editor) Rollback(ctx context.Context, userID, slug string, version int) (models.URLChange, error) {
+	history, err := e.History(ctx, userID, slug)
+	if err != nil {
+		return models.URLChange{}, err
+	}
+
+	raw, ok := destination(history, version)
+	if !ok {
+		return models.URLChange{}, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return e.edit(ctx, userID, slug, raw, history[len(history)-1].Version+1)
+}
+
+// destination returns the destination of the version by the changes of the URL.
+func destination(history []models.URLChange, version int) (string, bool) {
+	if version == 1 && len(history) != 0 {
+		return history[0].Old, true
+	}
+	for _, c := range history {
+		if c.Version == version {
+			return c.New, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,198 @@
+package shorturl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type urlEditorMock struct {
+	EditFn    func(context.Context, models.URLChange) (models.URLChange, error)
+	HistoryFn func(context.Context, string, string) ([]models.URLChange, error)
+}
+
+func (m *urlEditorMock) Edit(ctx context.Context, c models.URLChange) (models.URLChange, error) {
+	if m != nil && m.EditFn != nil {
+		return m.EditFn(ctx, c)
+	}
+	return models.URLChange{}, fmt.Errorf("unable to edit")
+}
+
+func (m *urlEditorMock) History(ctx context.Context, userID, slug string) ([]models.URLChange, error) {
+	if m != nil && m.HistoryFn != nil {
+		return m.HistoryFn(ctx, userID, slug)
+	}
+	return nil, fmt.Errorf("unable to get history")
+}
+
+func TestEditor_Edit(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		err   error
+		want  models.URLChange
+		wantE error
+	}{
+		{
+			name: "Destination is changed",
+			raw:  "HTTP://Example.com/b",
+			want: models.URLChange{
+				Slug:      "slug",
+				Version:   2,
+				Old:       "http://example.com/a",
+				New:       "HTTP://Example.com/b",
+				Canonical: "http://example.com/b",
+				UserID:    "user",
+			},
+		},
+		{
+			name:  "Invalid destination",
+			raw:   "example",
+			wantE: ErrInvalidDestination,
+		},
+		{
+			name:  "Shortened destination",
+			raw:   "http://localhost:8080/slug",
+			wantE: ErrInvalidDestination,
+		},
+		{
+			name:  "Rejected destination",
+			raw:   "ftp://example.com/b",
+			wantE: ErrRejected,
+		},
+		{
+			name:  "URL not found",
+			raw:   "http://example.com/b",
+			err:   shortenedurl.ErrNotFound,
+			wantE: ErrURLNotFound,
+		},
+		{
+			name:  "URL of another user",
+			raw:   "http://example.com/b",
+			err:   shortenedurl.ErrNotOwner,
+			wantE: ErrNotOwner,
+		},
+		{
+			name:  "Destination of another URL",
+			raw:   "http://example.com/b",
+			err:   shortenedurl.ErrUniqueViolation,
+			wantE: ErrUniqueViolation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Editor("http://localhost:8080", &urlEditorMock{
+				EditFn: func(_ context.Context, c models.URLChange) (models.URLChange, error) {
+					if tt.err != nil {
+						return models.URLChange{}, tt.err
+					}
+					assert.False(t, c.ChangedAt.IsZero())
+					c.ChangedAt = tt.want.ChangedAt
+					c.Old, c.Version = "http://example.com/a", 2
+					return c, nil
+				},
+			}, testCanon, testPolicy)
+			require.NoError(t, err)
+
+			got, err := e.Edit(context.TODO(), "user", "slug", tt.raw)
+			if tt.wantE != nil {
+				assert.True(t, errors.Is(err, tt.wantE), "want %v, got %v", tt.wantE, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Editor("http://localhost:8080", nil, testCanon, testPolicy)
+	assert.Error(t, err)
+	_, err = Editor("http://localhost:8080", &urlEditorMock{}, nil, testPolicy)
+	assert.Error(t, err)
+}
+
+func TestEditor_Rollback(t *testing.T) {
+	history := []models.URLChange{
+		{Slug: "slug", Version: 2, Old: "http://example.com/1", New: "http://example.com/2"},
+		{Slug: "slug", Version: 3, Old: "http://example.com/2", New: "http://example.com/3"},
+	}
+
+	tests := []struct {
+		name    string
+		history []models.URLChange
+		err     error
+		editErr error
+		version int
+		want    string
+		wantE   error
+	}{
+		{
+			name:    "Rollback to the original destination",
+			history: history,
+			version: 1,
+			want:    "http://example.com/1",
+		},
+		{
+			name:    "Rollback to the edited destination",
+			history: history,
+			version: 2,
+			want:    "http://example.com/2",
+		},
+		{
+			name:    "Unknown version",
+			history: history,
+			version: 4,
+			wantE:   ErrUnknownVersion,
+		},
+		{
+			name:    "URL has never been edited",
+			version: 1,
+			wantE:   ErrUnknownVersion,
+		},
+		{
+			name:    "URL of another user",
+			err:     shortenedurl.ErrNotOwner,
+			version: 1,
+			wantE:   ErrNotOwner,
+		},
+		{
+			name:    "URL changed after the history is read",
+			history: history,
+			editErr: shortenedurl.ErrVersionConflict,
+			version: 1,
+			wantE:   ErrVersionConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Editor("http://localhost:8080", &urlEditorMock{
+				HistoryFn: func(_ context.Context, userID, slug string) ([]models.URLChange, error) {
+					assert.Equal(t, "user", userID)
+					assert.Equal(t, "slug", slug)
+					return tt.history, tt.err
+				},
+				EditFn: func(_ context.Context, c models.URLChange) (models.URLChange, error) {
+					assert.Equal(t, 4, c.Version, "the change of the read version is expected")
+					return c, tt.editErr
+				},
+			}, testCanon, testPolicy)
+			require.NoError(t, err)
+
+			got, err := e.Rollback(context.TODO(), "user", "slug", tt.version)
+			if tt.wantE != nil {
+				assert.True(t, errors.Is(err, tt.wantE), "want %v, got %v", tt.wantE, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.New)
+			assert.Equal(t, 4, got.Version)
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package shorturl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	"golang.org/x/net/idna"
+)
+
+// Rejection reasons.
+const (
+	RejectedInvalid = "invalid_url"
+	RejectedScheme  = "scheme_not_allowed"
+	RejectedHost    = "host_denied"
+	RejectedAddress = "address_denied"
+	RejectedSelf    = "self_link"
+	RejectedThreat  = "threat_blocked"
+)
+
+// Rejection is the error of the URL rejected by the policy.
+type Rejection struct {
+	// Reason is one of the rejection reasons.
+	Reason string
+	// Detail is the rejected part of the URL.
+	Detail string
+}
+
+// Error returns the rejection as a string.
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("%s: %s", r.Reason, r.Detail)
+}
+
+// PolicyRules represents the destination safety rules.
+type PolicyRules struct {
+	// AllowedSchemes are the allowed URL schemes, http and https if empty.
+	AllowedSchemes []string `json:"allowed_schemes"`
+
+	// DeniedHosts are the denied hosts. The host starting with "*." matches its subdomains.
+	DeniedHosts []string `json:"denied_hosts"`
+
+	// DeniedCIDRs are the denied networks of the IP address hosts in addition to
+	// the loopback, private and link-local networks denied by default.
+	DeniedCIDRs []string `json:"denied_cidrs"`
+
+	// AllowPrivate allows the links to the networks denied by default.
+	AllowPrivate bool `json:"allow_private"`
+
+	// SelfDomains are our own domains besides the base URL host, the same way as DeniedHosts.
+	// The links to them are rejected, since they create redirect loops.
+	SelfDomains []string `json:"self_domains"`
+
+	// AllowSelf allows the links to our own domains.
+	AllowSelf bool `json:"allow_self"`
+}
+
+// PolicyConfig represents the destination safety policy configuration.
+type PolicyConfig struct {
+	// Path is the path of the JSON file with PolicyRules.
+	// The default rules are used if it is empty.
+	Path string `env:"URL_POLICY_PATH" envDefault:""`
+}
+
+// Empty checks on being empty.
+func (c PolicyConfig) Empty() bool {
+	return len(c.Path) == 0
+}
+
+// defaultSchemes are the allowed schemes if none are configured.
+var defaultSchemes = []string{"http", "https"}
+
+// defaultDeniedCIDRs are the loopback, private and link-local networks.
+// The host names are not resolved, so only the IP address hosts are matched.
+var defaultDeniedCIDRs = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// policy is the destination safety policy of the shortened URLs.
+type policy struct {
+	schemes   map[string]bool
+	hosts     hostMatcher
+	cidrs     []*net.IPNet
+	self      hostMatcher
+	allowSelf bool
+}
+
+// LoadPolicy returns a new policy with the rules from the configured file.
+// The base URL host is one of our own domains.
+func LoadPolicy(cfg PolicyConfig, baseURL string) (*policy, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+
+	var rules PolicyRules
+	if len(cfg.Path) != 0 {
+		b, err := os.ReadFile(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy file: %v", err)
+		}
+		if err = json.Unmarshal(b, &rules); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal policy file: %v", err)
+		}
+	}
+
+	return Policy(rules, baseURL)
+}
+
+// Policy returns a new policy with the rules. The base URL host is one of our own domains.
+func Policy(rules PolicyRules, baseURL string) (*policy, error) {
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL: %v", err)
+	}
+
+	if len(rules.AllowedSchemes) == 0 {
+		rules.AllowedSchemes = defaultSchemes
+	}
+	p := &policy{
+		schemes:   make(map[string]bool, len(rules.AllowedSchemes)),
+		hosts:     newHostMatcher(rules.DeniedHosts),
+		self:      newHostMatcher(append([]string{base.Hostname()}, rules.SelfDomains...)),
+		allowSelf: rules.AllowSelf,
+	}
+	for _, scheme := range rules.AllowedSchemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+	cidrs := rules.DeniedCIDRs
+	if !rules.AllowPrivate {
+		cidrs = append(defaultDeniedCIDRs[:len(defaultDeniedCIDRs):len(defaultDeniedCIDRs)], cidrs...)
+	}
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denied CIDR %v: %v", cidr, err)
+		}
+		p.cidrs = append(p.cidrs, network)
+	}
+
+	return p, nil
+}
+
+// Check checks the raw URL against the policy. The rejected URL is reported as *Rejection.
+func (p *policy) Check(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return &Rejection{Reason: RejectedInvalid, Detail: "failed to parse URL"}
+	}
+
+	if scheme := strings.ToLower(u.Scheme); !p.schemes[scheme] {
+		return &Rejection{Reason: RejectedScheme, Detail: scheme}
+	}
+
+	host := normalizeHost(u.Hostname())
+	if len(host) == 0 {
+		return &Rejection{Reason: RejectedInvalid, Detail: "empty host"}
+	}
+	ip, numeric := parseIPv4(host)
+	if numeric && ip == nil {
+		return &Rejection{Reason: RejectedInvalid, Detail: "invalid address"}
+	}
+	if ip == nil {
+		ip = net.ParseIP(host)
+	}
+	if ip != nil {
+		for _, network := range p.cidrs {
+			if network.Contains(ip) {
+				return &Rejection{Reason: RejectedAddress, Detail: host}
+			}
+		}
+	}
+	if p.hosts.match(host) {
+		return &Rejection{Reason: RejectedHost, Detail: host}
+	}
+	if !p.allowSelf && p.self.match(host) {
+		return &Rejection{Reason: RejectedSelf, Detail: host}
+	}
+
+	return nil
+}
+
+// policies is the chain of the policies, the first rejection wins.
+type policies []urlPolicy
+
+// Policies returns a new chain of the policies checked in order.
+func Policies(ps ...urlPolicy) policies {
+	return ps
+}
+
+// Check checks the raw URL against each policy of the chain.
+func (ps policies) Check(raw string) error {
+	for _, p := range ps {
+		if err := p.Check(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hostMatcher matches the hosts exactly or by the "*." suffix wildcards.
+type hostMatcher struct {
+	exact    map[string]bool
+	suffixes []string
+}
+
+// newHostMatcher returns a new hostMatcher of the hosts.
+func newHostMatcher(hosts []string) hostMatcher {
+	m := hostMatcher{
+		exact: make(map[string]bool, len(hosts)),
+	}
+	for _, h := range hosts {
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			m.suffixes = append(m.suffixes, "."+normalizeHost(suffix))
+			continue
+		}
+		if h = normalizeHost(h); len(h) != 0 {
+			m.exact[h] = true
+		}
+	}
+	return m
+}
+
+// match checks whether the normalized host matches.
+func (m hostMatcher) match(host string) bool {
+	if m.exact[host] {
+		return true
+	}
+	for _, suffix := range m.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPv4 parses the host as the IPv4 address in any of the forms the resolvers accept:
+// one to four dot separated decimal, octal or hexadecimal parts, the last part fills
+// the remaining bytes, e.g. 0300.0250.1.1 or 0xC0A80101 is 192.168.1.1.
+// numeric reports whether the host is such an address, the ip is nil if it is out of range.
+func parseIPv4(host string) (ip net.IP, numeric bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil, false
+	}
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		base := 10
+		switch {
+		case len(part) > 2 && (part[:2] == "0x" || part[:2] == "0X"):
+			part, base = part[2:], 16
+		case len(part) > 1 && part[0] == '0':
+			part, base = part[1:], 8
+		}
+		if len(part) == 0 {
+			return nil, false
+		}
+		v, err := strconv.ParseUint(part, base, 64)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return nil, true
+			}
+			return nil, false
+		}
+		values[i] = v
+	}
+
+	var addr uint64
+	last := len(values) - 1
+	for _, v := range values[:last] {
+		if v > 0xff {
+			return nil, true
+		}
+		addr = addr<<8 | v
+	}
+	rest := uint(4-last) * 8
+	if values[last] >= 1<<rest {
+		return nil, true
+	}
+	addr = addr<<rest | values[last]
+
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr)), true
+}
+
+// normalizeHost lowercases the host, drops the trailing dot and converts it to punycode.
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if ascii, err := idna.ToASCII(host); err == nil {
+		host = ascii
+	}
+	return host
+}
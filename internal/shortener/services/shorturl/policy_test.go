@@ -0,0 +1,302 @@
+package shorturl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	rules := PolicyRules{
+		DeniedHosts: []string{"evil.com", "*.malware.example", "Bücher.example"},
+		DeniedCIDRs: []string{"10.0.0.0/8", "127.0.0.0/8", "::1/128"},
+		SelfDomains: []string{"short.ly", "*.short.ly"},
+	}
+
+	tests := []struct {
+		name   string
+		rules  PolicyRules
+		raw    string
+		reason string
+	}{
+		{
+			name: "Allowed URL",
+			raw:  "https://demo.com/path?q=1",
+		},
+		{
+			name:   "Scheme is not allowed",
+			raw:    "javascript://demo.com/alert",
+			reason: RejectedScheme,
+		},
+		{
+			name:   "Scheme is not allowed by the custom rules",
+			rules:  PolicyRules{AllowedSchemes: []string{"https"}},
+			raw:    "http://demo.com",
+			reason: RejectedScheme,
+		},
+		{
+			name:  "Scheme is allowed by the custom rules",
+			rules: PolicyRules{AllowedSchemes: []string{"FTP"}},
+			raw:   "ftp://demo.com/file",
+		},
+		{
+			name:   "Host is denied",
+			raw:    "http://EVIL.com./path",
+			reason: RejectedHost,
+		},
+		{
+			name: "Subdomain of the exact host is allowed",
+			raw:  "http://www.evil.com",
+		},
+		{
+			name:   "Subdomain is denied by wildcard",
+			raw:    "http://a.b.malware.example",
+			reason: RejectedHost,
+		},
+		{
+			name: "Wildcard does not match the domain itself",
+			raw:  "http://malware.example",
+		},
+		{
+			name:   "Internationalized host is denied",
+			raw:    "http://xn--bcher-kva.example",
+			reason: RejectedHost,
+		},
+		{
+			name:   "Unicode host is denied",
+			raw:    "http://BÜCHER.example",
+			reason: RejectedHost,
+		},
+		{
+			name:   "Address is denied",
+			raw:    "http://10.1.2.3:8080/admin",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "IPv6 address is denied",
+			raw:    "http://[::1]/admin",
+			reason: RejectedAddress,
+		},
+		{
+			name: "Address is allowed",
+			raw:  "http://93.184.216.34",
+		},
+		{
+			name:   "Private address is denied by default",
+			raw:    "http://192.168.1.1",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "Link-local address is denied by default",
+			raw:    "http://169.254.169.254/latest/meta-data",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "IPv4-mapped IPv6 address is denied",
+			raw:    "http://[::ffff:127.0.0.1]/admin",
+			reason: RejectedAddress,
+		},
+		{
+			name:  "Private address is allowed by the custom rules",
+			rules: PolicyRules{AllowPrivate: true},
+			raw:   "http://192.168.1.1",
+		},
+		{
+			name:   "Octal address is denied",
+			raw:    "http://0300.0250.1.1/",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "Hexadecimal address is denied",
+			raw:    "http://0xC0A80101/",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "Decimal address is denied",
+			raw:    "http://2130706433/",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "Short address is denied",
+			raw:    "http://10.1/",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "Leading zero address is denied",
+			raw:    "http://012.0.0.1/",
+			reason: RejectedAddress,
+		},
+		{
+			name:   "Address out of range",
+			raw:    "http://0x1C0A80101/",
+			reason: RejectedInvalid,
+		},
+		{
+			name: "Numeric subdomain is allowed",
+			raw:  "http://0x7f.demo.com",
+		},
+		{
+			name:   "Base URL host is self",
+			raw:    "http://localhost:9090/slug",
+			reason: RejectedSelf,
+		},
+		{
+			name:   "Self domain",
+			raw:    "https://short.ly/slug",
+			reason: RejectedSelf,
+		},
+		{
+			name:   "Self subdomain",
+			raw:    "https://go.short.ly/slug",
+			reason: RejectedSelf,
+		},
+		{
+			name:  "Self link is allowed",
+			rules: PolicyRules{AllowSelf: true},
+			raw:   "http://localhost:8080/slug",
+		},
+		{
+			name:   "Empty host",
+			raw:    "http:///path",
+			reason: RejectedInvalid,
+		},
+		{
+			name:   "Invalid URL",
+			raw:    "http://demo.com/%zz",
+			reason: RejectedInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rules
+			if len(tt.rules.AllowedSchemes) != 0 || tt.rules.AllowSelf || tt.rules.AllowPrivate {
+				r = tt.rules
+			}
+			p, err := Policy(r, "http://localhost:8080")
+			require.NoError(t, err)
+
+			err = p.Check(tt.raw)
+			if len(tt.reason) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var rej *Rejection
+			require.ErrorAs(t, err, &rej)
+			assert.Equal(t, tt.reason, rej.Reason)
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	_, err := Policy(PolicyRules{DeniedCIDRs: []string{"10.0.0.0/33"}}, "http://localhost:8080")
+	assert.Error(t, err)
+
+	_, err = Policy(PolicyRules{}, "http://localhost:8080/%zz")
+	assert.Error(t, err)
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		cfg     PolicyConfig
+		raw     string
+		reason  string
+		wantErr bool
+	}{
+		{
+			name:   "Rules from file",
+			cfg:    PolicyConfig{Path: write("policy.json", `{"denied_hosts": ["*.evil.com"]}`)},
+			raw:    "http://www.evil.com",
+			reason: RejectedHost,
+		},
+		{
+			name:    "File does not exist",
+			cfg:     PolicyConfig{Path: filepath.Join(dir, "missing.json")},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid JSON",
+			cfg:     PolicyConfig{Path: write("invalid.json", `{"denied_hosts": "evil.com"}`)},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid CIDR",
+			cfg:     PolicyConfig{Path: write("cidr.json", `{"denied_cidrs": ["10.0.0.1"]}`)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := LoadPolicy(tt.cfg, "http://localhost:8080")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var rej *Rejection
+			require.ErrorAs(t, p.Check(tt.raw), &rej)
+			assert.Equal(t, tt.reason, rej.Reason)
+		})
+	}
+}
+
+func TestPolicies_Check(t *testing.T) {
+	base, err := Policy(PolicyRules{}, "http://localhost:8080")
+	require.NoError(t, err)
+	threats := &policyMock{
+		CheckFn: func(raw string) error {
+			if raw == "http://evil.com" || raw == "ftp://evil.com" {
+				return &Rejection{Reason: RejectedThreat, Detail: "evil.com"}
+			}
+			return nil
+		},
+	}
+	chain := Policies(base, threats)
+
+	tests := []struct {
+		name   string
+		raw    string
+		reason string
+	}{
+		{
+			name: "Allowed URL",
+			raw:  "http://demo.com",
+		},
+		{
+			name:   "Rejected by the last policy",
+			raw:    "http://evil.com",
+			reason: RejectedThreat,
+		},
+		{
+			name:   "First rejection wins",
+			raw:    "ftp://evil.com",
+			reason: RejectedScheme,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := chain.Check(tt.raw)
+			if len(tt.reason) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var rej *Rejection
+			require.ErrorAs(t, err, &rej)
+			assert.Equal(t, tt.reason, rej.Reason)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package shorturl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+)
+
+// urlProvider defines the shortened URL provider.
+type urlProvider interface {
+	GetByURL(context.Context, string, string) (models.ShortenedURL, error)
+	GetBySlug(context.Context, string) (models.ShortenedURL, error)
+	CollectByUser(context.Context, string) ([]models.ShortenedURL, error)
+}
+
+// provider is the shortened URL provider that looks the raw URLs up by their canonical form,
+// the way the shortener deduplicates them. The provided URLs with the destinations
+// on the threat blocklist are flagged.
+type provider struct {
+	urlProvider
+	canon   urlCanonicalizer
+	threats urlPolicy
+}
+
+// Provider returns a new provider in front of the storage provider.
+// Nothing is flagged if the threat blocklist is nil.
+func Provider(p urlProvider, canon urlCanonicalizer, threats urlPolicy) (*provider, error) {
+	if p == nil {
+		return nil, fmt.Errorf("url provider is nil")
+	}
+	if canon == nil {
+		return nil, fmt.Errorf("url canonicalizer is nil")
+	}
+
+	return &provider{
+		urlProvider: p,
+		canon:       canon,
+		threats:     threats,
+	}, nil
+}
+
+// GetByURL returns the shortened URL by the canonical form of the raw URL.
+// The raw URL that can not be canonicalized is looked up as is.
+func (p *provider) GetByURL(ctx context.Context, userID, raw string) (models.ShortenedURL, error) {
+	if canonical, err := p.canon.Canonical(raw); err == nil {
+		raw = canonical
+	}
+	return p.urlProvider.GetByURL(ctx, userID, raw)
+}
+
+// GetBySlug returns the shortened URL by the slug, it is flagged if the destination is blocked.
+func (p *provider) GetBySlug(ctx context.Context, slug string) (models.ShortenedURL, error) {
+	url, err := p.urlProvider.GetBySlug(ctx, slug)
+	if err != nil {
+		return models.ShortenedURL{}, err
+	}
+	p.flag(&url)
+	return url, nil
+}
+
+// CollectByUser returns the user's shortened URLs, they are flagged if the destinations are blocked.
+func (p *provider) CollectByUser(ctx context.Context, userID string) ([]models.ShortenedURL, error) {
+	urls, err := p.urlProvider.CollectByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range urls {
+		p.flag(&urls[i])
+	}
+	return urls, nil
+}
+
+// flag sets the blocklist entry the destination matches.
+func (p *provider) flag(url *models.ShortenedURL) {
+	if p.threats == nil || url.Empty() {
+		return
+	}
+
+	var rej *Rejection
+	if err := p.threats.Check(url.Raw); errors.As(err, &rej) && rej.Reason == RejectedThreat {
+		url.Threat = rej.Detail
+	}
+}
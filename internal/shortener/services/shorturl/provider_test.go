@@ -0,0 +1,155 @@
+package shorturl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type urlProviderMock struct {
+	GetByURLFn      func(context.Context, string, string) (models.ShortenedURL, error)
+	GetBySlugFn     func(context.Context, string) (models.ShortenedURL, error)
+	CollectByUserFn func(context.Context, string) ([]models.ShortenedURL, error)
+}
+
+func (m *urlProviderMock) GetByURL(ctx context.Context, userID, url string) (models.ShortenedURL, error) {
+	if m != nil && m.GetByURLFn != nil {
+		return m.GetByURLFn(ctx, userID, url)
+	}
+	return models.ShortenedURL{}, fmt.Errorf("unable to get")
+}
+
+func (m *urlProviderMock) GetBySlug(ctx context.Context, slug string) (models.ShortenedURL, error) {
+	if m != nil && m.GetBySlugFn != nil {
+		return m.GetBySlugFn(ctx, slug)
+	}
+	return models.ShortenedURL{}, fmt.Errorf("unable to get")
+}
+
+func (m *urlProviderMock) CollectByUser(ctx context.Context, userID string) ([]models.ShortenedURL, error) {
+	if m != nil && m.CollectByUserFn != nil {
+		return m.CollectByUserFn(ctx, userID)
+	}
+	return nil, fmt.Errorf("unable to collect")
+}
+
+func TestProvider_GetByURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "Canonical form is looked up",
+			raw:  "HTTP://Example.com:80/a/../b",
+			want: "http://example.com/b",
+		},
+		{
+			name: "Invalid URL is looked up as is",
+			raw:  "http://example.com/%zz",
+			want: "http://example.com/%zz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Provider(&urlProviderMock{
+				GetByURLFn: func(_ context.Context, userID, url string) (models.ShortenedURL, error) {
+					assert.Equal(t, "1", userID)
+					assert.Equal(t, tt.want, url)
+					return models.ShortenedURL{Slug: "slug1"}, nil
+				},
+			}, testCanon, nil)
+			require.NoError(t, err)
+
+			got, err := p.GetByURL(context.TODO(), "1", tt.raw)
+			require.NoError(t, err)
+			assert.Equal(t, "slug1", got.Slug)
+		})
+	}
+}
+
+type policyMock struct {
+	CheckFn func(string) error
+}
+
+func (m *policyMock) Check(raw string) error {
+	if m != nil && m.CheckFn != nil {
+		return m.CheckFn(raw)
+	}
+	return nil
+}
+
+func TestProvider_Flag(t *testing.T) {
+	threats := &policyMock{
+		CheckFn: func(raw string) error {
+			switch raw {
+			case "http://evil.com":
+				return &Rejection{Reason: RejectedThreat, Detail: "evil.com"}
+			case "ftp://demo.com":
+				// Only the threats flag the URLs.
+				return &Rejection{Reason: RejectedScheme, Detail: "ftp"}
+			}
+			return nil
+		},
+	}
+	urls := map[string]models.ShortenedURL{
+		"slug1": models.NewShortenedURL("1", "1", "http://demo.com", "slug1", "http://localhost:8080/slug1"),
+		"slug2": models.NewShortenedURL("1", "2", "http://evil.com", "slug2", "http://localhost:8080/slug2"),
+		"slug3": models.NewShortenedURL("1", "3", "ftp://demo.com", "slug3", "http://localhost:8080/slug3"),
+	}
+	storage := &urlProviderMock{
+		GetBySlugFn: func(_ context.Context, slug string) (models.ShortenedURL, error) {
+			return urls[slug], nil
+		},
+		CollectByUserFn: func(context.Context, string) ([]models.ShortenedURL, error) {
+			return []models.ShortenedURL{urls["slug1"], urls["slug2"], urls["slug3"]}, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		threats urlPolicy
+		want    map[string]string
+	}{
+		{
+			name:    "Blocked destination is flagged",
+			threats: threats,
+			want:    map[string]string{"slug2": "evil.com"},
+		},
+		{
+			name: "Nothing is flagged without blocklist",
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Provider(storage, testCanon, tt.threats)
+			require.NoError(t, err)
+
+			for _, slug := range []string{"slug1", "slug2", "slug3"} {
+				got, err := p.GetBySlug(context.TODO(), slug)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want[slug], got.Threat)
+				assert.Equal(t, len(tt.want[slug]) != 0, got.Flagged())
+			}
+
+			// The missing URL stays empty.
+			got, err := p.GetBySlug(context.TODO(), "slug4")
+			require.NoError(t, err)
+			assert.True(t, got.Empty())
+
+			collected, err := p.CollectByUser(context.TODO(), "1")
+			require.NoError(t, err)
+			require.Len(t, collected, 3)
+			for _, u := range collected {
+				assert.Equal(t, tt.want[u.Slug], u.Threat)
+			}
+		})
+	}
+}
@@ -1,66 +1,73 @@
 package shorturl
 
 import (
-	"crypto/rand"
-	"encoding/binary"
-	"errors"
 	"fmt"
 	"net"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 )
 
 // shortenedURL represents the shortened URL.
 type shortenedURL struct {
-	UserID    string
-	CorrID    string
-	Raw       string
-	Slug      string
-	Value     string
-	IsDeleted bool
+	UserID         string
+	CorrID         string
+	Raw            string
+	Canonical      string
+	Slug           string
+	Value          string
+	ExpiresAt      time.Time
+	ActivateAt     time.Time
+	DeactivateAt   time.Time
+	MaxVisits      int
+	PasswordHash   string
+	RedirectStatus int
+	CachePolicy    string
+	QueryMode      string
+	FixedParams    string
+	ForwardPath    bool
+	IsDeleted      bool
 }
 
-// shortenURL returns a new shortenedURL.
-func shortenURL(
-	userID string,
-	corrID string,
-	rawURI string,
-	baseURL string,
-) (shortenedURL, error) {
+// withSlug returns a copy of the shortenedURL with the slug and its short form.
+func (s shortenedURL) withSlug(baseURL, slug string) shortenedURL {
+	s.Slug = slug
+	s.Value = baseURL + "/" + slug
+	return s
+}
+
+// validateURL validates the URL to shorten.
+func validateURL(userID, rawURI, baseURL string) error {
 	if len(userID) == 0 {
-		return shortenedURL{}, fmt.Errorf("empty userID")
+		return fmt.Errorf("empty userID")
 	}
 	if len(rawURI) == 0 {
-		return shortenedURL{}, fmt.Errorf("empty URI")
+		return fmt.Errorf("empty URI")
 	}
 
 	uri, err := url.ParseRequestURI(rawURI)
 	if err != nil {
-		return shortenedURL{}, fmt.Errorf("failed to parse URI")
+		return fmt.Errorf("failed to parse URI")
 	}
 	if addr := net.ParseIP(uri.Host); addr == nil {
 		if !strings.Contains(uri.Host, ".") {
-			return shortenedURL{}, fmt.Errorf("failed to parse URI")
+			return fmt.Errorf("failed to parse URI")
 		}
 	}
 	if len(baseURL) == 0 {
-		return shortenedURL{}, fmt.Errorf("empty baseURL")
+		return fmt.Errorf("empty baseURL")
 	}
+	return nil
+}
 
-	slug, err := base62(7)
-	if err != nil {
-		return shortenedURL{}, fmt.Errorf("failed to create the slug: %v", err)
+// validateParams validates the encoded query of the fixed parameters.
+func validateParams(params string) error {
+	if _, err := url.ParseQuery(params); err != nil {
+		return fmt.Errorf("failed to parse fixed params")
 	}
-
-	return shortenedURL{
-		UserID: userID,
-		CorrID: corrID,
-		Raw:    rawURI,
-		Slug:   slug,
-		Value:  baseURL + "/" + slug,
-	}, nil
+	return nil
 }
 
 // SetDeleted sets IsDeleted as true.
@@ -73,15 +80,30 @@ func (s *shortenedURL) Empty() bool {
 	return len(s.UserID) == 0 &&
 		len(s.CorrID) == 0 &&
 		len(s.Raw) == 0 &&
+		len(s.Canonical) == 0 &&
 		len(s.Value) == 0 &&
 		len(s.Slug) == 0 &&
+		s.ExpiresAt.IsZero() &&
+		s.ActivateAt.IsZero() &&
+		s.DeactivateAt.IsZero() &&
+		s.MaxVisits == 0 &&
+		len(s.PasswordHash) == 0 &&
+		s.RedirectStatus == 0 &&
+		len(s.CachePolicy) == 0 &&
+		len(s.QueryMode) == 0 &&
+		len(s.FixedParams) == 0 &&
+		!s.ForwardPath &&
 		!s.IsDeleted
 }
 
 // String represents ShortURL as a string.
 func (s *shortenedURL) String() string {
-	return fmt.Sprintf("shortenedURL[userID: %s, corrID: %s, raw: %s, slug: %v, value: %s, deleted: %t]",
-		s.UserID, s.CorrID, s.Raw, s.Slug, s.Value, s.IsDeleted)
+	return fmt.Sprintf("shortenedURL[userID: %s, corrID: %s, raw: %s, canonical: %s, slug: %v, value: %s, "+
+		"expiresAt: %v, activateAt: %v, deactivateAt: %v, maxVisits: %d, protected: %t, redirectStatus: %d, "+
+		"cachePolicy: %s, queryMode: %s, fixedParams: %s, forwardPath: %t, deleted: %t]",
+		s.UserID, s.CorrID, s.Raw, s.Canonical, s.Slug, s.Value, s.ExpiresAt, s.ActivateAt, s.DeactivateAt,
+		s.MaxVisits, len(s.PasswordHash) != 0, s.RedirectStatus, s.CachePolicy,
+		s.QueryMode, s.FixedParams, s.ForwardPath, s.IsDeleted)
 }
 
 // Equals compares ShortURL.
@@ -89,20 +111,42 @@ func (s *shortenedURL) Equals(s1 shortenedURL) bool {
 	return s.UserID == s1.UserID &&
 		s.CorrID == s1.CorrID &&
 		s.Raw == s1.Raw &&
+		s.Canonical == s1.Canonical &&
 		s.Value == s1.Value &&
 		s.Slug == s1.Slug &&
+		s.ExpiresAt.Equal(s1.ExpiresAt) &&
+		s.ActivateAt.Equal(s1.ActivateAt) &&
+		s.DeactivateAt.Equal(s1.DeactivateAt) &&
+		s.MaxVisits == s1.MaxVisits &&
+		s.PasswordHash == s1.PasswordHash &&
+		s.RedirectStatus == s1.RedirectStatus &&
+		s.CachePolicy == s1.CachePolicy &&
+		s.QueryMode == s1.QueryMode &&
+		s.FixedParams == s1.FixedParams &&
+		s.ForwardPath == s1.ForwardPath &&
 		s.IsDeleted == s1.IsDeleted
 }
 
 // ToModel converts shortenedURL to models.ShortenedURL.
 func (s *shortenedURL) ToModel() models.ShortenedURL {
 	return models.ShortenedURL{
-		UserID:    s.UserID,
-		CorrID:    s.CorrID,
-		Raw:       s.Raw,
-		Slug:      s.Slug,
-		Value:     s.Value,
-		IsDeleted: s.IsDeleted,
+		UserID:         s.UserID,
+		CorrID:         s.CorrID,
+		Raw:            s.Raw,
+		Canonical:      s.Canonical,
+		Slug:           s.Slug,
+		Value:          s.Value,
+		ExpiresAt:      s.ExpiresAt,
+		ActivateAt:     s.ActivateAt,
+		DeactivateAt:   s.DeactivateAt,
+		MaxVisits:      s.MaxVisits,
+		PasswordHash:   s.PasswordHash,
+		RedirectStatus: s.RedirectStatus,
+		CachePolicy:    s.CachePolicy,
+		QueryMode:      s.QueryMode,
+		FixedParams:    s.FixedParams,
+		ForwardPath:    s.ForwardPath,
+		IsDeleted:      s.IsDeleted,
 	}
 }
 
@@ -118,32 +162,3 @@ func (s shortenedURLs) ToModel() []models.ShortenedURL {
 
 	return tmp
 }
-
-const (
-	base62Alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	base62Len      = uint64(len(base62Alphabet))
-)
-
-// base62 returns base62 encoded strings.
-func base62(size int) (string, error) {
-	if size == 0 {
-		return "", fmt.Errorf("zero size")
-	}
-
-	var sb strings.Builder
-	sb.Grow(size)
-
-	randomBytes := make([]byte, 8)
-	_, err := rand.Read(randomBytes)
-	if err != nil {
-		return "", errors.New("failed to generate random uint64")
-	}
-
-	base := binary.LittleEndian.Uint64(randomBytes[:])
-	for i := 0; i < size; i++ {
-		base = base / base62Len
-		sb.WriteByte(base62Alphabet[(base % base62Len)])
-	}
-
-	return sb.String(), nil
-}
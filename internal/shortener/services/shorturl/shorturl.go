@@ -5,7 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 	"github.com/caarlos0/env/v6"
@@ -19,58 +23,328 @@ type config struct {
 // urlSaver defines a urlSaver for the shortened URL.
 type urlSaver interface {
 	Save(context.Context, models.ShortenedURL) error
-	Batch(context.Context, []models.ShortenedURL) error
+	Batch(context.Context, []models.ShortenedURL) ([]models.BatchedURL, error)
 }
 
+// urlCanonicalizer defines the canonicalizer of the raw URLs.
+type urlCanonicalizer interface {
+	Canonical(string) (string, error)
+}
+
+// urlPolicy defines the destination safety policy.
+type urlPolicy interface {
+	Check(string) error
+}
+
+// maxSlugAttempts bounds the attempts to save the URL with a new slug after slug collisions.
+const maxSlugAttempts = 5
+
 // shortener is a representation of the url shortener.
 type shortener struct {
 	saver   urlSaver
+	slugs   SlugGenerator
+	canon   urlCanonicalizer
+	policy  urlPolicy
 	baseURL string
+
+	collisions atomic.Uint64
+	exhausted  atomic.Uint64
 }
 
-// NewShortener returns a new shortener.
-func Shortener(baseURL string, saver urlSaver) (*shortener, error) {
+// SlugStats represents the slug collision counters.
+type SlugStats struct {
+	// Collisions is the number of the slug collisions.
+	Collisions uint64
+	// Exhausted is the number of the requests failed after maxSlugAttempts collisions.
+	Exhausted uint64
+}
+
+// NewShortener returns a new shortener. The slugs are random base62 of the default length
+// if the slug generator is nil. The URLs are canonicalized by the default steps
+// if the canonicalizer is nil, and checked by the default rules if the policy is nil.
+func Shortener(
+	baseURL string,
+	saver urlSaver,
+	slugs SlugGenerator,
+	canon urlCanonicalizer,
+	policy urlPolicy,
+) (*shortener, error) {
 	if saver == nil {
 		return nil, fmt.Errorf("url saver is nil")
 	}
+	if slugs == nil {
+		random, err := RandomSlugs(defaultSlugLength, Base62Alphabet)
+		if err != nil {
+			return nil, err
+		}
+		slugs = random
+	}
+	if canon == nil {
+		c, err := Canonicalizer(CanonicalConfig{Steps: defaultCanonicalSteps})
+		if err != nil {
+			return nil, err
+		}
+		canon = c
+	}
 
-	if len(baseURL) == 0 {
-		var cfg config
-		opts := env.Options{RequiredIfNoDef: true}
-		err := env.Parse(&cfg, opts)
+	baseURL, err := resolveBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		p, err := Policy(PolicyRules{}, baseURL)
 		if err != nil {
-			return nil, fmt.Errorf("failed to set config")
+			return nil, err
 		}
-		baseURL = cfg.BaseURL
+		policy = p
 	}
 
 	return &shortener{
 		baseURL: baseURL,
 		saver:   saver,
+		slugs:   slugs,
+		canon:   canon,
+		policy:  policy,
 	}, nil
 }
 
+// resolveBaseURL returns the base URL, it is read from env if empty.
+func resolveBaseURL(baseURL string) (string, error) {
+	if len(baseURL) != 0 {
+		return baseURL, nil
+	}
+
+	var cfg config
+	opts := env.Options{RequiredIfNoDef: true}
+	err := env.Parse(&cfg, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to set config")
+	}
+	return cfg.BaseURL, nil
+}
+
 // URL shortening errors.
 var (
-	ErrInternal        = errors.New("internal error")
-	ErrInvalidCreation = errors.New("invalid creation")
-	ErrUniqueViolation = errors.New("unique violation")
+	ErrInternal         = errors.New("internal error")
+	ErrInvalidCreation  = errors.New("invalid creation")
+	ErrUniqueViolation  = errors.New("unique violation")
+	ErrSlugCollision    = errors.New("slug collision")
+	ErrInvalidAlias     = errors.New("invalid alias")
+	ErrAliasTaken       = errors.New("alias is taken")
+	ErrRejected         = errors.New("url rejected")
+	ErrInvalidExpiry    = errors.New("invalid expiry")
+	ErrInvalidMaxVisits = errors.New("invalid max visits")
+	ErrInvalidPassword  = errors.New("invalid password")
+	ErrInvalidWindow    = errors.New("invalid activation window")
+	ErrInvalidRedirect  = errors.New("invalid redirect")
+	ErrInvalidQuery     = errors.New("invalid query forwarding")
 )
 
-// Short creates and saves a new shortened URL.
+// SlugStats returns the slug collision counters.
+func (s *shortener) SlugStats() SlugStats {
+	return SlugStats{
+		Collisions: s.collisions.Load(),
+		Exhausted:  s.exhausted.Load(),
+	}
+}
+
+// collided counts the slug collision of the attempt. It returns false
+// if no attempts are left.
+func (s *shortener) collided(attempt int) bool {
+	logger := zerologx.Get()
+
+	s.collisions.Add(1)
+	if attempt < maxSlugAttempts {
+		logger.Warn().Int("attempt", attempt).Msg("shorturl: slug collision")
+		return true
+	}
+	s.exhausted.Add(1)
+	logger.Error().Int("attempts", attempt).Msg("shorturl: slug attempts exhausted")
+	return false
+}
+
+// validate validates the URL with its visits limit, redirect and query forwarding, then checks it
+// against the policy. The rejected URL is reported as ErrRejected wrapping the *Rejection.
+func (s *shortener) validate(url models.URL) error {
+	if err := validateURL(url.UserID, url.Raw, s.baseURL); err != nil {
+		return ErrInvalidCreation
+	}
+	if url.MaxVisits < 0 {
+		return ErrInvalidMaxVisits
+	}
+	if url.RedirectStatus != 0 && !models.ValidRedirectStatus(url.RedirectStatus) {
+		return fmt.Errorf("%w: unsupported status %d", ErrInvalidRedirect, url.RedirectStatus)
+	}
+	if len(url.CachePolicy) != 0 && !models.ValidCachePolicy(url.CachePolicy) {
+		return fmt.Errorf("%w: unknown cache policy %q", ErrInvalidRedirect, url.CachePolicy)
+	}
+	if len(url.QueryMode) != 0 && !models.ValidQueryMode(url.QueryMode) {
+		return fmt.Errorf("%w: unknown query mode %q", ErrInvalidQuery, url.QueryMode)
+	}
+	if err := validateParams(url.FixedParams); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	if err := s.policy.Check(url.Raw); err != nil {
+		return fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	return nil
+}
+
+// expiry returns the time the shortened URL expires at, the zero time is never.
+// The expiry is truncated to seconds, it must be in the future.
+func expiry(url models.URL, now time.Time) (time.Time, error) {
+	switch {
+	case url.TTL < 0:
+		return time.Time{}, fmt.Errorf("%w: negative ttl", ErrInvalidExpiry)
+	case url.TTL > 0 && !url.ExpiresAt.IsZero():
+		return time.Time{}, fmt.Errorf("%w: both expires_at and ttl are set", ErrInvalidExpiry)
+	case url.TTL > 0:
+		return now.Add(url.TTL).UTC().Truncate(time.Second), nil
+	case url.ExpiresAt.IsZero():
+		return time.Time{}, nil
+	}
+
+	expiresAt := url.ExpiresAt.UTC().Truncate(time.Second)
+	if !expiresAt.After(now) {
+		return time.Time{}, fmt.Errorf("%w: expires_at is in the past", ErrInvalidExpiry)
+	}
+	return expiresAt, nil
+}
+
+// window returns the activation window of the shortened URL, the zero time leaves
+// the window open on that side. The window is truncated to seconds, it must end
+// in the future after it starts.
+func window(url models.URL, now time.Time) (time.Time, time.Time, error) {
+	var activateAt, deactivateAt time.Time
+	if !url.ActivateAt.IsZero() {
+		activateAt = url.ActivateAt.UTC().Truncate(time.Second)
+	}
+	if url.DeactivateAt.IsZero() {
+		return activateAt, time.Time{}, nil
+	}
+
+	deactivateAt = url.DeactivateAt.UTC().Truncate(time.Second)
+	if !deactivateAt.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: deactivate_at is in the past", ErrInvalidWindow)
+	}
+	if !activateAt.IsZero() && !deactivateAt.After(activateAt) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: deactivate_at is not after activate_at", ErrInvalidWindow)
+	}
+	return activateAt, deactivateAt, nil
+}
+
+// prepare returns a new shortenedURL of the URL without the slug. The URL is validated,
+// the password is hashed and the canonical form is taken once for all slug attempts.
+func (s *shortener) prepare(url models.URL) (shortenedURL, error) {
+	if err := s.validate(url); err != nil {
+		return shortenedURL{}, err
+	}
+	now := time.Now()
+	expiresAt, err := expiry(url, now)
+	if err != nil {
+		return shortenedURL{}, err
+	}
+	activateAt, deactivateAt, err := window(url, now)
+	if err != nil {
+		return shortenedURL{}, err
+	}
+	passwordHash, err := hashPassword(url.Password)
+	if err != nil {
+		return shortenedURL{}, err
+	}
+	canonical, err := s.canon.Canonical(url.Raw)
+	if err != nil {
+		return shortenedURL{}, ErrInvalidCreation
+	}
+
+	return shortenedURL{
+		UserID:         url.UserID,
+		CorrID:         url.CorrID,
+		Raw:            url.Raw,
+		Canonical:      canonical,
+		ExpiresAt:      expiresAt,
+		ActivateAt:     activateAt,
+		DeactivateAt:   deactivateAt,
+		MaxVisits:      url.MaxVisits,
+		PasswordHash:   passwordHash,
+		RedirectStatus: url.RedirectStatus,
+		CachePolicy:    url.CachePolicy,
+		QueryMode:      url.QueryMode,
+		FixedParams:    url.FixedParams,
+		ForwardPath:    url.ForwardPath,
+	}, nil
+}
+
+// shorten returns the prepared shortenedURL with the slug of the attempt.
+// The slug is generated for the canonical form of the URL. The reserved slug
+// is taken by the route, so it is reported as shortenedurl.ErrSlugCollision.
+func (s *shortener) shorten(ctx context.Context, prepared shortenedURL, attempt int) (shortenedURL, error) {
+	slug, err := s.slugs.Slug(ctx, prepared.Canonical, attempt)
+	if err == nil && len(slug) == 0 {
+		err = fmt.Errorf("empty slug")
+	}
+	if err != nil {
+		logger := zerologx.Get()
+		logger.Error().Err(err).Msg("shorturl: failed to generate slug")
+		return shortenedURL{}, ErrInternal
+	}
+	if reservedAliases[strings.ToLower(slug)] {
+		return shortenedURL{}, shortenedurl.ErrSlugCollision
+	}
+	return prepared.withSlug(s.baseURL, slug), nil
+}
+
+// Short creates and saves a new shortened URL. The URL is saved with a new slug
+// after a slug collision up to maxSlugAttempts times. The URL with the custom alias
+// is saved with the alias as the slug, ErrAliasTaken is returned if the alias is taken.
 func (s *shortener) Short(ctx context.Context, url models.URL) (string, error) {
-	shortenedURL, err := shortenURL(
-		url.UserID,
-		url.CorrID,
-		url.Raw,
-		s.baseURL,
-	)
+	if len(url.Alias) != 0 {
+		return s.shortAlias(ctx, url)
+	}
+
+	prepared, err := s.prepare(url)
 	if err != nil {
-		return "", ErrInvalidCreation
+		return "", err
+	}
+	for attempt := 1; ; attempt++ {
+		shortenedURL, err := s.shorten(ctx, prepared, attempt)
+		if err == nil {
+			err = s.saver.Save(ctx, shortenedURL.ToModel())
+		}
+		if errors.Is(err, shortenedurl.ErrSlugCollision) {
+			if s.collided(attempt) {
+				continue
+			}
+			return "", ErrSlugCollision
+		}
+		if err != nil {
+			if errors.Is(err, shortenedurl.ErrUniqueViolation) {
+				err = ErrUniqueViolation
+			}
+			return "", err
+		}
+
+		return shortenedURL.Value, nil
 	}
+}
 
-	if err = s.saver.Save(ctx, shortenedURL.ToModel()); err != nil {
-		if errors.Is(err, shortenedurl.ErrUniqueViolation) {
+// shortAlias creates and saves a new shortened URL with the custom alias.
+func (s *shortener) shortAlias(ctx context.Context, url models.URL) (string, error) {
+	if err := validateAlias(url.Alias); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidAlias, err)
+	}
+	prepared, err := s.prepare(url)
+	if err != nil {
+		return "", err
+	}
+	shortenedURL := prepared.withSlug(s.baseURL, url.Alias)
+
+	err = s.saver.Save(ctx, shortenedURL.ToModel())
+	if err != nil {
+		switch {
+		case errors.Is(err, shortenedurl.ErrSlugCollision):
+			err = ErrAliasTaken
+		case errors.Is(err, shortenedurl.ErrUniqueViolation):
 			err = ErrUniqueViolation
 		}
 		return "", err
@@ -82,26 +356,53 @@ func (s *shortener) Short(ctx context.Context, url models.URL) (string, error) {
 // Batch was for empty URLs list.
 var ErrEmptyBatch = errors.New("empty batch")
 
-// Batch creates and saves a new shortened URLs.
-func (s *shortener) Batch(ctx context.Context, urls []models.URL) ([]models.ShortenedURL, error) {
+// Batch creates and saves a new shortened URLs. The URLs that have been shortened before
+// are returned with their existing shortened form and BatchExisted status.
+// The batch is saved with new slugs after a slug collision up to maxSlugAttempts times.
+func (s *shortener) Batch(ctx context.Context, urls []models.URL) ([]models.BatchedURL, error) {
 	if len(urls) == 0 {
 		return nil, ErrEmptyBatch
 	}
 
-	shortenedURLs := make(shortenedURLs, len(urls))
+	prepared := make(shortenedURLs, len(urls))
 	for i, v := range urls {
-		s, err := shortenURL(
-			v.UserID,
-			v.CorrID,
-			v.Raw,
-			s.baseURL,
-		)
+		shortened, err := s.prepare(v)
 		if err != nil {
-			return nil, ErrInvalidCreation
+			return nil, err
+		}
+		prepared[i] = shortened
+	}
+
+	var batched []models.BatchedURL
+	for attempt := 1; ; attempt++ {
+		var err error
+		shortenedURLs := make(shortenedURLs, len(prepared))
+		for i, v := range prepared {
+			if shortenedURLs[i], err = s.shorten(ctx, v, attempt); err != nil {
+				break
+			}
 		}
-		shortenedURLs[i] = s
+		if err == nil {
+			batched, err = s.saver.Batch(ctx, shortenedURLs.ToModel())
+		}
+		if errors.Is(err, shortenedurl.ErrSlugCollision) {
+			if s.collided(attempt) {
+				continue
+			}
+			return nil, ErrSlugCollision
+		}
+		if err != nil {
+			if errors.Is(err, shortenedurl.ErrUniqueViolation) {
+				err = ErrUniqueViolation
+			}
+			return nil, err
+		}
+		break
+	}
+	// The existing URLs keep the correlation of the request.
+	for i := range batched {
+		batched[i].CorrID = urls[i].CorrID
 	}
 
-	urlsToBatch := shortenedURLs.ToModel()
-	return urlsToBatch, s.saver.Batch(ctx, urlsToBatch)
+	return batched, nil
 }
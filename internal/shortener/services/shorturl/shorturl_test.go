@@ -3,17 +3,21 @@ package shorturl
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type saverMock struct {
 	SaveFn  func(context.Context, models.ShortenedURL) error
-	BatchFn func(ctx context.Context, urls []models.ShortenedURL) error
+	BatchFn func(ctx context.Context, urls []models.ShortenedURL) ([]models.BatchedURL, error)
 }
 
 func (m *saverMock) Save(ctx context.Context, data models.ShortenedURL) error {
@@ -23,11 +27,29 @@ func (m *saverMock) Save(ctx context.Context, data models.ShortenedURL) error {
 	return fmt.Errorf("unable to save")
 }
 
-func (m *saverMock) Batch(ctx context.Context, urls []models.ShortenedURL) error {
+func (m *saverMock) Batch(ctx context.Context, urls []models.ShortenedURL) ([]models.BatchedURL, error) {
 	if m != nil && m.BatchFn != nil {
 		return m.BatchFn(ctx, urls)
 	}
-	return fmt.Errorf("unable to batch")
+	return nil, fmt.Errorf("unable to batch")
+}
+
+// testSlugs generates the random slugs of the default length.
+var testSlugs = &randomSlugs{length: defaultSlugLength, alphabet: Base62Alphabet}
+
+// testCanon canonicalizes the URLs by the default steps.
+var testCanon, _ = Canonicalizer(CanonicalConfig{Steps: defaultCanonicalSteps})
+
+// testPolicy checks the URLs by the default rules, the private addresses are allowed.
+var testPolicy, _ = Policy(PolicyRules{AllowPrivate: true}, "http://localhost:8080")
+
+// batchCreated returns the URLs as created.
+func batchCreated(urls []models.ShortenedURL) []models.BatchedURL {
+	batched := make([]models.BatchedURL, len(urls))
+	for i, v := range urls {
+		batched[i] = models.NewBatchedURL(v, models.BatchCreated)
+	}
+	return batched
 }
 
 func TestShortener_Short(t *testing.T) {
@@ -40,6 +62,7 @@ func TestShortener_Short(t *testing.T) {
 		url     url
 		err     error
 		saver   saverMock
+		slugs   SlugGenerator
 		baseURL string
 		name    string
 	}{
@@ -101,11 +124,66 @@ func TestShortener_Short(t *testing.T) {
 			},
 			err: ErrInternal,
 		},
+		{
+			name:    "Short URL, empty userID",
+			baseURL: "http://localhost:8080",
+			url: url{
+				corrID: "1",
+				raw:    "http://demo.com",
+			},
+			err: ErrInvalidCreation,
+		},
+		{
+			name:    "Short URL, empty URI",
+			baseURL: "http://localhost:8080",
+			url: url{
+				userID: "1",
+				corrID: "1",
+			},
+			err: ErrInvalidCreation,
+		},
+		{
+			name:    "Short URL, invalid URI",
+			baseURL: "http://localhost:8080",
+			url: url{
+				userID: "1",
+				corrID: "1",
+				raw:    "httpdemo.com",
+			},
+			err: ErrInvalidCreation,
+		},
+		{
+			name: "Short URL, empty baseURL",
+			url: url{
+				userID: "1",
+				corrID: "1",
+				raw:    "http://demo.com",
+			},
+			err: ErrInvalidCreation,
+		},
+		{
+			name:    "Short URL, empty slug",
+			baseURL: "http://localhost:8080",
+			url: url{
+				userID: "1",
+				corrID: "1",
+				raw:    "http://demo.com",
+			},
+			slugs: &randomSlugs{alphabet: Base62Alphabet},
+			err:   ErrInternal,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			slugs := tt.slugs
+			if slugs == nil {
+				slugs = testSlugs
+			}
 			service := shortener{
+				slugs:   slugs,
+				canon:   testCanon,
+				policy:  testPolicy,
 				baseURL: tt.baseURL,
 				saver:   &tt.saver,
 			}
@@ -130,11 +208,12 @@ func TestShortener_Batch(t *testing.T) {
 		raw    string
 	}
 	tests := []struct {
-		saver   saverMock
-		urls    []url
-		err     error
-		name    string
-		baseURL string
+		saver    saverMock
+		urls     []url
+		statuses []models.BatchStatus
+		err      error
+		name     string
+		baseURL  string
 	}{
 		{
 			name:    "Batch all urls, no error",
@@ -167,8 +246,34 @@ func TestShortener_Batch(t *testing.T) {
 				},
 			},
 			saver: saverMock{
-				BatchFn: func(ctx context.Context, su []models.ShortenedURL) error {
-					return nil
+				BatchFn: func(ctx context.Context, su []models.ShortenedURL) ([]models.BatchedURL, error) {
+					return batchCreated(su), nil
+				},
+			},
+		},
+		{
+			name:    "Batch urls, some existed",
+			baseURL: "http://localhost:8080",
+			urls: []url{
+				{
+					userID: "1",
+					corrID: "1",
+					raw:    "http://example.com/query1",
+				},
+				{
+					userID: "1",
+					corrID: "2",
+					raw:    "http://example.com/query2",
+				},
+			},
+			statuses: []models.BatchStatus{models.BatchExisted, models.BatchCreated},
+			saver: saverMock{
+				BatchFn: func(ctx context.Context, su []models.ShortenedURL) ([]models.BatchedURL, error) {
+					batched := batchCreated(su)
+					batched[0] = models.NewBatchedURL(models.NewShortenedURL("1", "old",
+						"http://example.com/query1", "slug1", "http://localhost:8080/slug1"),
+						models.BatchExisted)
+					return batched, nil
 				},
 			},
 		},
@@ -237,6 +342,23 @@ func TestShortener_Batch(t *testing.T) {
 			saver: saverMock{},
 			err:   fmt.Errorf("unable to batch"),
 		},
+		{
+			name:    "Batch with unique violation",
+			baseURL: "http://localhost:8080",
+			urls: []url{
+				{
+					userID: "1",
+					corrID: "1",
+					raw:    "http://example.com/query1",
+				},
+			},
+			saver: saverMock{
+				BatchFn: func(ctx context.Context, su []models.ShortenedURL) ([]models.BatchedURL, error) {
+					return nil, shortenedurl.ErrUniqueViolation
+				},
+			},
+			err: ErrUniqueViolation,
+		},
 		{
 			name:    "Empty batch",
 			baseURL: "http://localhost:8080",
@@ -247,6 +369,9 @@ func TestShortener_Batch(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			service := shortener{
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
 				baseURL: "http://localhost:8080",
 				saver:   &tt.saver,
 			}
@@ -264,14 +389,830 @@ func TestShortener_Batch(t *testing.T) {
 			if tt.err == nil {
 				require.NoError(t, err)
 
+				require.Equal(t, len(tt.urls), len(batched))
 				for i, v := range batched {
 					assert.Equal(t, tt.urls[i].userID, v.UserID)
 					assert.Equal(t, tt.urls[i].corrID, v.CorrID)
 					assert.Equal(t, tt.urls[i].raw, v.Raw)
+
+					status := models.BatchCreated
+					if tt.statuses != nil {
+						status = tt.statuses[i]
+					}
+					assert.Equal(t, status, v.Status)
 				}
 				return
 			}
 			assert.Equal(t, tt.err.Error(), err.Error())
+			assert.Nil(t, batched)
+		})
+	}
+}
+
+func TestShortener_SlugCollision(t *testing.T) {
+	defer func(cost int) { passwordCost = cost }(passwordCost)
+	passwordCost = bcrypt.MinCost
+
+	tests := []struct {
+		name       string
+		collisions int
+		err        error
+		stats      SlugStats
+	}{
+		{
+			name:       "Saved after collisions",
+			collisions: maxSlugAttempts - 1,
+			stats:      SlugStats{Collisions: maxSlugAttempts - 1},
+		},
+		{
+			name:       "Attempts exhausted",
+			collisions: maxSlugAttempts,
+			err:        ErrSlugCollision,
+			stats:      SlugStats{Collisions: maxSlugAttempts, Exhausted: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+", short", func(t *testing.T) {
+			slugs := make(map[string]bool)
+			hashes := make(map[string]bool)
+			service := shortener{
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				baseURL: "http://localhost:8080",
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						assert.False(t, slugs[data.Slug], "slug is reused")
+						slugs[data.Slug] = true
+						hashes[data.PasswordHash] = true
+						if len(slugs) <= tt.collisions {
+							return shortenedurl.ErrSlugCollision
+						}
+						return nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", "http://example.com/query")
+			url.Password = "secret"
+
+			got, err := service.Short(context.TODO(), url)
+			if tt.err == nil {
+				require.NoError(t, err)
+				assert.NotEmpty(t, got)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+			assert.Len(t, hashes, 1, "password is hashed per attempt")
+			assert.Equal(t, tt.stats, service.SlugStats())
+		})
+
+		t.Run(tt.name+", batch", func(t *testing.T) {
+			attempts := 0
+			service := shortener{
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				baseURL: "http://localhost:8080",
+				saver: &saverMock{
+					BatchFn: func(_ context.Context, su []models.ShortenedURL) ([]models.BatchedURL, error) {
+						attempts++
+						if attempts <= tt.collisions {
+							return nil, shortenedurl.ErrSlugCollision
+						}
+						return batchCreated(su), nil
+					},
+				},
+			}
+
+			got, err := service.Batch(context.TODO(), []models.URL{
+				models.NewURL("1", "1", "http://example.com/query1"),
+				models.NewURL("1", "2", "http://example.com/query2"),
+			})
+			if tt.err == nil {
+				require.NoError(t, err)
+				assert.Equal(t, 2, len(got))
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Nil(t, got)
+			}
+			assert.Equal(t, tt.stats, service.SlugStats())
+		})
+	}
+}
+
+func TestShortener_ReservedSlug(t *testing.T) {
+	counter := uint64(4844)
+	slugs, err := CounterSlugs(&sequenceMock{
+		NextFn: func(context.Context) (uint64, error) {
+			counter++
+			return counter, nil
+		},
+	}, Base62Alphabet)
+	require.NoError(t, err)
+	require.Equal(t, "api", bijective(counter+1, Base62Alphabet))
+
+	var saved string
+	service := shortener{
+		slugs:   slugs,
+		canon:   testCanon,
+		policy:  testPolicy,
+		baseURL: "http://localhost:8080",
+		saver: &saverMock{
+			SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+				saved = data.Slug
+				return nil
+			},
+		},
+	}
+
+	got, err := service.Short(context.TODO(), models.NewURL("1", "1", "http://example.com/query"))
+	require.NoError(t, err)
+	assert.Equal(t, "apj", saved)
+	assert.Equal(t, "http://localhost:8080/apj", got)
+	assert.Equal(t, SlugStats{Collisions: 1}, service.SlugStats())
+}
+
+func TestShortener_Alias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		saveErr error
+		want    string
+		err     error
+	}{
+		{
+			name:  "Saved with alias",
+			alias: "spring-sale",
+			want:  "http://localhost:8080/spring-sale",
+		},
+		{
+			name:    "Alias is taken",
+			alias:   "spring-sale",
+			saveErr: shortenedurl.ErrSlugCollision,
+			err:     ErrAliasTaken,
+		},
+		{
+			name:    "URL has been shortened",
+			alias:   "spring-sale",
+			saveErr: shortenedurl.ErrUniqueViolation,
+			err:     ErrUniqueViolation,
+		},
+		{
+			name:  "Invalid alias",
+			alias: "spring/sale",
+			err:   ErrInvalidAlias,
+		},
+		{
+			name:  "Reserved alias",
+			alias: "api",
+			err:   ErrInvalidAlias,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saves := 0
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						saves++
+						assert.Equal(t, tt.alias, data.Slug)
+						return tt.saveErr
+					},
+				},
+			}
+
+			url := models.NewURL("1", "", "http://example.com/query")
+			url.Alias = tt.alias
+
+			got, err := service.Short(context.TODO(), url)
+			if tt.err == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+			// The alias is not retried.
+			assert.LessOrEqual(t, saves, 1)
+			assert.Equal(t, SlugStats{}, service.SlugStats())
+		})
+	}
+}
+
+func TestShortener_Canonical(t *testing.T) {
+	const (
+		raw       = "HTTP://Example.com:80/a/../b?utm_source=x"
+		canonical = "http://example.com/b"
+	)
+
+	canon, err := Canonicalizer(CanonicalConfig{
+		Steps: append(defaultCanonicalSteps, CanonicalStripTracking),
+	})
+	require.NoError(t, err)
+	slugs, err := HashSlugs(defaultSlugLength, Base62Alphabet)
+	require.NoError(t, err)
+
+	var saved []models.ShortenedURL
+	service := shortener{
+		baseURL: "http://localhost:8080",
+		slugs:   slugs,
+		canon:   canon,
+		policy:  testPolicy,
+		saver: &saverMock{
+			SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+				saved = append(saved, data)
+				return nil
+			},
+		},
+	}
+
+	for _, v := range []string{raw, canonical} {
+		_, err = service.Short(context.TODO(), models.NewURL("1", "", v))
+		require.NoError(t, err)
+	}
+	require.Len(t, saved, 2)
+
+	// The original URL is kept for redirects.
+	assert.Equal(t, raw, saved[0].Raw)
+	assert.Equal(t, canonical, saved[0].Canonical)
+	assert.Equal(t, canonical, saved[1].Canonical)
+	// The hash slugs are generated for the canonical form.
+	assert.Equal(t, saved[0].Slug, saved[1].Slug)
+
+	// The queries that do not parse as the form values are shortened as they are.
+	for _, v := range []string{"http://example.com/?a=1;b=2", "http://example.com/?q=100%"} {
+		_, err = service.Short(context.TODO(), models.NewURL("1", "", v))
+		require.NoError(t, err, v)
+		assert.Equal(t, v, saved[len(saved)-1].Canonical)
+	}
+}
+
+func TestShortener_Policy(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		alias  string
+		reason string
+	}{
+		{
+			name:   "Scheme is not allowed",
+			raw:    "ftp://example.com/file",
+			reason: RejectedScheme,
+		},
+		{
+			name:   "Self link",
+			raw:    "http://short.ly/slug",
+			reason: RejectedSelf,
+		},
+		{
+			name:   "Self link with alias",
+			raw:    "http://GO.short.ly/slug",
+			alias:  "loop",
+			reason: RejectedSelf,
+		},
+		{
+			name:   "Host is denied",
+			raw:    "https://cdn.malware.example/x",
+			reason: RejectedHost,
+		},
+	}
+
+	policy, err := Policy(PolicyRules{
+		DeniedHosts: []string{"*.malware.example"},
+		SelfDomains: []string{"short.ly", "*.short.ly"},
+	}, "http://localhost:8080")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  policy,
+				saver: &saverMock{
+					SaveFn: func(context.Context, models.ShortenedURL) error {
+						t.Fatal("rejected URL is saved")
+						return nil
+					},
+					BatchFn: func(context.Context, []models.ShortenedURL) ([]models.BatchedURL, error) {
+						t.Fatal("rejected URL is batched")
+						return nil, nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", tt.raw)
+			url.Alias = tt.alias
+
+			var rej *Rejection
+			_, err := service.Short(context.TODO(), url)
+			require.ErrorIs(t, err, ErrRejected)
+			require.ErrorAs(t, err, &rej)
+			assert.Equal(t, tt.reason, rej.Reason)
+
+			_, err = service.Batch(context.TODO(), []models.URL{
+				models.NewURL("1", "0", "http://example.com"),
+				url,
+			})
+			require.ErrorIs(t, err, ErrRejected)
+			require.ErrorAs(t, err, &rej)
+			assert.Equal(t, tt.reason, rej.Reason)
+		})
+	}
+}
+
+func TestShortener_Expiry(t *testing.T) {
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		ttl       time.Duration
+		alias     string
+		want      time.Duration
+		err       error
+	}{
+		{
+			name: "Never expires",
+		},
+		{
+			name:      "Expires at",
+			expiresAt: expiresAt.In(time.FixedZone("UTC+3", 3*60*60)),
+			want:      time.Until(expiresAt),
+		},
+		{
+			name: "Expires after TTL",
+			ttl:  time.Hour,
+			want: time.Hour,
+		},
+		{
+			name:  "Alias expires after TTL",
+			ttl:   time.Hour,
+			alias: "spring-sale",
+			want:  time.Hour,
+		},
+		{
+			name:      "Expires at in the past",
+			expiresAt: time.Now().Add(-time.Minute),
+			err:       ErrInvalidExpiry,
+		},
+		{
+			name:  "Negative TTL",
+			ttl:   -time.Hour,
+			alias: "spring-sale",
+			err:   ErrInvalidExpiry,
+		},
+		{
+			name:      "Both expires at and TTL",
+			expiresAt: expiresAt,
+			ttl:       time.Hour,
+			err:       ErrInvalidExpiry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []models.ShortenedURL
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						saved = append(saved, data)
+						return nil
+					},
+					BatchFn: func(_ context.Context, urls []models.ShortenedURL) ([]models.BatchedURL, error) {
+						saved = append(saved, urls...)
+						return batchCreated(urls), nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", "http://example.com")
+			url.Alias = tt.alias
+			url.ExpiresAt = tt.expiresAt
+			url.TTL = tt.ttl
+
+			_, err := service.Short(context.TODO(), url)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, saved)
+				return
+			}
+			require.NoError(t, err)
+			if len(tt.alias) == 0 {
+				_, err = service.Batch(context.TODO(), []models.URL{url})
+				require.NoError(t, err)
+			}
+
+			for _, v := range saved {
+				if tt.want == 0 {
+					assert.True(t, v.ExpiresAt.IsZero())
+					continue
+				}
+				assert.Equal(t, time.UTC, v.ExpiresAt.Location())
+				assert.Zero(t, v.ExpiresAt.Nanosecond())
+				assert.WithinDuration(t, time.Now().Add(tt.want), v.ExpiresAt, 2*time.Second)
+			}
+		})
+	}
+}
+
+func TestShortener_MaxVisits(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxVisits int
+		alias     string
+		err       error
+	}{
+		{
+			name: "Unlimited",
+		},
+		{
+			name:      "One-time link",
+			maxVisits: 1,
+		},
+		{
+			name:      "Alias with visits limit",
+			maxVisits: 3,
+			alias:     "onboarding",
+		},
+		{
+			name:      "Negative visits limit",
+			maxVisits: -1,
+			err:       ErrInvalidMaxVisits,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []models.ShortenedURL
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						saved = append(saved, data)
+						return nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", "http://example.com")
+			url.Alias = tt.alias
+			url.MaxVisits = tt.maxVisits
+
+			_, err := service.Short(context.TODO(), url)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, saved)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, saved, 1)
+			assert.Equal(t, tt.maxVisits, saved[0].MaxVisits)
+			assert.Zero(t, saved[0].Visits)
+		})
+	}
+}
+
+func TestShortener_Password(t *testing.T) {
+	defer func(cost int) { passwordCost = cost }(passwordCost)
+	passwordCost = bcrypt.MinCost
+
+	tests := []struct {
+		name     string
+		password string
+		alias    string
+		err      error
+	}{
+		{
+			name: "Unprotected",
+		},
+		{
+			name:     "Protected",
+			password: "secret",
+		},
+		{
+			name:     "Alias with password",
+			password: "secret",
+			alias:    "docs",
+		},
+		{
+			name:     "Too long password",
+			password: strings.Repeat("p", maxPasswordLength+1),
+			err:      ErrInvalidPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []models.ShortenedURL
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						saved = append(saved, data)
+						return nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", "http://example.com")
+			url.Alias = tt.alias
+			url.Password = tt.password
+
+			_, err := service.Short(context.TODO(), url)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, saved)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, saved, 1)
+			if len(tt.password) == 0 {
+				assert.False(t, saved[0].Protected())
+				return
+			}
+			assert.NotEqual(t, tt.password, saved[0].PasswordHash)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(saved[0].PasswordHash), []byte(tt.password)))
+		})
+	}
+}
+
+func TestShortener_Window(t *testing.T) {
+	launch := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+
+	tests := []struct {
+		name         string
+		activateAt   time.Time
+		deactivateAt time.Time
+		alias        string
+		err          error
+	}{
+		{
+			name: "Always active",
+		},
+		{
+			name:       "Activates at launch",
+			activateAt: launch.In(time.FixedZone("UTC+3", 3*60*60)),
+		},
+		{
+			name:         "Alias with window",
+			activateAt:   launch,
+			deactivateAt: launch.Add(time.Hour),
+			alias:        "launch",
+		},
+		{
+			name:       "Activated in the past",
+			activateAt: time.Now().Add(-time.Hour).Truncate(time.Second).UTC(),
+		},
+		{
+			name:         "Deactivates later",
+			deactivateAt: launch,
+		},
+		{
+			name:         "Deactivate at in the past",
+			deactivateAt: time.Now().Add(-time.Minute),
+			err:          ErrInvalidWindow,
+		},
+		{
+			name:         "Deactivate at before activate at",
+			activateAt:   launch,
+			deactivateAt: launch.Add(-time.Hour),
+			alias:        "launch",
+			err:          ErrInvalidWindow,
+		},
+		{
+			name:         "Empty window",
+			activateAt:   launch,
+			deactivateAt: launch,
+			err:          ErrInvalidWindow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []models.ShortenedURL
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						saved = append(saved, data)
+						return nil
+					},
+					BatchFn: func(_ context.Context, urls []models.ShortenedURL) ([]models.BatchedURL, error) {
+						saved = append(saved, urls...)
+						return batchCreated(urls), nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", "http://example.com")
+			url.Alias = tt.alias
+			url.ActivateAt = tt.activateAt
+			url.DeactivateAt = tt.deactivateAt
+
+			_, err := service.Short(context.TODO(), url)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, saved)
+				return
+			}
+			require.NoError(t, err)
+			if len(tt.alias) == 0 {
+				_, err = service.Batch(context.TODO(), []models.URL{url})
+				require.NoError(t, err)
+			}
+
+			for _, v := range saved {
+				assert.True(t, tt.activateAt.Equal(v.ActivateAt), "activate at %v, got %v", tt.activateAt, v.ActivateAt)
+				assert.True(t, tt.deactivateAt.Equal(v.DeactivateAt))
+				if !v.ActivateAt.IsZero() {
+					assert.Equal(t, time.UTC, v.ActivateAt.Location())
+				}
+			}
+		})
+	}
+}
+
+func TestShortener_Redirect(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		cache  string
+		alias  string
+		err    error
+	}{
+		{
+			name: "Service defaults",
+		},
+		{
+			name:   "Permanent redirect",
+			status: http.StatusMovedPermanently,
+			cache:  models.CachePublic,
+		},
+		{
+			name:   "Tracking alias",
+			status: http.StatusFound,
+			cache:  models.CacheNoStore,
+			alias:  "track",
+		},
+		{
+			name:   "Not a redirect status",
+			status: http.StatusOK,
+			err:    ErrInvalidRedirect,
+		},
+		{
+			name:  "Unknown cache policy",
+			cache: "forever",
+			alias: "forever",
+			err:   ErrInvalidRedirect,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []models.ShortenedURL
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						saved = append(saved, data)
+						return nil
+					},
+					BatchFn: func(_ context.Context, urls []models.ShortenedURL) ([]models.BatchedURL, error) {
+						saved = append(saved, urls...)
+						return batchCreated(urls), nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", "http://example.com")
+			url.Alias = tt.alias
+			url.RedirectStatus = tt.status
+			url.CachePolicy = tt.cache
+
+			_, err := service.Short(context.TODO(), url)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, saved)
+				return
+			}
+			require.NoError(t, err)
+			if len(tt.alias) == 0 {
+				_, err = service.Batch(context.TODO(), []models.URL{url})
+				require.NoError(t, err)
+			}
+
+			for _, v := range saved {
+				assert.Equal(t, tt.status, v.RedirectStatus)
+				assert.Equal(t, tt.cache, v.CachePolicy)
+			}
+		})
+	}
+}
+
+func TestShortener_QueryForwarding(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		params  string
+		forward bool
+		alias   string
+		err     error
+	}{
+		{
+			name: "Query is dropped",
+		},
+		{
+			name:    "Merged query with UTM parameters",
+			mode:    models.QueryMerge,
+			params:  "utm_medium=email&utm_source=newsletter",
+			forward: true,
+		},
+		{
+			name:   "Overridden query of the alias",
+			mode:   models.QueryOverride,
+			params: "utm_campaign=spring",
+			alias:  "spring",
+		},
+		{
+			name: "Unknown query mode",
+			mode: "append",
+			err:  ErrInvalidQuery,
+		},
+		{
+			name:   "Invalid fixed params",
+			params: "utm_source=%zz",
+			alias:  "invalid",
+			err:    ErrInvalidQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []models.ShortenedURL
+			service := shortener{
+				baseURL: "http://localhost:8080",
+				slugs:   testSlugs,
+				canon:   testCanon,
+				policy:  testPolicy,
+				saver: &saverMock{
+					SaveFn: func(_ context.Context, data models.ShortenedURL) error {
+						saved = append(saved, data)
+						return nil
+					},
+					BatchFn: func(_ context.Context, urls []models.ShortenedURL) ([]models.BatchedURL, error) {
+						saved = append(saved, urls...)
+						return batchCreated(urls), nil
+					},
+				},
+			}
+
+			url := models.NewURL("1", "1", "http://example.com")
+			url.Alias = tt.alias
+			url.QueryMode = tt.mode
+			url.FixedParams = tt.params
+			url.ForwardPath = tt.forward
+
+			_, err := service.Short(context.TODO(), url)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, saved)
+				return
+			}
+			require.NoError(t, err)
+			if len(tt.alias) == 0 {
+				_, err = service.Batch(context.TODO(), []models.URL{url})
+				require.NoError(t, err)
+			}
+
+			for _, v := range saved {
+				assert.Equal(t, tt.mode, v.QueryMode)
+				assert.Equal(t, tt.params, v.FixedParams)
+				assert.Equal(t, tt.forward, v.ForwardPath)
+			}
 		})
 	}
 }
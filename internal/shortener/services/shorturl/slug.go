@@ -0,0 +1,280 @@
+package shorturl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// SlugGenerator defines the slug generator of the shortened URLs.
+type SlugGenerator interface {
+	// Slug returns the slug for the raw URL. The attempt starts with 1 and grows
+	// after each slug collision, the deterministic generators vary the slug by it.
+	Slug(ctx context.Context, raw string, attempt int) (string, error)
+}
+
+// slugSequence defines the storage sequence of the counter slugs.
+type slugSequence interface {
+	Next(context.Context) (uint64, error)
+}
+
+// Slug alphabets.
+const (
+	// Base62Alphabet is the default alphabet of the slugs.
+	Base62Alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// ReadableAlphabet is the base62 alphabet without the look-alike 0, O, 1 and l.
+	ReadableAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHIJKLMNPQRSTUVWXYZ23456789"
+)
+
+// Slug lengths.
+const (
+	defaultSlugLength = 7
+	maxSlugLength     = 32
+)
+
+// Slug generation strategies.
+const (
+	SlugRandom   = "random"
+	SlugReadable = "readable"
+	SlugCounter  = "counter"
+	SlugHash     = "hash"
+)
+
+// SlugConfig represents the slug generation configuration.
+type SlugConfig struct {
+	// Strategy is one of SlugRandom, SlugReadable, SlugCounter or SlugHash.
+	Strategy string `env:"SLUG_STRATEGY" envDefault:"random"`
+
+	// Length is the length of the random and hash slugs.
+	// The counter slugs grow with the sequence.
+	Length int `env:"SLUG_LENGTH" envDefault:"7"`
+
+	// Alphabet is the alphabet of the slugs, Base62Alphabet if empty.
+	// It is not configurable for the readable slugs.
+	Alphabet string `env:"SLUG_ALPHABET" envDefault:""`
+}
+
+// Empty checks on being empty.
+func (c SlugConfig) Empty() bool {
+	return len(c.Strategy) == 0 &&
+		c.Length == 0 &&
+		len(c.Alphabet) == 0
+}
+
+// Slugs returns a new SlugGenerator of the configured strategy. The sequence is required
+// by the counter strategy only.
+func Slugs(cfg SlugConfig, seq slugSequence) (SlugGenerator, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if len(cfg.Strategy) == 0 {
+		cfg.Strategy = SlugRandom
+	}
+	if cfg.Length == 0 {
+		cfg.Length = defaultSlugLength
+	}
+	if len(cfg.Alphabet) == 0 && cfg.Strategy != SlugReadable {
+		cfg.Alphabet = Base62Alphabet
+	}
+
+	switch cfg.Strategy {
+	case SlugRandom:
+		return RandomSlugs(cfg.Length, cfg.Alphabet)
+	case SlugReadable:
+		if len(cfg.Alphabet) != 0 {
+			return nil, fmt.Errorf("alphabet is not configurable for readable slugs")
+		}
+		return ReadableSlugs(cfg.Length)
+	case SlugCounter:
+		return CounterSlugs(seq, cfg.Alphabet)
+	case SlugHash:
+		return HashSlugs(cfg.Length, cfg.Alphabet)
+	default:
+		return nil, fmt.Errorf("unknown slug strategy: %v", cfg.Strategy)
+	}
+}
+
+// validateAlphabet checks that the alphabet consists of 2 to 256 unique characters
+// allowed in the URL path unescaped.
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		return fmt.Errorf("alphabet must have 2 to 256 characters")
+	}
+
+	var seen [256]bool
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~') {
+			return fmt.Errorf("invalid alphabet character: %q", c)
+		}
+		if seen[c] {
+			return fmt.Errorf("repeated alphabet character: %q", c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// validateLength checks the slug length.
+func validateLength(length int) error {
+	if length < 1 || length > maxSlugLength {
+		return fmt.Errorf("slug length must be from 1 to %d", maxSlugLength)
+	}
+	return nil
+}
+
+// randomSlugs generates the cryptographically secure random slugs.
+type randomSlugs struct {
+	length   int
+	alphabet string
+}
+
+// RandomSlugs returns a new randomSlugs of the length in the alphabet.
+func RandomSlugs(length int, alphabet string) (*randomSlugs, error) {
+	if err := validateLength(length); err != nil {
+		return nil, err
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	return &randomSlugs{
+		length:   length,
+		alphabet: alphabet,
+	}, nil
+}
+
+// ReadableSlugs returns a new randomSlugs of the length in ReadableAlphabet.
+func ReadableSlugs(length int) (*randomSlugs, error) {
+	return RandomSlugs(length, ReadableAlphabet)
+}
+
+// Slug returns a new random slug. Each character takes a random byte, the bytes
+// beyond the largest multiple of the alphabet size are rejected to keep
+// the characters uniformly distributed.
+func (g *randomSlugs) Slug(_ context.Context, _ string, _ int) (string, error) {
+	size := len(g.alphabet)
+	limit := 256 - 256%size
+
+	slug := make([]byte, 0, g.length)
+	buf := make([]byte, 2*g.length)
+	for len(slug) < g.length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %v", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			slug = append(slug, g.alphabet[int(b)%size])
+			if len(slug) == g.length {
+				break
+			}
+		}
+	}
+
+	return string(slug), nil
+}
+
+// counterSlugs generates the slugs by the bijective encoding of the sequence values,
+// so every value has a unique slug and the slugs are as short as possible.
+type counterSlugs struct {
+	seq      slugSequence
+	alphabet string
+}
+
+// CounterSlugs returns a new counterSlugs of the sequence values in the alphabet.
+func CounterSlugs(seq slugSequence, alphabet string) (*counterSlugs, error) {
+	if seq == nil {
+		return nil, fmt.Errorf("slug sequence is nil")
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	return &counterSlugs{
+		seq:      seq,
+		alphabet: alphabet,
+	}, nil
+}
+
+// Slug returns the slug of the next sequence value.
+func (g *counterSlugs) Slug(ctx context.Context, _ string, _ int) (string, error) {
+	n, err := g.seq.Next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the next sequence value: %v", err)
+	}
+	if n == 0 {
+		return "", fmt.Errorf("sequence value is zero")
+	}
+	return bijective(n, g.alphabet), nil
+}
+
+// bijective encodes the positive number in the bijective base of the alphabet size:
+// the digits run from 1 to the size, so no two numbers share the encoding.
+func bijective(n uint64, alphabet string) string {
+	size := uint64(len(alphabet))
+
+	var buf [64]byte
+	i := len(buf)
+	for n > 0 {
+		n--
+		i--
+		buf[i] = alphabet[n%size]
+		n /= size
+	}
+	return string(buf[i:])
+}
+
+// hashSlugs generates the slugs deterministically from the hash of the raw URL.
+type hashSlugs struct {
+	length   int
+	alphabet string
+}
+
+// HashSlugs returns a new hashSlugs of the length in the alphabet.
+func HashSlugs(length int, alphabet string) (*hashSlugs, error) {
+	if err := validateLength(length); err != nil {
+		return nil, err
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	return &hashSlugs{
+		length:   length,
+		alphabet: alphabet,
+	}, nil
+}
+
+// Slug returns the slug of the SHA-256 hash of the raw URL. The attempts after
+// the first one hash the URL with the attempt number.
+func (g *hashSlugs) Slug(_ context.Context, raw string, attempt int) (string, error) {
+	data := raw
+	if attempt > 1 {
+		data += "\n" + strconv.Itoa(attempt)
+	}
+	sum := sha256.Sum256([]byte(data))
+
+	var (
+		n    = new(big.Int).SetBytes(sum[:])
+		size = big.NewInt(int64(len(g.alphabet)))
+		m    = new(big.Int)
+	)
+	slug := make([]byte, g.length)
+	for i := range slug {
+		n.DivMod(n, size, m)
+		slug[i] = g.alphabet[m.Int64()]
+	}
+
+	return string(slug), nil
+}
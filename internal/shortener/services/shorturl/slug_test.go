@@ -0,0 +1,241 @@
+package shorturl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sequenceMock struct {
+	NextFn func(context.Context) (uint64, error)
+}
+
+func (m *sequenceMock) Next(ctx context.Context) (uint64, error) {
+	if m != nil && m.NextFn != nil {
+		return m.NextFn(ctx)
+	}
+	return 0, fmt.Errorf("unable to get next")
+}
+
+func TestSlugs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SlugConfig
+		seq     slugSequence
+		want    SlugGenerator
+		wantErr bool
+	}{
+		{
+			name: "Random",
+			cfg:  SlugConfig{Strategy: SlugRandom, Length: 10},
+			want: &randomSlugs{length: 10, alphabet: Base62Alphabet},
+		},
+		{
+			name: "Random, default length",
+			cfg:  SlugConfig{Strategy: SlugRandom},
+			want: &randomSlugs{length: defaultSlugLength, alphabet: Base62Alphabet},
+		},
+		{
+			name: "Random, custom alphabet",
+			cfg:  SlugConfig{Strategy: SlugRandom, Alphabet: "abc"},
+			want: &randomSlugs{length: defaultSlugLength, alphabet: "abc"},
+		},
+		{
+			name: "Readable",
+			cfg:  SlugConfig{Strategy: SlugReadable},
+			want: &randomSlugs{length: defaultSlugLength, alphabet: ReadableAlphabet},
+		},
+		{
+			name: "Counter",
+			cfg:  SlugConfig{Strategy: SlugCounter},
+			seq:  &sequenceMock{},
+			want: &counterSlugs{seq: &sequenceMock{}, alphabet: Base62Alphabet},
+		},
+		{
+			name: "Hash",
+			cfg:  SlugConfig{Strategy: SlugHash, Length: 12},
+			want: &hashSlugs{length: 12, alphabet: Base62Alphabet},
+		},
+		{
+			name:    "Unknown strategy",
+			cfg:     SlugConfig{Strategy: "uuid"},
+			wantErr: true,
+		},
+		{
+			name:    "Readable, custom alphabet",
+			cfg:     SlugConfig{Strategy: SlugReadable, Alphabet: "abc"},
+			wantErr: true,
+		},
+		{
+			name:    "Counter without sequence",
+			cfg:     SlugConfig{Strategy: SlugCounter},
+			wantErr: true,
+		},
+		{
+			name:    "Length is too long",
+			cfg:     SlugConfig{Strategy: SlugRandom, Length: maxSlugLength + 1},
+			wantErr: true,
+		},
+		{
+			name:    "Negative length",
+			cfg:     SlugConfig{Strategy: SlugHash, Length: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Alphabet of one character",
+			cfg:     SlugConfig{Strategy: SlugRandom, Alphabet: "a"},
+			wantErr: true,
+		},
+		{
+			name:    "Repeated alphabet character",
+			cfg:     SlugConfig{Strategy: SlugRandom, Alphabet: "abca"},
+			wantErr: true,
+		},
+		{
+			name:    "Reserved alphabet character",
+			cfg:     SlugConfig{Strategy: SlugRandom, Alphabet: "ab/"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Slugs(tt.cfg, tt.seq)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRandomSlugs_Slug(t *testing.T) {
+	tests := []struct {
+		name     string
+		length   int
+		alphabet string
+	}{
+		{
+			name:     "Base62",
+			length:   defaultSlugLength,
+			alphabet: Base62Alphabet,
+		},
+		{
+			name:     "Readable",
+			length:   maxSlugLength,
+			alphabet: ReadableAlphabet,
+		},
+		{
+			name:     "Binary",
+			length:   1,
+			alphabet: "01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := RandomSlugs(tt.length, tt.alphabet)
+			require.NoError(t, err)
+
+			counts := make(map[rune]int)
+			for i := 0; i < 1000; i++ {
+				slug, err := g.Slug(context.TODO(), "http://demo.com", 1)
+				require.NoError(t, err)
+				require.Len(t, slug, tt.length)
+
+				for _, c := range slug {
+					require.Contains(t, tt.alphabet, string(c))
+					counts[c]++
+				}
+			}
+			// Every character of the alphabet is used.
+			assert.Len(t, counts, len(tt.alphabet))
+		})
+	}
+}
+
+func TestReadableAlphabet(t *testing.T) {
+	assert.False(t, strings.ContainsAny(ReadableAlphabet, "0O1l"))
+	assert.NoError(t, validateAlphabet(ReadableAlphabet))
+}
+
+func TestBijective(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{n: 1, want: "a"},
+		{n: 2, want: "b"},
+		{n: 3, want: "aa"},
+		{n: 4, want: "ab"},
+		{n: 5, want: "ba"},
+		{n: 6, want: "bb"},
+		{n: 7, want: "aaa"},
+		{n: 1<<64 - 1, want: strings.Repeat("a", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n), func(t *testing.T) {
+			assert.Equal(t, tt.want, bijective(tt.n, "ab"))
+		})
+	}
+
+	seen := make(map[string]bool)
+	for n := uint64(1); n <= 10000; n++ {
+		slug := bijective(n, Base62Alphabet)
+		require.False(t, seen[slug], "slug %v is repeated", slug)
+		seen[slug] = true
+	}
+	assert.Equal(t, "9", bijective(62, Base62Alphabet))
+	assert.Equal(t, "aa", bijective(63, Base62Alphabet))
+}
+
+func TestCounterSlugs_Slug(t *testing.T) {
+	var last uint64
+	g, err := CounterSlugs(&sequenceMock{
+		NextFn: func(context.Context) (uint64, error) {
+			last++
+			return last, nil
+		},
+	}, Base62Alphabet)
+	require.NoError(t, err)
+
+	for _, want := range []string{"a", "b", "c"} {
+		slug, err := g.Slug(context.TODO(), "http://demo.com", 1)
+		require.NoError(t, err)
+		assert.Equal(t, want, slug)
+	}
+
+	g, err = CounterSlugs(&sequenceMock{}, Base62Alphabet)
+	require.NoError(t, err)
+
+	_, err = g.Slug(context.TODO(), "http://demo.com", 1)
+	assert.Error(t, err)
+}
+
+func TestHashSlugs_Slug(t *testing.T) {
+	g, err := HashSlugs(defaultSlugLength, Base62Alphabet)
+	require.NoError(t, err)
+
+	slug := func(raw string, attempt int) string {
+		s, err := g.Slug(context.TODO(), raw, attempt)
+		require.NoError(t, err)
+		require.Len(t, s, defaultSlugLength)
+		return s
+	}
+
+	// The same URL has the same slug.
+	assert.Equal(t, slug("http://demo.com", 1), slug("http://demo.com", 1))
+	assert.NotEqual(t, slug("http://demo.com", 1), slug("http://demo2.com", 1))
+
+	// Each attempt has a new slug.
+	assert.NotEqual(t, slug("http://demo.com", 1), slug("http://demo.com", 2))
+	assert.NotEqual(t, slug("http://demo.com", 2), slug("http://demo.com", 3))
+	assert.Equal(t, slug("http://demo.com", 2), slug("http://demo.com", 2))
+}
@@ -0,0 +1,99 @@
+package shorturl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
+	"github.com/caarlos0/env/v6"
+)
+
+// urlExpirer defines the expirer of the shortened URLs.
+type urlExpirer interface {
+	Expire(ctx context.Context, now time.Time) (int, error)
+}
+
+// SweeperConfig represents the expiration sweeper configuration.
+type SweeperConfig struct {
+	// Interval is the interval of marking the expired URLs.
+	Interval time.Duration `env:"URL_EXPIRY_SWEEP_INTERVAL" envDefault:"1m"`
+}
+
+// Empty checks on being empty.
+func (c SweeperConfig) Empty() bool {
+	return c.Interval == 0
+}
+
+// sweeper marks the expired shortened URLs periodically, the marked URLs
+// are no longer collected by user.
+type sweeper struct {
+	expirer urlExpirer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Sweeper returns a new started sweeper of the expirer.
+func Sweeper(cfg SweeperConfig, expirer urlExpirer) (*sweeper, error) {
+	if expirer == nil {
+		return nil, fmt.Errorf("url expirer is nil")
+	}
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("non-positive expiry sweep interval")
+	}
+
+	s := &sweeper{
+		expirer: expirer,
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.sweep(cfg.Interval)
+
+	return s, nil
+}
+
+// Close stops the sweeper.
+func (s *sweeper) Close() error {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// sweep marks the expired URLs periodically until the sweeper is closed.
+func (s *sweeper) sweep(interval time.Duration) {
+	defer s.wg.Done()
+
+	logger := zerologx.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			n, err := s.expirer.Expire(ctx, time.Now())
+			cancel()
+			if err != nil {
+				logger.Error().Err(err).Msg("shorturl: failed to mark expired links")
+				continue
+			}
+			if n > 0 {
+				logger.Info().Int("links", n).Msg("shorturl: expired links marked")
+			}
+		}
+	}
+}
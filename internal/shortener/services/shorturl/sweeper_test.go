@@ -0,0 +1,85 @@
+package shorturl
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type expirerMock struct {
+	ExpireFn func(context.Context, time.Time) (int, error)
+}
+
+func (m *expirerMock) Expire(ctx context.Context, now time.Time) (int, error) {
+	if m != nil && m.ExpireFn != nil {
+		return m.ExpireFn(ctx, now)
+	}
+	return 0, fmt.Errorf("unable to expire")
+}
+
+func TestSweeper(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     SweeperConfig
+		expirer urlExpirer
+		wantErr bool
+	}{
+		{
+			name:    "Valid config",
+			cfg:     SweeperConfig{Interval: time.Minute},
+			expirer: &expirerMock{},
+		},
+		{
+			name:    "Nil expirer",
+			cfg:     SweeperConfig{Interval: time.Minute},
+			wantErr: true,
+		},
+		{
+			name:    "Negative interval",
+			cfg:     SweeperConfig{Interval: -time.Minute},
+			expirer: &expirerMock{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Sweeper(tt.cfg, tt.expirer)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, s.Close())
+			// Close is idempotent.
+			assert.NoError(t, s.Close())
+		})
+	}
+}
+
+func TestSweeper_Sweep(t *testing.T) {
+	var calls atomic.Int32
+	expirer := &expirerMock{
+		ExpireFn: func(_ context.Context, now time.Time) (int, error) {
+			assert.WithinDuration(t, time.Now(), now, time.Second)
+			if calls.Add(1) == 1 {
+				return 0, fmt.Errorf("unable to expire")
+			}
+			return 1, nil
+		},
+	}
+
+	s, err := Sweeper(SweeperConfig{Interval: 10 * time.Millisecond}, expirer)
+	require.NoError(t, err)
+	defer s.Close()
+
+	// The sweep goes on after a failure.
+	assert.Eventually(t, func() bool {
+		return calls.Load() >= 2
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,148 @@
+package shorturl
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/pkg/aesgcm"
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/caarlos0/env/v6"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxPasswordLength is the maximum length of the password in bytes, bcrypt ignores the rest.
+const maxPasswordLength = 72
+
+// passwordCost is the bcrypt cost of the password hashes.
+var passwordCost = bcrypt.DefaultCost
+
+// hashPassword returns the bcrypt hash of the password, the empty password is not hashed.
+func hashPassword(password string) (string, error) {
+	if len(password) == 0 {
+		return "", nil
+	}
+	if len(password) > maxPasswordLength {
+		return "", fmt.Errorf("%w: longer than %d bytes", ErrInvalidPassword, maxPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// UnlockConfig represents the configuration of unlocking the password-protected URLs.
+type UnlockConfig struct {
+	// Key is the key the unlock tokens are sealed with. A random key is used if it is empty,
+	// the issued tokens do not survive a restart then.
+	Key string `env:"URL_UNLOCK_KEY" envDefault:""`
+
+	// TTL is the lifetime of the unlock token.
+	TTL time.Duration `env:"URL_UNLOCK_TTL" envDefault:"10m"`
+}
+
+// Empty checks on being empty.
+func (c UnlockConfig) Empty() bool {
+	return len(c.Key) == 0 &&
+		c.TTL == 0
+}
+
+// defaultUnlockTTL is the unlock token lifetime if none is configured.
+const defaultUnlockTTL = 10 * time.Minute
+
+// ErrWrongPassword is returned if the password does not match the protected URL.
+var ErrWrongPassword = errors.New("wrong password")
+
+// unlocker unlocks the password-protected shortened URLs. The unlocked URL is
+// granted the unlock token, the token is sealed by AES-GCM and bound to the slug.
+type unlocker struct {
+	key aesgcm.Key256
+	ttl time.Duration
+}
+
+// Unlocker returns a new unlocker.
+func Unlocker(cfg UnlockConfig) (*unlocker, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("negative unlock ttl")
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = defaultUnlockTTL
+	}
+
+	u := &unlocker{ttl: cfg.TTL}
+	if len(cfg.Key) != 0 {
+		u.key = aesgcm.HashKey256(cfg.Key)
+		return u, nil
+	}
+	if _, err := rand.Read(u.key[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate unlock key: %v", err)
+	}
+	return u, nil
+}
+
+// TTL returns the lifetime of the unlock token.
+func (u *unlocker) TTL() time.Duration {
+	return u.ttl
+}
+
+// Unlock checks the password of the protected URL and returns a new unlock token
+// of the URL. ErrWrongPassword is returned if the password does not match.
+func (u *unlocker) Unlock(url models.ShortenedURL, password string) (string, error) {
+	if !url.Protected() {
+		return "", fmt.Errorf("shortened URL is not protected")
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(url.PasswordHash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return "", ErrWrongPassword
+		}
+		return "", err
+	}
+
+	// The token is sealed in the format "{slug}:{expiry unix time}".
+	expiresAt := time.Now().Add(u.ttl).Unix()
+	sealed, err := aesgcm.Seal(url.Slug+":"+strconv.FormatInt(expiresAt, 10), u.key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Unlocked checks whether the token unlocks the URL. The unprotected URL
+// is always unlocked.
+func (u *unlocker) Unlocked(url models.ShortenedURL, token string) bool {
+	if !url.Protected() {
+		return true
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return false
+	}
+	plaintext, err := aesgcm.Open(string(sealed), u.key)
+	if err != nil {
+		return false
+	}
+
+	slug, expiry, ok := strings.Cut(string(plaintext), ":")
+	if !ok || slug != url.Slug {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() < expiresAt
+}
@@ -0,0 +1,139 @@
+package shorturl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func protectedURL(t *testing.T, slug, password string) models.ShortenedURL {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	url := models.NewShortenedURL("1", "1", "http://demo.com/doc", slug, "http://localhost:8080/"+slug)
+	url.PasswordHash = string(hash)
+	return url
+}
+
+func TestUnlocker_Unlock(t *testing.T) {
+	u, err := Unlocker(UnlockConfig{Key: "key", TTL: time.Minute})
+	require.NoError(t, err)
+
+	docs := protectedURL(t, "docs", "secret")
+	other := protectedURL(t, "other", "secret")
+
+	tests := []struct {
+		name     string
+		url      models.ShortenedURL
+		password string
+		err      error
+	}{
+		{
+			name:     "Right password",
+			url:      docs,
+			password: "secret",
+		},
+		{
+			name:     "Wrong password",
+			url:      docs,
+			password: "guess",
+			err:      ErrWrongPassword,
+		},
+		{
+			name:     "Empty password",
+			url:      docs,
+			password: "",
+			err:      ErrWrongPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := u.Unlock(tt.url, tt.password)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Empty(t, token)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, u.Unlocked(tt.url, token))
+			// The token is bound to the slug.
+			assert.False(t, u.Unlocked(other, token))
+		})
+	}
+
+	_, err = u.Unlock(models.NewShortenedURL("1", "1", "http://demo.com", "open", ""), "secret")
+	assert.Error(t, err)
+}
+
+func TestUnlocker_Unlocked(t *testing.T) {
+	u, err := Unlocker(UnlockConfig{Key: "key", TTL: time.Minute})
+	require.NoError(t, err)
+	_, err = Unlocker(UnlockConfig{Key: "key", TTL: -time.Minute})
+	require.Error(t, err)
+
+	docs := protectedURL(t, "docs", "secret")
+	token, err := u.Unlock(docs, "secret")
+	require.NoError(t, err)
+
+	// The token of another key is not valid.
+	another, err := Unlocker(UnlockConfig{TTL: time.Minute})
+	require.NoError(t, err)
+	anotherToken, err := another.Unlock(docs, "secret")
+	require.NoError(t, err)
+
+	// The token is expired after TTL.
+	short, err := Unlocker(UnlockConfig{Key: "key", TTL: time.Nanosecond})
+	require.NoError(t, err)
+	shortToken, err := short.Unlock(docs, "secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		url   models.ShortenedURL
+		token string
+		want  bool
+	}{
+		{
+			name:  "Valid token",
+			url:   docs,
+			token: token,
+			want:  true,
+		},
+		{
+			name: "Unprotected URL",
+			url:  models.NewShortenedURL("1", "1", "http://demo.com", "open", ""),
+			want: true,
+		},
+		{
+			name: "No token",
+			url:  docs,
+		},
+		{
+			name:  "Invalid token",
+			url:   docs,
+			token: "invalid",
+		},
+		{
+			name:  "Token of another key",
+			url:   docs,
+			token: anotherToken,
+		},
+		{
+			name:  "Expired token",
+			url:   docs,
+			token: shortToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, u.Unlocked(tt.url, tt.token))
+		})
+	}
+}
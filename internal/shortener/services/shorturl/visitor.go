@@ -0,0 +1,41 @@
+package shorturl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+)
+
+// urlVisitor defines the visits counter of the shortened URLs.
+type urlVisitor interface {
+	Visit(context.Context, string) (int, error)
+}
+
+// ErrVisitsExhausted is returned for the visit-limited URL with no visits left.
+var ErrVisitsExhausted = errors.New("no visits left")
+
+// visitor counts the visits of the visit-limited shortened URLs.
+type visitor struct {
+	visits urlVisitor
+}
+
+// Visitor returns a new visitor in front of the storage visits counter.
+func Visitor(v urlVisitor) (*visitor, error) {
+	if v == nil {
+		return nil, fmt.Errorf("url visitor is nil")
+	}
+	return &visitor{visits: v}, nil
+}
+
+// Visit counts a visit of the URL by slug and returns the number of the visits left,
+// -1 is returned for the URL that is not visit-limited. ErrVisitsExhausted is returned
+// if no visits are left.
+func (v *visitor) Visit(ctx context.Context, slug string) (int, error) {
+	left, err := v.visits.Visit(ctx, slug)
+	if errors.Is(err, shortenedurl.ErrVisitsExhausted) {
+		return 0, ErrVisitsExhausted
+	}
+	return left, err
+}
@@ -0,0 +1,76 @@
+package shorturl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type visitorMock struct {
+	VisitFn func(context.Context, string) (int, error)
+}
+
+func (m *visitorMock) Visit(ctx context.Context, slug string) (int, error) {
+	if m != nil && m.VisitFn != nil {
+		return m.VisitFn(ctx, slug)
+	}
+	return 0, fmt.Errorf("unable to visit")
+}
+
+func TestVisitor_Visit(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  int
+		err   error
+		want  int
+		wantE error
+	}{
+		{
+			name: "Visit is counted",
+			left: 2,
+			want: 2,
+		},
+		{
+			name: "Unlimited URL",
+			left: -1,
+			want: -1,
+		},
+		{
+			name:  "No visits left",
+			err:   shortenedurl.ErrVisitsExhausted,
+			wantE: ErrVisitsExhausted,
+		},
+		{
+			name:  "Storage failure",
+			err:   fmt.Errorf("unable to visit"),
+			wantE: fmt.Errorf("unable to visit"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := Visitor(&visitorMock{
+				VisitFn: func(_ context.Context, slug string) (int, error) {
+					assert.Equal(t, "slug", slug)
+					return tt.left, tt.err
+				},
+			})
+			require.NoError(t, err)
+
+			got, err := v.Visit(context.TODO(), "slug")
+			if tt.wantE != nil {
+				assert.Equal(t, tt.wantE, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Visitor(nil)
+	assert.Error(t, err)
+}
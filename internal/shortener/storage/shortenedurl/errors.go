@@ -4,3 +4,18 @@ import "errors"
 
 // UniqueViolation while saving a new shortened URL.
 var ErrUniqueViolation = errors.New("find shortened URL with the same raw URL")
+
+// SlugCollision while saving a new shortened URL with the slug that is already taken.
+var ErrSlugCollision = errors.New("find shortened URL with the same slug")
+
+// VisitsExhausted while visiting the visit-limited shortened URL with no visits left.
+var ErrVisitsExhausted = errors.New("no visits left for shortened URL")
+
+// NotFound while changing the shortened URL that is not found or has been deleted.
+var ErrNotFound = errors.New("shortened URL not found")
+
+// NotOwner while changing the shortened URL of another user.
+var ErrNotOwner = errors.New("shortened URL of another user")
+
+// VersionConflict while changing the shortened URL that has been changed since the expected version.
+var ErrVersionConflict = errors.New("shortened URL has been changed")
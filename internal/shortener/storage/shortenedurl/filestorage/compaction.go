@@ -0,0 +1,173 @@
+package filestorage
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
+)
+
+// compactionStat represents the result of a single compaction.
+type compactionStat struct {
+	segments   int
+	recordsIn  int
+	recordsOut int
+	bytesIn    int64
+	bytesOut   int64
+	took       time.Duration
+}
+
+// compactor compacts the sealed segments periodically until the storage is closed.
+func (fs *fileStorage) compactor(interval time.Duration) {
+	defer fs.wg.Done()
+
+	logger := zerologx.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fs.done:
+			return
+		case <-ticker.C:
+			stat, err := fs.compact()
+			if err != nil {
+				logger.Error().Err(err).Msg("filestorage: compaction failed")
+				continue
+			}
+			if stat.segments == 0 {
+				continue
+			}
+			logger.Info().
+				Int("segments", stat.segments).
+				Int("records_in", stat.recordsIn).
+				Int("records_out", stat.recordsOut).
+				Int64("bytes_in", stat.bytesIn).
+				Int64("bytes_out", stat.bytesOut).
+				Dur("took", stat.took).
+				Msg("filestorage: compaction done")
+		}
+	}
+}
+
+// compact merges the sealed segments into a single one. Obsolete entries,
+// such as applied tombstones, are dropped.
+//
+// The sealed segments are immutable, so they are merged without blocking the storage.
+// The merged segment is committed by renaming it with mergedSuffix, so an interrupted
+// swap is finished by recoverSegments on the next start. The merged segment is opened
+// and swapped in before the compacted files are removed.
+func (fs *fileStorage) compact() (compactionStat, error) {
+	start := time.Now()
+
+	fs.mtx.RLock()
+	sealed := make([]*segment, len(fs.segments)-1)
+	copy(sealed, fs.segments)
+	fs.mtx.RUnlock()
+
+	if len(sealed) == 0 || (len(sealed) == 1 && sealed[0].compacted) {
+		return compactionStat{}, nil
+	}
+
+	stat := compactionStat{segments: len(sealed)}
+
+	var entries []logEntry
+	for _, s := range sealed {
+		scan, err := readSegment(s.path)
+		if err != nil {
+			return compactionStat{}, fmt.Errorf("read segment %v: %v", s.path, err)
+		}
+		entries = append(entries, scan.entries...)
+		stat.bytesIn += scan.fileSize
+	}
+	// The edits follow the records, so the records are the leading entries
+	// of the merged segment and the edits are applied to them on reading.
+	records, edits := replay(entries)
+	stat.recordsIn = len(entries)
+	stat.recordsOut = len(records) + len(edits)
+
+	last := sealed[len(sealed)-1].seq
+	path := segmentPath(fs.base, last)
+
+	locs, err := writeSegment(path+tmpSuffix, last, append(records, edits...))
+	if err != nil {
+		return compactionStat{}, fmt.Errorf("write merged segment: %v", err)
+	}
+	info, err := os.Stat(path + tmpSuffix)
+	if err != nil {
+		return compactionStat{}, err
+	}
+	stat.bytesOut = info.Size()
+
+	// Commit the merged segment.
+	merged := &segment{seq: last, path: path + mergedSuffix, compacted: true}
+	if err = os.Rename(path+tmpSuffix, merged.path); err != nil {
+		return compactionStat{}, err
+	}
+	f, err := os.Open(merged.path)
+	if err != nil {
+		return compactionStat{}, err
+	}
+	merged.r = newMsgpReader(f)
+
+	// Swap in the merged segment.
+	fs.mtx.Lock()
+	prev := fs.segments
+	segments := make([]*segment, 0, len(fs.segments)-len(sealed)+1)
+	segments = append(segments, merged)
+	fs.segments = append(segments, fs.segments[len(sealed):]...)
+
+	// Point the index to the merged segment. The index is rebuilt
+	// if the records were reordered, the expired marks are kept.
+	if !fs.idx.relocate(locs[:len(records)], last) {
+		old := fs.idx
+		if err = fs.buildIndex(); err != nil {
+			fs.segments = prev
+			fs.mtx.Unlock()
+			merged.r.Close()
+			return compactionStat{}, err
+		}
+		fs.idx.keepExpired(old)
+	}
+
+	fs.removeSegments(sealed, merged)
+	fs.mtx.Unlock()
+
+	stat.took = time.Since(start)
+	return stat, nil
+}
+
+// removeSegments closes and removes the compacted segments, then moves the merged
+// segment to its own path. The caller must hold fs.mtx.
+//
+// The compaction is already swapped in, so the errors are logged only.
+// The merged segment is left committed if any old segment stays, since
+// recoverSegments finishes the swap on the next start.
+func (fs *fileStorage) removeSegments(sealed []*segment, merged *segment) {
+	logger := zerologx.Get()
+
+	removed := true
+	for _, s := range sealed {
+		if err := s.r.Close(); err != nil {
+			logger.Error().Err(err).Str("segment", s.path).Msg("filestorage: failed to close compacted segment")
+		}
+		if s.seq == merged.seq {
+			continue
+		}
+		if err := os.Remove(s.path); err != nil {
+			logger.Error().Err(err).Str("segment", s.path).Msg("filestorage: failed to remove compacted segment")
+			removed = false
+		}
+	}
+	if !removed {
+		return
+	}
+
+	path := segmentPath(fs.base, merged.seq)
+	if err := os.Rename(merged.path, path); err != nil {
+		logger.Error().Err(err).Str("segment", merged.path).Msg("filestorage: failed to rename merged segment")
+		return
+	}
+	merged.path = path
+}
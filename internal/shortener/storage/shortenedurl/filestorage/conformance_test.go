@@ -0,0 +1,43 @@
+package filestorage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	for _, scope := range []shortenedurl.Scope{shortenedurl.ScopeUser, shortenedurl.ScopeGlobal} {
+		t.Run(string(scope), func(t *testing.T) {
+			closed := make(map[*fileStorage]bool)
+			open := func(t *testing.T, path string) *fileStorage {
+				r, err := FileStorage(Config{
+					Path:  path,
+					Scope: scope,
+				})
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					if !closed[r] {
+						require.NoError(t, r.Close())
+					}
+				})
+				return r
+			}
+
+			storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+				return open(t, filepath.Join(t.TempDir(), "test_conformance"))
+			}, storagetest.Options{
+				Scope: scope,
+				Reopen: func(t *testing.T, s storagetest.Storage) storagetest.Storage {
+					r := s.(*fileStorage)
+					require.NoError(t, r.Close())
+					closed[r] = true
+					return open(t, r.base)
+				},
+			})
+		})
+	}
+}
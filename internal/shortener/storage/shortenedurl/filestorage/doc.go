@@ -6,7 +6,7 @@ for manipulating data:
 
 	shortURL, err := repo.GetBySlug(ctx, "slug")
 	...
-	shortURL, err := repo.GetByURL(ctx, "url")
+	shortURL, err := repo.GetByURL(ctx, "userID", "url")
 	...
 	shortURLs, err := repo.CollectByUser(ctx, "userID")
 	...
@@ -17,10 +17,16 @@ for manipulating data:
 	err := repo.Delete("userID", []string)
 	...
 
-For creation a new FileRepo the caller should pass filepath.
-If filepath is empty, then it will be set from env param:
+For creation a new FileStorage the caller should pass the config.
+If the config is empty, then it will be set from env params:
 
-	repo, err := New(filepath)
+	repo, err := FileStorage(Config{Path: filepath})
+
+The URLs are unique per user by their canonical form by default, the records
+written before the canonical form was introduced are deduplicated by the raw URL.
+With the global scope a URL can be shortened only once across all users:
+
+	repo, err := FileStorage(Config{Path: filepath, Scope: shortenedurl.ScopeGlobal})
 
 The caller must close the repository when finished with it:
 
@@ -31,5 +37,90 @@ The caller must close the repository when finished with it:
 To be able to write and read to a file, interfaces are defined in various ways: writer, reader.
 
 The current implementation uses the MessagePack format for encoding data.
+
+# Deletion
+
+The file is an append-only log, so records are never changed in place.
+Delete appends a tombstone for each slug owned by the user. The reader applies
+tombstones in the order they were written, so GetBySlug returns the record
+with IsDeleted set to true.
+
+# Expiration
+
+The expiry time is written down with the record. Expire marks the records expired
+by the time in the index only, so they are skipped by CollectByUser. The marks are
+not written down, the opened storage marks the records again on the next Expire.
+Compaction keeps the marks.
+
+# Visits
+
+The maximum number of visits is written down with the record. Visit appends
+a counter holding the number of the counted visits of the slug, the reader applies
+the last counter of the slug. Compaction folds the counters into the records.
+
+# Editing
+
+Edit appends an edit entry holding the destination change of the slug. The index
+applies the edits in the order they were written: the edited record is read with
+the last destination and looked up by it, the edits of the slug make up its history.
+Compaction keeps the edits after the merged records.
+
+# Counter slugs
+
+Next appends a sequence entry holding the handed out value of the counter slug
+sequence. The opened storage continues the sequence after the greatest written value,
+so the values used up by duplicates and deleted URLs are never handed out again.
+Compaction keeps only the greatest sequence entry.
+
+# Segments and compaction
+
+The log is split into segment files named by the base path and the sequence number:
+
+	file.db.000001
+	file.db.000002
+
+Only the last segment is active and written to. It is sealed when its size reaches
+Config.SegmentSize and a new segment is started. A single file of the older versions
+found at the base path is adopted as the first segment.
+
+Every Config.CompactionInterval the sealed segments are merged into one in background.
+Entries made obsolete by later ones, such as applied tombstones, are dropped.
+The merged segment is written to a temporary file and committed by renaming,
+so an interrupted compaction is either discarded or finished on the next start.
+
+# Index
+
+The index is built once at start by replaying the log. It maps slugs,
+raw URLs and users to the record locations and is updated by every write,
+so lookups are served from memory and only the record itself is read from the disk.
+
+# Framing and durability
+
+Each entry is written down as a frame prefixed with the payload length and its CRC32.
+At start the segments are scanned frame by frame: a frame with a mismatched CRC
+is logged and skipped, an incomplete frame at the end of the segment is a torn
+write of a crash, so the tail is truncated.
+
+Batch writes all its entries down as a single frame holding a msgp array of them,
+so after a crash the batch is either read back as a whole or dropped with the torn tail.
+
+Config.Sync sets the fsync policy of the writes:
+
+	always   - every write is synced before it returns;
+	interval - the active segment is synced every Config.SyncInterval;
+	never    - flushing is left to the OS.
+
+# Format versions
+
+Each segment starts with the header holding the magic bytes and the format version:
+
+	v0 - plain msgp entries without the header;
+	v1 - framed entries without the header;
+	v2 - the header followed by framed entries.
+
+Segments of the older versions are still readable, but new entries are never
+appended to them. Upgrade rewrites them into the current format once, the same
+is done at start if Config.Upgrade is set. Entries are msgp maps keyed by the field
+names, so fields added to ShortenedURL are zero in the entries written down before.
 */
 package filestorage
@@ -7,185 +7,690 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 	"github.com/caarlos0/env/v6"
 )
 
-// config represents the file storage configuration.
-type config struct {
-	URI string
+// Config represents the file storage configuration.
+type Config struct {
+	// Path is the base path of the log segments.
+	Path string `env:"FILE_STORAGE_PATH"`
+
+	// SegmentSize is the size in bytes after which the active segment is sealed
+	// and a new one is started.
+	SegmentSize int64 `env:"FILE_STORAGE_SEGMENT_SIZE" envDefault:"4194304"`
+
+	// CompactionInterval is the interval between compactions of the sealed segments.
+	CompactionInterval time.Duration `env:"FILE_STORAGE_COMPACTION_INTERVAL" envDefault:"1m"`
+
+	// Sync is the fsync policy of the writes, such as always, interval or never.
+	Sync string `env:"FILE_STORAGE_SYNC" envDefault:"never"`
+
+	// SyncInterval is the interval between fsyncs for the interval policy.
+	SyncInterval time.Duration `env:"FILE_STORAGE_SYNC_INTERVAL" envDefault:"1s"`
+
+	// Upgrade rewrites the segments of the older format versions at start.
+	Upgrade bool `env:"FILE_STORAGE_UPGRADE" envDefault:"false"`
+
+	// Scope is the scope of the raw URL uniqueness.
+	Scope shortenedurl.Scope `env:"URL_UNIQUE_SCOPE" envDefault:"user"`
+}
+
+// Empty checks on being empty.
+func (c Config) Empty() bool {
+	return len(c.Path) == 0 &&
+		c.SegmentSize == 0 &&
+		c.CompactionInterval == 0 &&
+		len(c.Sync) == 0 &&
+		c.SyncInterval == 0 &&
+		!c.Upgrade &&
+		len(c.Scope) == 0
 }
 
+// Fsync policies.
+const (
+	// SyncAlways syncs the active segment after every write.
+	SyncAlways = "always"
+	// SyncInterval syncs the active segment periodically.
+	SyncInterval = "interval"
+	// SyncNever leaves flushing to the OS.
+	SyncNever = "never"
+)
+
+// Default file storage settings.
+const (
+	defaultSegmentSize        = 4 << 20
+	defaultCompactionInterval = time.Minute
+	defaultSync               = SyncNever
+	defaultSyncInterval       = time.Second
+)
+
 // fileStorage defines the shortenedURL file storage.
+//
+// The storage is an append-only log split into segments. Only the last
+// segment is active and written to, the others are sealed and compacted in background.
 type fileStorage struct {
-	w   writer
-	r   reader
-	mtx sync.Mutex
+	base        string
+	segmentSize int64
+	segments    []*segment
+	w           writer
+	idx         *index
+	mtx         sync.RWMutex
+
+	sync  string
+	dirty bool
+
+	scope shortenedurl.Scope
+
+	// seq is the last value of the counter slug sequence. The caller must hold mtx.
+	seq uint64
+
+	done chan struct{}
+	wg   sync.WaitGroup
 }
 
-// New returns a new fileStorage for shortenedURLs.
-func FileStorage(path string) (*fileStorage, error) {
-	if len(path) == 0 {
-		var cfg config
+// FileStorage returns a new fileStorage for shortenedURLs.
+func FileStorage(cfg Config) (*fileStorage, error) {
+	if cfg.Empty() {
 		opts := env.Options{RequiredIfNoDef: true}
-		err := env.Parse(&cfg, opts)
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if len(cfg.Path) == 0 {
+		return nil, fmt.Errorf("empty file storage path")
+	}
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = defaultSegmentSize
+	}
+	if cfg.CompactionInterval <= 0 {
+		cfg.CompactionInterval = defaultCompactionInterval
+	}
+	if len(cfg.Sync) == 0 {
+		cfg.Sync = defaultSync
+	}
+	if cfg.SyncInterval <= 0 {
+		cfg.SyncInterval = defaultSyncInterval
+	}
+	switch cfg.Sync {
+	case SyncAlways, SyncInterval, SyncNever:
+	default:
+		return nil, fmt.Errorf("unknown sync policy: %v", cfg.Sync)
+	}
+	scope, err := shortenedurl.ParseScope(string(cfg.Scope))
+	if err != nil {
+		return nil, err
+	}
+
+	seqs, err := recoverSegments(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover segments: %v", err)
+	}
+	if cfg.Upgrade {
+		if _, err = upgradeSegments(cfg.Path, seqs); err != nil {
+			return nil, fmt.Errorf("failed to upgrade segments: %v", err)
+		}
+	}
+	if len(seqs) == 0 {
+		seqs = append(seqs, 1)
+	}
+
+	fs := &fileStorage{
+		base:        cfg.Path,
+		segmentSize: cfg.SegmentSize,
+		sync:        cfg.Sync,
+		scope:       scope,
+		done:        make(chan struct{}),
+	}
+	for _, seq := range seqs {
+		s, err := openSegment(cfg.Path, seq)
 		if err != nil {
-			return nil, fmt.Errorf("failed to set fileStorage")
+			fs.closeSegments()
+			return nil, err
+		}
+		fs.segments = append(fs.segments, s)
+	}
+
+	if err = fs.buildIndex(); err != nil {
+		fs.closeSegments()
+		return nil, fmt.Errorf("failed to build index: %v", err)
+	}
+	// The storages written before the sequence entries continue after their records.
+	fs.seq = fs.idx.seq
+	if n := uint64(fs.idx.records()); n > fs.seq {
+		fs.seq = n
+	}
+
+	if fs.w, err = openSegmentWriter(fs.active().path); err != nil {
+		fs.closeSegments()
+		return nil, err
+	}
+
+	// Entries must not be appended to the segments of the older versions.
+	if fs.active().version != currentFormat {
+		if err = fs.rotate(); err != nil {
+			fs.w.Close()
+			fs.closeSegments()
+			return nil, err
 		}
-		path = cfg.URI
 	}
 
-	fw, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	fs.wg.Add(1)
+	go fs.compactor(cfg.CompactionInterval)
+
+	if fs.sync == SyncInterval {
+		fs.wg.Add(1)
+		go fs.syncer(cfg.SyncInterval)
+	}
+
+	return fs, nil
+}
+
+// openSegmentWriter opens the segment file for appending.
+func openSegmentWriter(path string) (writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, err
 	}
-	fr, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+
+	w, err := newMsgpWriter(f)
 	if err != nil {
+		f.Close()
 		return nil, err
 	}
+	return w, nil
+}
 
-	return &fileStorage{
-		w: newMsgpWriter(fw),
-		r: newMsgpReader(fr),
-	}, nil
+// active returns the active segment.
+func (fs *fileStorage) active() *segment {
+	return fs.segments[len(fs.segments)-1]
 }
 
-// GetBySlug finds the shortURL by slug.
-func (fs *fileStorage) GetBySlug(_ context.Context, slug string) (models.ShortenedURL, error) {
-	fs.mtx.Lock()
-	var records []ShortenedURL
-	records, err := fs.r.List()
-	fs.mtx.Unlock()
+// buildIndex builds the index by replaying all segments. Torn tails of the segments
+// are truncated, corrupt entries are skipped. The caller must hold fs.mtx.
+func (fs *fileStorage) buildIndex() error {
+	logger := zerologx.Get()
 
-	if err != nil {
-		return models.ShortenedURL{}, err
+	idx := newIndex()
+	for _, s := range fs.segments {
+		scan, err := s.r.Scan()
+		if err != nil {
+			return fmt.Errorf("segment %v: %v", s.path, err)
+		}
+		s.version = scan.version
+
+		if scan.skipped > 0 {
+			logger.Warn().
+				Str("segment", s.path).
+				Int("skipped", scan.skipped).
+				Msg("filestorage: corrupt entries skipped")
+		}
+		if scan.torn() {
+			logger.Warn().
+				Str("segment", s.path).
+				Int64("offset", scan.size).
+				Int64("bytes", scan.fileSize-scan.size).
+				Msg("filestorage: torn tail truncated")
+			if err = os.Truncate(s.path, scan.size); err != nil {
+				return fmt.Errorf("truncate segment %v: %v", s.path, err)
+			}
+		}
+
+		for _, e := range scan.entries {
+			idx.apply(e.ShortenedURL, location{seq: s.seq, offset: e.offset, size: e.size})
+		}
 	}
+	fs.idx = idx
+	return nil
+}
 
-	for _, r := range records {
-		if r.Slug == slug {
-			return r.ToModel(), nil
+// read reads the indexed record from the log. The caller must hold fs.mtx.
+func (fs *fileStorage) read(e indexEntry) (models.ShortenedURL, error) {
+	for _, s := range fs.segments {
+		if s.seq != e.loc.seq {
+			continue
 		}
+
+		record, err := s.r.ReadAt(e.loc.offset, e.loc.size)
+		if err != nil {
+			return models.ShortenedURL{}, err
+		}
+		record.IsDeleted = e.deleted
+		record.Visits = e.visits
+		if e.edited {
+			record.Raw = e.raw
+			record.Canonical = e.canonical
+		}
+		m := record.ToModel()
+		m.IsExpired = e.expired
+		return m, nil
 	}
+	return models.ShortenedURL{}, fmt.Errorf("segment %d not found", e.loc.seq)
+}
 
-	return models.ShortenedURL{}, nil
+// write appends the entry to the active segment and applies it to the index.
+// The active segment is sealed when it reaches the segment size. The caller must hold fs.mtx.
+func (fs *fileStorage) write(entry ShortenedURL) error {
+	if err := fs.prepareWrite(); err != nil {
+		return err
+	}
+
+	ext, err := fs.w.Write(entry)
+	if err != nil {
+		return err
+	}
+	if err = fs.commitWrite(); err != nil {
+		return err
+	}
+
+	fs.idx.apply(entry, fs.locate(ext))
+	return nil
 }
 
-// GetByURL finds the shortURL by original URL.
-func (fs *fileStorage) GetByURL(_ context.Context, url string) (models.ShortenedURL, error) {
-	fs.mtx.Lock()
-	var records []ShortenedURL
-	records, err := fs.r.List()
-	fs.mtx.Unlock()
+// writeBatch appends the entries to the active segment as a single frame and applies
+// them to the index. The entries are applied only if the whole frame is written down.
+// The caller must hold fs.mtx.
+func (fs *fileStorage) writeBatch(entries []ShortenedURL) error {
+	if err := fs.prepareWrite(); err != nil {
+		return err
+	}
 
+	exts, err := fs.w.WriteBatch(entries)
 	if err != nil {
-		return models.ShortenedURL{}, err
+		return err
 	}
+	if err = fs.commitWrite(); err != nil {
+		return err
+	}
+
+	for i, e := range entries {
+		fs.idx.apply(e, fs.locate(exts[i]))
+	}
+	return nil
+}
+
+// prepareWrite seals the active segment if it reaches the segment size. A segment
+// holding the header only is never sealed. The caller must hold fs.mtx.
+func (fs *fileStorage) prepareWrite() error {
+	if size := fs.w.Size(); size > segmentHeaderSize && size >= fs.segmentSize {
+		return fs.rotate()
+	}
+	return nil
+}
+
+// commitWrite syncs the written entries according to the sync policy. The caller must hold fs.mtx.
+func (fs *fileStorage) commitWrite() error {
+	fs.dirty = true
+	if fs.sync == SyncAlways {
+		return fs.flush()
+	}
+	return nil
+}
 
-	for _, r := range records {
-		if r.Raw == url {
-			return r.ToModel(), nil
+// locate returns the location of the extent in the active segment.
+func (fs *fileStorage) locate(ext extent) location {
+	return location{
+		seq:    fs.active().seq,
+		offset: ext.offset,
+		size:   ext.size,
+	}
+}
+
+// flush syncs the active segment if it has unsynced writes. The caller must hold fs.mtx.
+func (fs *fileStorage) flush() error {
+	if !fs.dirty {
+		return nil
+	}
+	if err := fs.w.Sync(); err != nil {
+		return err
+	}
+	fs.dirty = false
+	return nil
+}
+
+// syncer syncs the active segment periodically until the storage is closed.
+func (fs *fileStorage) syncer(interval time.Duration) {
+	defer fs.wg.Done()
+
+	logger := zerologx.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fs.done:
+			return
+		case <-ticker.C:
+			fs.mtx.Lock()
+			err := fs.flush()
+			fs.mtx.Unlock()
+			if err != nil {
+				logger.Error().Err(err).Msg("filestorage: sync failed")
+			}
 		}
 	}
+}
+
+// rotate seals the active segment and starts a new one. The caller must hold fs.mtx.
+func (fs *fileStorage) rotate() error {
+	next, err := openSegment(fs.base, fs.active().seq+1)
+	if err != nil {
+		return err
+	}
+
+	w, err := openSegmentWriter(next.path)
+	if err != nil {
+		next.r.Close()
+		return err
+	}
+	if fs.sync != SyncNever {
+		if err = fs.flush(); err != nil {
+			w.Close()
+			next.r.Close()
+			return err
+		}
+	}
+	if err = fs.w.Close(); err != nil {
+		w.Close()
+		next.r.Close()
+		return err
+	}
+
+	fs.w = w
+	fs.dirty = false
+	fs.segments = append(fs.segments, next)
+	return nil
+}
+
+// GetBySlug finds the shortURL by slug.
+func (fs *fileStorage) GetBySlug(_ context.Context, slug string) (models.ShortenedURL, error) {
+	fs.mtx.RLock()
+	defer fs.mtx.RUnlock()
+
+	if e, ok := fs.idx.getBySlug(slug); ok {
+		return fs.read(e)
+	}
+	return models.ShortenedURL{}, nil
+}
+
+// GetByURL finds the shortURL by the canonical form of the original URL,
+// the records without one are looked up by the original URL. In the user scope only
+// the user URLs are looked up, in the global scope the URL of any user is returned.
+func (fs *fileStorage) GetByURL(_ context.Context, userID, url string) (models.ShortenedURL, error) {
+	fs.mtx.RLock()
+	defer fs.mtx.RUnlock()
+
+	if e, ok := fs.idx.getByURL(fs.scope, userID, url); ok {
+		return fs.read(e)
+	}
 	return models.ShortenedURL{}, nil
 }
 
-// Collect finds all user shortenedURLs.
+// Collect finds all user shortenedURLs, the expired ones are skipped.
 func (fs *fileStorage) CollectByUser(_ context.Context, userID string) ([]models.ShortenedURL, error) {
 	if len(userID) == 0 {
 		return nil, errors.New("userID is empty")
 	}
 
+	fs.mtx.RLock()
+	defer fs.mtx.RUnlock()
+
+	entries := fs.idx.collectByUser(userID)
+	results := make([]models.ShortenedURL, len(entries))
+	for i, e := range entries {
+		r, err := fs.read(e)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = r
+	}
+	return results, nil
+}
+
+// Save saves a new shortURL. The URL is unique in the storage scope by its dedup key,
+// the taken slug is reported as ErrSlugCollision.
+func (fs *fileStorage) Save(_ context.Context, data models.ShortenedURL) error {
 	fs.mtx.Lock()
-	records, err := fs.r.List()
-	fs.mtx.Unlock()
+	defer fs.mtx.Unlock()
 
-	if err != nil {
-		return nil, err
+	if _, ok := fs.idx.getByURL(fs.scope, data.UserID, data.DedupKey()); ok {
+		return shortenedurl.ErrUniqueViolation
+	}
+	if _, ok := fs.idx.getBySlug(data.Slug); ok {
+		return shortenedurl.ErrSlugCollision
+	}
+	return fs.write(newShortenedURL(data))
+}
+
+// Batch saves a list of shortenedURLs atomically: after a failure or a crash
+// either all of them are saved or none. The URLs that conflict with the saved ones
+// or with the preceding URLs of the batch are not saved, the conflicting records
+// are returned for them instead. If any slug of the batch is taken, nothing is saved.
+func (fs *fileStorage) Batch(_ context.Context, records []models.ShortenedURL) ([]models.BatchedURL, error) {
+	if len(records) == 0 {
+		return nil, nil
 	}
 
-	var idxs []int
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	results := make([]models.BatchedURL, len(records))
+	entries := make([]ShortenedURL, 0, len(records))
+	byURL := make(map[string][]int, len(records)) // dedup key: results
+	slugs := make(map[string]bool, len(records))
 	for i, r := range records {
-		if r.UserID == userID {
-			idxs = append(idxs, i)
+		if e, ok := fs.idx.getByURL(fs.scope, r.UserID, r.DedupKey()); ok {
+			existed, err := fs.read(e)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = models.NewBatchedURL(existed, models.BatchExisted)
+			continue
+		}
+
+		conflict := -1
+		for _, j := range byURL[r.DedupKey()] {
+			if conflict < 0 && fs.scope.Conflicts(results[j].UserID, r.UserID) {
+				conflict = j
+			}
+		}
+		if conflict >= 0 {
+			results[i] = models.NewBatchedURL(results[conflict].ShortenedURL, models.BatchExisted)
+			continue
+		}
+
+		if _, ok := fs.idx.getBySlug(r.Slug); ok || slugs[r.Slug] {
+			return nil, shortenedurl.ErrSlugCollision
 		}
+		slugs[r.Slug] = true
+
+		byURL[r.DedupKey()] = append(byURL[r.DedupKey()], i)
+		entries = append(entries, newShortenedURL(r))
+		results[i] = models.NewBatchedURL(r, models.BatchCreated)
+	}
+	if len(entries) == 0 {
+		return results, nil
 	}
 
-	results := make(shortenedURLs, len(idxs))
-	for i, idx := range idxs {
-		results[i] = records[idx]
+	if err := fs.writeBatch(entries); err != nil {
+		return nil, err
 	}
-	return results.ToModel(), nil
+	return results, nil
 }
 
-// Save saves a new shortURL.
-func (fs *fileStorage) Save(_ context.Context, data models.ShortenedURL) error {
+// Delete marks urls as deleted. A tombstone is appended for each slug owned
+// by the user, slugs of other users are skipped.
+func (fs *fileStorage) Delete(userID string, slugs []string) error {
 	fs.mtx.Lock()
-	err := fs.w.Write(newShortenedURL(data))
-	fs.mtx.Unlock()
-	return err
-}
+	defer fs.mtx.Unlock()
 
-// Batch saves a list of shortenedURLs.
-func (fs *fileStorage) Batch(_ context.Context, records []models.ShortenedURL) error {
-	fs.mtx.Lock()
-	for _, r := range records {
-		err := fs.w.Write(newShortenedURL(r))
-		if err != nil {
-			// BUG: No Rollback. Added entries should be removed.
-			return nil
+	for _, slug := range slugs {
+		if !fs.idx.owns(userID, slug) {
+			continue
+		}
+		if err := fs.write(newTombstone(userID, slug)); err != nil {
+			return err
 		}
 	}
-	fs.mtx.Unlock()
 	return nil
 }
 
-// Delete marks urls as deleted.
-func (fs *fileStorage) Delete(userID string, slugs []string) error {
-	// FYI: Do nothing for this version.
-	// Should use the LSM-tree for updating entries in the log segment.
-	return fmt.Errorf("not implemented")
+// Visit counts a visit of the visit-limited URL and returns the number of the visits left.
+// A counter with the number of the counted visits is appended for each visit.
+// ErrVisitsExhausted is returned if no visits are left. The URL that is not found
+// or is not visit-limited is not counted, -1 is returned for it.
+func (fs *fileStorage) Visit(_ context.Context, slug string) (int, error) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	e, ok := fs.idx.getBySlug(slug)
+	if !ok || e.maxVisits <= 0 {
+		return -1, nil
+	}
+	if e.visits >= e.maxVisits {
+		return 0, shortenedurl.ErrVisitsExhausted
+	}
+
+	if err := fs.write(newCounter(slug, e.visits+1)); err != nil {
+		return 0, err
+	}
+	return e.maxVisits - e.visits - 1, nil
 }
 
-// Stat collects statistics about shortened URLs.
-func (fs *fileStorage) Stat(_ context.Context) (models.Stat, error) {
-	var (
-		stat models.Stat
-		err  error
-	)
+// changeable returns the record with the slug that may be changed by the user.
+// ErrNotFound is returned for the record that is not found or has been deleted,
+// ErrNotOwner for the record of another user. The caller must hold fs.mtx.
+func (fs *fileStorage) changeable(userID, slug string) (indexEntry, error) {
+	e, ok := fs.idx.getBySlug(slug)
+	if !ok || e.deleted {
+		return indexEntry{}, shortenedurl.ErrNotFound
+	}
+	if e.userID != userID {
+		return indexEntry{}, shortenedurl.ErrNotOwner
+	}
+	return e, nil
+}
+
+// Edit changes the destination of the user's URL and returns the change with the old
+// destination and the new version set. An edit record holding the change is appended.
+// ErrNotFound is returned for the URL that is not found or has been deleted, ErrNotOwner
+// for the URL of another user. The new destination that conflicts with another URL
+// in the uniqueness scope is reported as ErrUniqueViolation, the change that would
+// not get its set version as ErrVersionConflict.
+func (fs *fileStorage) Edit(_ context.Context, c models.URLChange) (models.URLChange, error) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	e, err := fs.changeable(c.UserID, c.Slug)
+	if err != nil {
+		return models.URLChange{}, err
+	}
+	version := len(fs.idx.history[c.Slug]) + 2
+	if c.Version != 0 && c.Version != version {
+		return models.URLChange{}, shortenedurl.ErrVersionConflict
+	}
+	if existed, ok := fs.idx.getByURL(fs.scope, c.UserID, c.DedupKey()); ok && existed.slug != c.Slug {
+		return models.URLChange{}, shortenedurl.ErrUniqueViolation
+	}
 
-	done := make(chan struct{}, 1)
-	go func() {
-		var records []ShortenedURL
+	r, err := fs.read(e)
+	if err != nil {
+		return models.URLChange{}, err
+	}
+	c.Old = r.Raw
+	c.Version = version
 
-		fs.mtx.Lock()
-		records, err = fs.r.List()
-		fs.mtx.Unlock()
+	if err = fs.write(newEdit(c)); err != nil {
+		return models.URLChange{}, err
+	}
+	return c, nil
+}
 
-		if err != nil {
-			return
-		}
+// History returns the destination changes of the user's URL in the version order.
+// ErrNotFound is returned for the URL that is not found or has been deleted,
+// ErrNotOwner for the URL of another user.
+func (fs *fileStorage) History(_ context.Context, userID, slug string) ([]models.URLChange, error) {
+	fs.mtx.RLock()
+	defer fs.mtx.RUnlock()
 
-		users := make(map[string]struct{})
-		for _, v := range records {
-			users[v.UserID] = struct{}{}
-		}
-		stat.Users = len(users)
-		stat.URLs = len(records)
+	if _, err := fs.changeable(userID, slug); err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.URLChange, len(fs.idx.history[slug]))
+	copy(changes, fs.idx.history[slug])
+	return changes, nil
+}
+
+// Expire marks the URLs expired by the time and returns the number of the marked ones.
+// Nothing is written down, the opened storage marks the URLs again by their expiry time.
+func (fs *fileStorage) Expire(_ context.Context, now time.Time) (int, error) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	return fs.idx.expire(now), nil
+}
+
+// Next returns the next value of the counter slug sequence, the first one is 1.
+// Each value is written down before it is handed out, so the opened storage
+// never hands out the same value again.
+func (fs *fileStorage) Next(_ context.Context) (uint64, error) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	next := fs.seq + 1
+	if err := fs.write(newSequence(next)); err != nil {
+		return 0, err
+	}
+	fs.seq = next
+	return next, nil
+}
+
+// Stat collects statistics about shortened URLs.
+func (fs *fileStorage) Stat(_ context.Context) (models.Stat, error) {
+	fs.mtx.RLock()
+	defer fs.mtx.RUnlock()
 
-		done <- struct{}{}
-	}()
-	<-done
-	return stat, err
+	return models.Stat{
+		URLs:  fs.idx.records(),
+		Users: fs.idx.users(),
+	}, nil
 }
 
-// Close closes the writer and reader.
+// Close stops the compaction and closes the segment files.
 func (fs *fileStorage) Close() error {
-	err1 := fs.w.Close()
-	err2 := fs.r.Close()
+	close(fs.done)
+	fs.wg.Wait()
+
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+
+	var err1 error
+	if fs.sync != SyncNever {
+		err1 = fs.flush()
+	}
+	if err := fs.w.Close(); err1 == nil {
+		err1 = err
+	}
+	err2 := fs.closeSegments()
 
 	if err1 != nil || err2 != nil {
 		return fmt.Errorf("close files - %v; %v", err1, err2)
 	}
 	return nil
 }
+
+// closeSegments closes the segment readers.
+func (fs *fileStorage) closeSegments() error {
+	var err error
+	for _, s := range fs.segments {
+		if closeErr := s.r.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
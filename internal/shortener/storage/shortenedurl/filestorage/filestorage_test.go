@@ -2,9 +2,11 @@ package filestorage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
 )
 
 func TestFileStorage_Save(t *testing.T) {
@@ -301,7 +304,7 @@ func TestFileStorage_GetByURL(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			got, err := r.GetByURL(context.TODO(), tt.wantURL.raw)
+			got, err := r.GetByURL(context.TODO(), tt.wantURL.userID, tt.wantURL.raw)
 			require.NoError(t, err)
 			if !tt.notFound {
 				assert.Equal(t, tt.wantURL.userID, got.UserID)
@@ -354,10 +357,10 @@ func BenchmarkFileRepo_GetByURL(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		url := shortenedURLs[rand.Intn(n)].Raw
+		v := shortenedURLs[rand.Intn(n)]
 		b.StartTimer()
 
-		_, err = repo.GetByURL(context.TODO(), url)
+		_, err = repo.GetByURL(context.TODO(), v.UserID, v.Raw)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -400,7 +403,7 @@ func TestFileRepo_Collect(t *testing.T) {
 					userID: "1",
 					corrID: "3",
 					raw:    "http://demo.com/3",
-					slug:   "slug1",
+					slug:   "slug3",
 				},
 			},
 			existedURLs: []url{
@@ -438,7 +441,7 @@ func TestFileRepo_Collect(t *testing.T) {
 					userID: "1",
 					corrID: "3",
 					raw:    "http://demo.com/3",
-					slug:   "slug1",
+					slug:   "slug3",
 				},
 			},
 		},
@@ -482,7 +485,7 @@ func TestFileRepo_Collect(t *testing.T) {
 					userID: "1",
 					corrID: "3",
 					raw:    "http://demo.com/3",
-					slug:   "slug1",
+					slug:   "slug3",
 				},
 			},
 		},
@@ -627,7 +630,7 @@ func TestFileRepo_Batch(t *testing.T) {
 					userID: "1",
 					corrID: "3",
 					raw:    "http://demo.com/3",
-					slug:   "slug1",
+					slug:   "slug3",
 				},
 			},
 		},
@@ -653,22 +656,162 @@ func TestFileRepo_Batch(t *testing.T) {
 				shotrenedURLs[i] = shortenedURL
 			}
 
-			err = r.Batch(context.TODO(), shotrenedURLs)
+			_, err = r.Batch(context.TODO(), shotrenedURLs)
+			require.NoError(t, err)
 
-			assert.NoError(t, err)
+			for _, v := range shotrenedURLs {
+				got, err := r.GetByURL(context.TODO(), v.UserID, v.Raw)
+				require.NoError(t, err)
+				assert.Equal(t, v, got)
+			}
 		})
 	}
 }
 
-func TestFileRepo_Delete(t *testing.T) {
-	r, close, err := newFileStorage("test_delete")
+type failingWriter struct {
+	writer
+}
+
+func (w failingWriter) WriteBatch([]ShortenedURL) ([]extent, error) {
+	return nil, errors.New("unable to write")
+}
+
+func TestFileRepo_BatchFailure(t *testing.T) {
+	r, close, err := newFileStorage("test_batch_failure")
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, close())
 	}()
 
-	err = r.Delete("0001", nil)
-	require.Error(t, err)
+	w := r.w
+	r.w = failingWriter{writer: w}
+
+	_, err = r.Batch(context.TODO(), []models.ShortenedURL{
+		models.NewShortenedURL("1", "1", "http://demo.com/1", "slug1", "http://127.0.0.1/slug1"),
+		models.NewShortenedURL("1", "2", "http://demo.com/2", "slug2", "http://127.0.0.1/slug2"),
+	})
+	assert.Error(t, err)
+	r.w = w
+
+	// The storage is not locked and the failed batch is not visible.
+	stat, err := r.Stat(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 0, stat.URLs)
+	require.NoError(t, r.Save(context.TODO(), models.NewShortenedURL(
+		"1", "3", "http://demo.com/3", "slug3", "http://127.0.0.1/slug3",
+	)))
+}
+
+func TestFileRepo_Delete(t *testing.T) {
+	type url struct {
+		userID string
+		corrID string
+		raw    string
+		slug   string
+	}
+	tests := []struct {
+		name         string
+		baseURL      string
+		targetUserID string
+		targetSlugs  []string
+		deletedSlugs []string
+		existedURLs  []url
+	}{
+		{
+			name:         "Delete own URLs",
+			baseURL:      "http://127.0.0.1/",
+			targetUserID: "1",
+			targetSlugs:  []string{"slug1", "slug2"},
+			deletedSlugs: []string{"slug1", "slug2"},
+			existedURLs: []url{
+				{
+					userID: "1",
+					corrID: "1",
+					raw:    "http://demo.com/1",
+					slug:   "slug1",
+				},
+				{
+					userID: "1",
+					corrID: "2",
+					raw:    "http://demo.com/2",
+					slug:   "slug2",
+				},
+				{
+					userID: "1",
+					corrID: "3",
+					raw:    "http://demo.com/3",
+					slug:   "slug3",
+				},
+			},
+		},
+		{
+			name:         "Delete URLs of another user",
+			baseURL:      "http://127.0.0.1/",
+			targetUserID: "2",
+			targetSlugs:  []string{"slug1", "slug2"},
+			existedURLs: []url{
+				{
+					userID: "1",
+					corrID: "1",
+					raw:    "http://demo.com/1",
+					slug:   "slug1",
+				},
+				{
+					userID: "1",
+					corrID: "2",
+					raw:    "http://demo.com/2",
+					slug:   "slug2",
+				},
+			},
+		},
+		{
+			name:         "Delete unknown URLs",
+			baseURL:      "http://127.0.0.1/",
+			targetUserID: "1",
+			targetSlugs:  []string{"slug9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, close, err := newFileStorage("test_delete")
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, close())
+			}()
+
+			for _, v := range tt.existedURLs {
+				shortenedURL := models.NewShortenedURL(
+					v.userID,
+					v.corrID,
+					v.raw,
+					v.slug,
+					tt.baseURL+v.slug,
+				)
+				require.NoError(t, r.Save(context.TODO(), shortenedURL))
+			}
+
+			err = r.Delete(tt.targetUserID, tt.targetSlugs)
+			require.NoError(t, err)
+
+			deleted := make(map[string]struct{})
+			for _, slug := range tt.deletedSlugs {
+				deleted[slug] = struct{}{}
+			}
+			for _, v := range tt.existedURLs {
+				got, err := r.GetBySlug(context.TODO(), v.slug)
+				require.NoError(t, err)
+
+				_, ok := deleted[v.slug]
+				assert.Equal(t, ok, got.IsDeleted, "slug: %v", v.slug)
+				assert.Equal(t, v.raw, got.Raw)
+			}
+
+			stat, err := r.Stat(context.TODO())
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.existedURLs), stat.URLs)
+		})
+	}
 }
 
 func TestFileStorage_Stat(t *testing.T) {
@@ -785,20 +928,418 @@ func TestFileStorage_Stat(t *testing.T) {
 	}
 }
 
+func TestFileStorage_Compact(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "test_compact")
+
+	// A tiny segment size seals the active segment after each write.
+	r, err := FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+
+	n := 10
+	for i := 0; i < n; i++ {
+		slug := fmt.Sprintf("slug%d", i)
+		shortenedURL := models.NewShortenedURL(
+			"1",
+			strconv.Itoa(i),
+			fmt.Sprintf("http://demo.com/%d", i),
+			slug,
+			"http://127.0.0.1/"+slug,
+		)
+		require.NoError(t, r.Save(context.TODO(), shortenedURL))
+	}
+	require.NoError(t, r.Delete("1", []string{"slug0", "slug1"}))
+	assert.Equal(t, n+2, len(r.segments))
+
+	stat, err := r.compact()
+	require.NoError(t, err)
+	assert.Equal(t, n+1, stat.segments)
+	assert.Equal(t, n+1, stat.recordsIn)
+	assert.Equal(t, n, stat.recordsOut)
+	assert.Equal(t, 2, len(r.segments))
+
+	// Nothing to compact.
+	stat, err = r.compact()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stat.segments)
+
+	assertRecords := func(r *fileStorage) {
+		records, err := r.CollectByUser(context.TODO(), "1")
+		require.NoError(t, err)
+		require.Equal(t, n, len(records))
+		for i, v := range records {
+			assert.Equal(t, fmt.Sprintf("slug%d", i), v.Slug)
+			assert.Equal(t, i < 2, v.IsDeleted)
+		}
+	}
+	assertRecords(r)
+	require.NoError(t, r.Close())
+
+	// Reopen the storage.
+	r, err = FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+	}()
+	assertRecords(r)
+}
+
+func TestFileStorage_CompactVisits(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "test_compact_visits")
+
+	// A tiny segment size seals the active segment after each write.
+	r, err := FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+
+	shortenedURL := models.NewShortenedURL("1", "1", "http://demo.com/1", "slug1", "http://127.0.0.1/slug1")
+	shortenedURL.MaxVisits = 5
+	require.NoError(t, r.Save(context.TODO(), shortenedURL))
+	for i := 0; i < 3; i++ {
+		_, err = r.Visit(context.TODO(), "slug1")
+		require.NoError(t, err)
+	}
+
+	// The sealed counters are folded into the record, the last one is in the active segment.
+	stat, err := r.compact()
+	require.NoError(t, err)
+	assert.Equal(t, 3, stat.recordsIn)
+	assert.Equal(t, 1, stat.recordsOut)
+
+	assertVisits := func(r *fileStorage, want int) {
+		got, err := r.GetBySlug(context.TODO(), "slug1")
+		require.NoError(t, err)
+		assert.Equal(t, 5, got.MaxVisits)
+		assert.Equal(t, want, got.Visits)
+	}
+	assertVisits(r, 3)
+
+	left, err := r.Visit(context.TODO(), "slug1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, left)
+	require.NoError(t, r.Close())
+
+	// Reopen the storage.
+	r, err = FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+	}()
+	assertVisits(r, 4)
+}
+
+func TestFileStorage_CompactEdits(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "test_compact_edits")
+
+	// A tiny segment size seals the active segment after each write.
+	r, err := FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Save(context.TODO(),
+		models.NewShortenedURL("1", "1", "http://demo.com/1", "slug1", "http://127.0.0.1/slug1")))
+	for _, raw := range []string{"http://demo.com/2", "http://demo.com/3"} {
+		_, err = r.Edit(context.TODO(), models.URLChange{Slug: "slug1", UserID: "1", New: raw})
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Save(context.TODO(),
+		models.NewShortenedURL("1", "2", "http://demo.com/4", "slug2", "http://127.0.0.1/slug2")))
+
+	// The sealed edits are kept after the records, the last record is in the active segment.
+	stat, err := r.compact()
+	require.NoError(t, err)
+	assert.Equal(t, 3, stat.recordsIn)
+	assert.Equal(t, 3, stat.recordsOut)
+
+	assertEdits := func(r *fileStorage) {
+		got, err := r.GetBySlug(context.TODO(), "slug1")
+		require.NoError(t, err)
+		assert.Equal(t, "http://demo.com/3", got.Raw)
+
+		got, err = r.GetByURL(context.TODO(), "1", "http://demo.com/3")
+		require.NoError(t, err)
+		assert.Equal(t, "slug1", got.Slug)
+
+		got, err = r.GetBySlug(context.TODO(), "slug2")
+		require.NoError(t, err)
+		assert.Equal(t, "http://demo.com/4", got.Raw)
+
+		history, err := r.History(context.TODO(), "1", "slug1")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(history))
+		assert.Equal(t, "http://demo.com/1", history[0].Old)
+		assert.Equal(t, 3, history[1].Version)
+	}
+	assertEdits(r)
+	require.NoError(t, r.Close())
+
+	// Reopen the storage.
+	r, err = FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+	}()
+	assertEdits(r)
+}
+
+func TestFileStorage_CompactSequence(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "test_compact_sequence")
+
+	// A tiny segment size seals the active segment after each write.
+	r, err := FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Save(context.TODO(),
+		models.NewShortenedURL("1", "1", "http://demo.com/1", "slug1", "http://127.0.0.1/slug1")))
+	for i := 0; i < 5; i++ {
+		_, err = r.Next(context.TODO())
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Save(context.TODO(),
+		models.NewShortenedURL("1", "2", "http://demo.com/2", "slug2", "http://127.0.0.1/slug2")))
+
+	// Only the greatest sealed sequence entry is kept after the record.
+	stat, err := r.compact()
+	require.NoError(t, err)
+	assert.Equal(t, 6, stat.recordsIn)
+	assert.Equal(t, 2, stat.recordsOut)
+	require.NoError(t, r.Close())
+
+	// Reopen the storage.
+	r, err = FileStorage(Config{Path: base, SegmentSize: 1, CompactionInterval: time.Hour})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+	}()
+	next, err := r.Next(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), next)
+}
+
+func TestRecoverSegments(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []uint64
+		left  []string
+	}{
+		{
+			name: "No files",
+		},
+		{
+			name:  "Legacy file",
+			files: []string{"file.db"},
+			want:  []uint64{1},
+			left:  []string{"file.db.000001"},
+		},
+		{
+			name:  "Segments",
+			files: []string{"file.db.000003", "file.db.000001", "file.db.000002", "other.db.000004"},
+			want:  []uint64{1, 2, 3},
+			left:  []string{"file.db.000001", "file.db.000002", "file.db.000003", "other.db.000004"},
+		},
+		{
+			name:  "Unfinished compaction",
+			files: []string{"file.db.000001", "file.db.000002", "file.db.000003", "file.db.000002.tmp"},
+			want:  []uint64{1, 2, 3},
+			left:  []string{"file.db.000001", "file.db.000002", "file.db.000003"},
+		},
+		{
+			name:  "Committed compaction",
+			files: []string{"file.db.000001", "file.db.000002", "file.db.000003", "file.db.000002.merged"},
+			want:  []uint64{2, 3},
+			left:  []string{"file.db.000002", "file.db.000003"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, f), nil, 0644))
+			}
+
+			got, err := recoverSegments(filepath.Join(dir, "file.db"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			files, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			var left []string
+			for _, f := range files {
+				left = append(left, f.Name())
+			}
+			assert.Equal(t, tt.left, left)
+		})
+	}
+}
+
+func TestFileStorage_Recover(t *testing.T) {
+	records := []ShortenedURL{
+		newShortenedURL(models.NewShortenedURL("1", "1", "http://demo.com/1", "slug1", "http://127.0.0.1/slug1")),
+		newShortenedURL(models.NewShortenedURL("1", "2", "http://demo.com/2", "slug2", "http://127.0.0.1/slug2")),
+		newShortenedURL(models.NewShortenedURL("1", "3", "http://demo.com/3", "slug3", "http://127.0.0.1/slug3")),
+	}
+	framed := func(records []ShortenedURL) []byte {
+		var b []byte
+		for _, r := range records {
+			payload, err := r.MarshalMsg(nil)
+			require.NoError(t, err)
+			b = appendFrame(b, payload)
+		}
+		return b
+	}
+	batch := func(records []ShortenedURL) []byte {
+		payload := msgp.AppendArrayHeader(nil, uint32(len(records)))
+		for _, r := range records {
+			var err error
+			payload, err = r.MarshalMsg(payload)
+			require.NoError(t, err)
+		}
+		return appendFrame(nil, payload)
+	}
+
+	tests := []struct {
+		name string
+		file func() []byte
+		want []string
+	}{
+		{
+			name: "Torn tail",
+			file: func() []byte {
+				b := framed(records)
+				return b[:len(b)-3]
+			},
+			want: []string{"slug1", "slug2"},
+		},
+		{
+			name: "Corrupt entry",
+			file: func() []byte {
+				b := framed(records)
+				b[frameHeaderSize+1] ^= 0xff
+				return b
+			},
+			want: []string{"slug2", "slug3"},
+		},
+		{
+			name: "Batch",
+			file: func() []byte {
+				return append(framed(records[:1]), batch(records[1:])...)
+			},
+			want: []string{"slug1", "slug2", "slug3"},
+		},
+		{
+			name: "Torn batch",
+			file: func() []byte {
+				b := append(framed(records[:1]), batch(records[1:])...)
+				return b[:len(b)-1]
+			},
+			want: []string{"slug1"},
+		},
+		{
+			name: "Unframed entries",
+			file: func() []byte {
+				var b []byte
+				for _, r := range records {
+					var err error
+					b, err = r.MarshalMsg(b)
+					require.NoError(t, err)
+				}
+				return b
+			},
+			want: []string{"slug1", "slug2", "slug3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := filepath.Join(t.TempDir(), "test_recover")
+			require.NoError(t, os.WriteFile(segmentPath(base, 1), tt.file(), 0644))
+
+			open := func() *fileStorage {
+				r, err := FileStorage(Config{Path: base, CompactionInterval: time.Hour})
+				require.NoError(t, err)
+				return r
+			}
+			assertSlugs := func(r *fileStorage, want []string) {
+				got, err := r.CollectByUser(context.TODO(), "1")
+				require.NoError(t, err)
+				slugs := make([]string, len(got))
+				for i, v := range got {
+					slugs[i] = v.Slug
+				}
+				assert.Equal(t, want, slugs)
+			}
+
+			r := open()
+			assertSlugs(r, tt.want)
+
+			// New entries are appended after the recovered ones.
+			require.NoError(t, r.Save(context.TODO(), models.NewShortenedURL(
+				"1", "4", "http://demo.com/4", "slug4", "http://127.0.0.1/slug4",
+			)))
+			require.NoError(t, r.Close())
+
+			r = open()
+			defer func() {
+				require.NoError(t, r.Close())
+			}()
+			assertSlugs(r, append(tt.want, "slug4"))
+		})
+	}
+}
+
+func TestFileStorage_Sync(t *testing.T) {
+	tests := []struct {
+		name string
+		sync string
+		err  bool
+	}{
+		{name: "Always", sync: SyncAlways},
+		{name: "Interval", sync: SyncInterval},
+		{name: "Never", sync: SyncNever},
+		{name: "Unknown policy", sync: "sometimes", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := filepath.Join(t.TempDir(), "test_sync")
+			r, err := FileStorage(Config{Path: base, Sync: tt.sync, SyncInterval: time.Millisecond})
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, r.Close())
+			}()
+
+			require.NoError(t, r.Save(context.TODO(), models.NewShortenedURL(
+				"1", "1", "http://demo.com", "slug1", "http://127.0.0.1/slug1",
+			)))
+			if tt.sync == SyncAlways {
+				r.mtx.RLock()
+				assert.False(t, r.dirty)
+				r.mtx.RUnlock()
+			}
+		})
+	}
+}
+
 // newFileStorage creates a new fileStorage for test.
 func newFileStorage(filename string) (*fileStorage, func() error, error) {
-	// Prepare tmp filepath.
-	start := time.Now()
-	filename = fmt.Sprintf("%v\\%v%v", os.TempDir(), filename, strconv.FormatInt(start.Unix(), 10))
+	// Prepare tmp dir for segments.
+	dir, err := os.MkdirTemp("", filename)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	repo, err := FileStorage(filename)
+	repo, err := FileStorage(Config{Path: filepath.Join(dir, filename)})
 	if err != nil {
 		return nil, nil, err
 	}
 
 	return repo, func() error {
 		err1 := repo.Close()
-		err2 := os.Remove(filename)
+		err2 := os.RemoveAll(dir)
 		if err1 == nil && err2 == nil {
 			return nil
 		}
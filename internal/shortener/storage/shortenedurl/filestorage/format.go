@@ -0,0 +1,63 @@
+package filestorage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Segment format versions.
+const (
+	// formatV0 is plain msgp entries without the header and frames.
+	formatV0 uint32 = iota
+	// formatV1 is framed entries without the header.
+	formatV1
+	// formatV2 is framed entries after the segment header.
+	formatV2
+
+	// currentFormat is the format the new segments are written down in.
+	currentFormat = formatV2
+)
+
+// Each segment of the current format starts with the header:
+//
+//	| magic [4]byte | version uint32 |
+//
+// The version is big-endian.
+const segmentHeaderSize = 8
+
+var segmentMagic = []byte("SURL")
+
+// appendHeader appends the segment header of the version to dst.
+func appendHeader(dst []byte, version uint32) []byte {
+	dst = append(dst, segmentMagic...)
+	return binary.BigEndian.AppendUint32(dst, version)
+}
+
+// readHeader returns the format version of the segment data and the size of its header.
+// The data of the older versions has no header, so they are told apart by the first entry.
+// An empty data or a torn header is reported as the current format with the zero header size.
+func readHeader(b []byte) (uint32, int, error) {
+	if len(b) < segmentHeaderSize {
+		n := len(b)
+		if n > len(segmentMagic) {
+			n = len(segmentMagic)
+		}
+		if bytes.Equal(b[:n], segmentMagic[:n]) {
+			return currentFormat, 0, nil
+		}
+	}
+
+	if !bytes.HasPrefix(b, segmentMagic) {
+		if isUnframed(b) {
+			return formatV0, 0, nil
+		}
+		return formatV1, 0, nil
+	}
+
+	version := binary.BigEndian.Uint32(b[len(segmentMagic):segmentHeaderSize])
+	if version < formatV2 || version > currentFormat {
+		return 0, 0, fmt.Errorf("unsupported format version: %d", version)
+	}
+	return version, segmentHeaderSize, nil
+}
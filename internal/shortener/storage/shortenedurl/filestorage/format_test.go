@@ -0,0 +1,66 @@
+package filestorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHeader(t *testing.T) {
+	entry := newShortenedURL(newTestModel("slug1"))
+	record, err := entry.MarshalMsg(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		b        []byte
+		version  uint32
+		wantSize int
+		err      bool
+	}{
+		{
+			name:    "Empty data",
+			version: currentFormat,
+		},
+		{
+			name:    "Torn header",
+			b:       appendHeader(nil, currentFormat)[:5],
+			version: currentFormat,
+		},
+		{
+			name:     "Current format",
+			b:        appendFrame(appendHeader(nil, currentFormat), record),
+			version:  currentFormat,
+			wantSize: segmentHeaderSize,
+		},
+		{
+			name:    "Unframed entries",
+			b:       record,
+			version: formatV0,
+		},
+		{
+			name:    "Framed entries without header",
+			b:       appendFrame(nil, record),
+			version: formatV1,
+		},
+		{
+			name: "Unknown version",
+			b:    appendHeader(nil, currentFormat+1),
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, size, err := readHeader(tt.b)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, version)
+			assert.Equal(t, tt.wantSize, size)
+		})
+	}
+}
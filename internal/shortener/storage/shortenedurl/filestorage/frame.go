@@ -0,0 +1,66 @@
+package filestorage
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// Each entry of the log is written down as a frame:
+//
+//	| length uint32 | crc32 uint32 | msgp payload |
+//
+// The length and the Castagnoli CRC of the payload are big-endian.
+const frameHeaderSize = 8
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Frame errors.
+var (
+	// errTornFrame is returned when the frame is cut off by the end of the data.
+	errTornFrame = errors.New("torn frame")
+	// errCorruptFrame is returned when the frame payload does not match its CRC.
+	errCorruptFrame = errors.New("corrupt frame")
+)
+
+// appendFrame appends the payload framed with its length and CRC to dst.
+func appendFrame(dst, payload []byte) []byte {
+	var header [frameHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:], crc32.Checksum(payload, crcTable))
+
+	dst = append(dst, header[:]...)
+	return append(dst, payload...)
+}
+
+// readFrame reads the frame at the beginning of b. It returns the payload
+// and the frame size. The frame size is valid for errCorruptFrame too,
+// so the caller is able to skip the frame.
+func readFrame(b []byte) ([]byte, int, error) {
+	if len(b) < frameHeaderSize {
+		return nil, 0, errTornFrame
+	}
+
+	length := int(binary.BigEndian.Uint32(b[:4]))
+	sum := binary.BigEndian.Uint32(b[4:frameHeaderSize])
+	if len(b)-frameHeaderSize < length {
+		return nil, 0, errTornFrame
+	}
+
+	size := frameHeaderSize + length
+	payload := b[frameHeaderSize:size]
+	if crc32.Checksum(payload, crcTable) != sum {
+		return nil, size, errCorruptFrame
+	}
+	return payload, size, nil
+}
+
+// isUnframed checks whether the data is written down by the older versions
+// as plain msgp entries. The data starts with a msgp map then, the frame
+// starts with the high byte of its length, which is zero for any real entry.
+func isUnframed(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	return b[0]&0xf0 == 0x80 || b[0] == 0xde || b[0] == 0xdf
+}
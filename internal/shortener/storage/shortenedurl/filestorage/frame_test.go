@@ -0,0 +1,55 @@
+package filestorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFrame(t *testing.T) {
+	payload := []byte("payload")
+	frame := appendFrame(nil, payload)
+
+	corrupt := append([]byte(nil), frame...)
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	tests := []struct {
+		name     string
+		b        []byte
+		want     []byte
+		wantSize int
+		err      error
+	}{
+		{
+			name:     "Valid frame",
+			b:        append(frame, 0, 0),
+			want:     payload,
+			wantSize: len(frame),
+		},
+		{
+			name: "Torn header",
+			b:    frame[:frameHeaderSize-1],
+			err:  errTornFrame,
+		},
+		{
+			name: "Torn payload",
+			b:    frame[:len(frame)-1],
+			err:  errTornFrame,
+		},
+		{
+			name:     "Corrupt payload",
+			b:        corrupt,
+			wantSize: len(frame),
+			err:      errCorruptFrame,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, size, err := readFrame(tt.b)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantSize, size)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package filestorage
+
+import (
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+)
+
+// location is a position of the entry payload in the log.
+type location struct {
+	seq    uint64
+	offset int64
+	size   int64
+}
+
+// indexEntry is an indexed record of the log. The expiry marks are kept
+// in the index only, they are set again by the expiry time after restart.
+//
+// The key is the current dedup key of the record. The edited record keeps
+// its changed destination in the index, it replaces the one read from the log.
+type indexEntry struct {
+	loc       location
+	slug      string
+	userID    string
+	key       string
+	edited    bool
+	raw       string
+	canonical string
+	expiresAt time.Time
+	expired   bool
+	maxVisits int
+	visits    int
+	deleted   bool
+}
+
+// index is an in-memory index of the log records. It is built once
+// by replaying the log and then kept up to date by the write path.
+//
+// Records are kept in the log order, lookups refer to them by position.
+// The records stay indexed by the dedup keys they had before the edits,
+// so the lookups check the current key.
+type index struct {
+	entries []indexEntry
+	bySlug  map[string][]int              // slug: records
+	byURL   map[string][]int              // dedup key: records
+	byUser  map[string][]int              // userID: records
+	history map[string][]models.URLChange // slug: changes
+
+	// seq is the greatest written value of the counter slug sequence.
+	seq uint64
+}
+
+// newIndex returns a new empty index.
+func newIndex() *index {
+	return &index{
+		bySlug:  make(map[string][]int),
+		byURL:   make(map[string][]int),
+		byUser:  make(map[string][]int),
+		history: make(map[string][]models.URLChange),
+	}
+}
+
+// apply applies the log entry to the index.
+func (idx *index) apply(e ShortenedURL, loc location) {
+	if e.Sequence != 0 {
+		if e.Sequence > idx.seq {
+			idx.seq = e.Sequence
+		}
+		return
+	}
+	if e.Tombstone {
+		idx.markDeleted(e.UserID, e.Slug)
+		return
+	}
+	if e.Counter {
+		idx.setVisits(e.Slug, e.Visits)
+		return
+	}
+	if e.Edit {
+		idx.edit(e.change())
+		return
+	}
+
+	i := len(idx.entries)
+	m := e.ToModel()
+	idx.entries = append(idx.entries, indexEntry{
+		loc:       loc,
+		slug:      e.Slug,
+		userID:    e.UserID,
+		key:       m.DedupKey(),
+		expiresAt: e.ExpiresAt,
+		maxVisits: e.MaxVisits,
+		visits:    e.Visits,
+		deleted:   e.IsDeleted,
+	})
+	idx.bySlug[e.Slug] = append(idx.bySlug[e.Slug], i)
+	idx.byUser[e.UserID] = append(idx.byUser[e.UserID], i)
+	idx.byURL[m.DedupKey()] = append(idx.byURL[m.DedupKey()], i)
+}
+
+// edit changes the destination of the user records with the slug
+// and appends the change to the slug history.
+func (idx *index) edit(c models.URLChange) {
+	for _, i := range idx.bySlug[c.Slug] {
+		e := &idx.entries[i]
+		if e.userID != c.UserID {
+			continue
+		}
+		e.edited = true
+		e.raw = c.New
+		e.canonical = c.Canonical
+		if e.key != c.DedupKey() {
+			e.key = c.DedupKey()
+			idx.byURL[e.key] = append(idx.byURL[e.key], i)
+		}
+	}
+	idx.history[c.Slug] = append(idx.history[c.Slug], c)
+}
+
+// markDeleted marks the user records with the slug as deleted.
+func (idx *index) markDeleted(userID, slug string) {
+	for _, i := range idx.bySlug[slug] {
+		if idx.entries[i].userID == userID {
+			idx.entries[i].deleted = true
+		}
+	}
+}
+
+// setVisits sets the number of the counted visits of the records with the slug.
+func (idx *index) setVisits(slug string, visits int) {
+	for _, i := range idx.bySlug[slug] {
+		idx.entries[i].visits = visits
+	}
+}
+
+// expire marks the records expired by the time and returns the number of the marked ones.
+func (idx *index) expire(now time.Time) int {
+	var n int
+	for i := range idx.entries {
+		e := &idx.entries[i]
+		if !e.expired && !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+			e.expired = true
+			n++
+		}
+	}
+	return n
+}
+
+// keepExpired marks the records expired in the old index of the same log.
+func (idx *index) keepExpired(old *index) {
+	for _, e := range old.entries {
+		if !e.expired {
+			continue
+		}
+		for _, i := range idx.bySlug[e.slug] {
+			if idx.entries[i].userID == e.userID {
+				idx.entries[i].expired = true
+			}
+		}
+	}
+}
+
+// owns checks whether the user has an alive record with the slug.
+func (idx *index) owns(userID, slug string) bool {
+	for _, i := range idx.bySlug[slug] {
+		if idx.entries[i].userID == userID && !idx.entries[i].deleted {
+			return true
+		}
+	}
+	return false
+}
+
+// getBySlug returns the first record with the slug.
+func (idx *index) getBySlug(slug string) (indexEntry, bool) {
+	if ids, ok := idx.bySlug[slug]; ok {
+		return idx.entries[ids[0]], true
+	}
+	return indexEntry{}, false
+}
+
+// getByURL returns the first record with the dedup key URL that conflicts
+// with a new URL of the user in the scope.
+func (idx *index) getByURL(scope shortenedurl.Scope, userID, url string) (indexEntry, bool) {
+	for _, i := range idx.byURL[url] {
+		if idx.entries[i].key == url && scope.Conflicts(idx.entries[i].userID, userID) {
+			return idx.entries[i], true
+		}
+	}
+	return indexEntry{}, false
+}
+
+// collectByUser returns the user records in the log order, the expired ones are skipped.
+func (idx *index) collectByUser(userID string) []indexEntry {
+	ids := idx.byUser[userID]
+	records := make([]indexEntry, 0, len(ids))
+	for _, id := range ids {
+		if !idx.entries[id].expired {
+			records = append(records, idx.entries[id])
+		}
+	}
+	return records
+}
+
+// records returns the number of records.
+func (idx *index) records() int {
+	return len(idx.entries)
+}
+
+// users returns the number of users.
+func (idx *index) users() int {
+	return len(idx.byUser)
+}
+
+// relocate moves the leading records to the new locations after compaction.
+// The records of the compacted segments must be exactly the leading ones,
+// otherwise nothing is changed and false is returned.
+func (idx *index) relocate(locs []location, lastSeq uint64) bool {
+	n := len(locs)
+	if n > len(idx.entries) {
+		return false
+	}
+	if n > 0 && idx.entries[n-1].loc.seq > lastSeq {
+		return false
+	}
+	if n < len(idx.entries) && idx.entries[n].loc.seq <= lastSeq {
+		return false
+	}
+
+	for i, loc := range locs {
+		idx.entries[i].loc = loc
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package filestorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex(t *testing.T) {
+	idx := newIndex()
+
+	entries := []ShortenedURL{
+		{UserID: "1", Slug: "slug1", Raw: "http://demo.com/1"},
+		{UserID: "1", Slug: "slug2", Raw: "http://demo.com/2"},
+		{UserID: "2", Slug: "slug3", Raw: "http://demo.com/1"},
+		newTombstone("2", "slug1"),
+		newTombstone("1", "slug2"),
+	}
+	for i, e := range entries {
+		idx.apply(e, location{seq: 1, offset: int64(i)})
+	}
+
+	assert.Equal(t, 3, idx.records())
+	assert.Equal(t, 2, idx.users())
+
+	got, ok := idx.getBySlug("slug1")
+	assert.True(t, ok)
+	assert.False(t, got.deleted, "tombstone of another user is applied")
+
+	got, ok = idx.getBySlug("slug2")
+	assert.True(t, ok)
+	assert.True(t, got.deleted)
+	assert.False(t, idx.owns("1", "slug2"))
+	assert.True(t, idx.owns("1", "slug1"))
+	assert.False(t, idx.owns("2", "slug1"))
+
+	got, ok = idx.getByURL(shortenedurl.ScopeUser, "2", "http://demo.com/1")
+	assert.True(t, ok)
+	assert.Equal(t, "slug3", got.slug)
+	got, ok = idx.getByURL(shortenedurl.ScopeGlobal, "2", "http://demo.com/1")
+	assert.True(t, ok)
+	assert.Equal(t, "slug1", got.slug)
+	_, ok = idx.getByURL(shortenedurl.ScopeUser, "2", "http://demo.com/2")
+	assert.False(t, ok)
+	_, ok = idx.getByURL(shortenedurl.ScopeGlobal, "2", "http://demo.com/2")
+	assert.True(t, ok)
+
+	_, ok = idx.getBySlug("slug9")
+	assert.False(t, ok)
+
+	assert.Equal(t, 2, len(idx.collectByUser("1")))
+	assert.Equal(t, 0, len(idx.collectByUser("3")))
+
+	// The records of the segment 1 are the leading ones.
+	locs := []location{{seq: 2, offset: 0}, {seq: 2, offset: 10}, {seq: 2, offset: 20}}
+	assert.True(t, idx.relocate(locs, 1))
+	got, _ = idx.getBySlug("slug3")
+	assert.Equal(t, locs[2], got.loc)
+
+	// Too many locations.
+	assert.False(t, idx.relocate(append(locs, location{}), 2))
+}
+
+func TestIndex_KeepExpired(t *testing.T) {
+	now := time.Now()
+	entries := []ShortenedURL{
+		{UserID: "1", Slug: "slug1", Raw: "http://demo.com/1", ExpiresAt: now.Add(-time.Hour)},
+		{UserID: "1", Slug: "slug2", Raw: "http://demo.com/2", ExpiresAt: now.Add(time.Hour)},
+		{UserID: "2", Slug: "slug3", Raw: "http://demo.com/3"},
+	}
+	old, idx := newIndex(), newIndex()
+	for i, e := range entries {
+		old.apply(e, location{seq: 1, offset: int64(i)})
+	}
+	// The rebuilt index has the records in another order.
+	for i := len(entries) - 1; i >= 0; i-- {
+		idx.apply(entries[i], location{seq: 2, offset: int64(i)})
+	}
+	assert.Equal(t, 1, old.expire(now))
+
+	idx.keepExpired(old)
+	records := idx.collectByUser("1")
+	if assert.Equal(t, 1, len(records)) {
+		assert.Equal(t, "slug2", records[0].slug)
+	}
+	assert.Equal(t, 0, idx.expire(now), "expired mark is lost")
+}
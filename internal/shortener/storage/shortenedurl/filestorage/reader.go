@@ -10,57 +10,227 @@ import (
 
 // reader defines the file reader.
 type reader interface {
-	List() ([]ShortenedURL, error)
+	Scan() (segmentScan, error)
+	ReadAt(offset, size int64) (ShortenedURL, error)
 	io.Closer
 }
 
 var _ reader = (*msgpReader)(nil)
 
-// msgpReader is a msgp file reader.
+// logEntry is an entry of the log segment with the position of its payload in the file.
+type logEntry struct {
+	ShortenedURL
+	offset int64
+	size   int64
+}
+
+// segmentScan is the result of the segment scan.
+type segmentScan struct {
+	// entries are the valid entries of the segment.
+	entries []logEntry
+	// size is the size of the valid part of the segment. The rest of the file is a torn tail.
+	size int64
+	// fileSize is the size of the segment file.
+	fileSize int64
+	// skipped is the number of corrupt entries that were skipped.
+	skipped int
+	// version is the format version of the segment.
+	version uint32
+}
+
+// torn checks whether the segment ends with a torn tail.
+func (s segmentScan) torn() bool {
+	return s.size < s.fileSize
+}
+
+// msgpReader is a msgp file reader. It uses positional reads only,
+// so it is safe for concurrent use.
 type msgpReader struct {
-	file   *os.File
-	reader *msgp.Reader
+	file *os.File
 }
 
 // newMsgpReader returns a new msgpReader.
 func newMsgpReader(f *os.File) reader {
 	return &msgpReader{
-		file:   f,
-		reader: msgp.NewReader(f),
+		file: f,
 	}
 }
 
-// List returns all entries in the file. A successful call returns err == nil.
-func (r *msgpReader) List() (records []ShortenedURL, err error) {
-	defer func() {
-		err = r.rewind()
-	}()
+// Scan reads all entries in the file including tombstones. The file is read according
+// to its format version. Corrupt entries are skipped, the scan stops at the torn tail.
+// A successful call returns err == nil.
+func (r *msgpReader) Scan() (segmentScan, error) {
+	info, err := r.file.Stat()
+	if err != nil {
+		return segmentScan{}, err
+	}
 
-	var record ShortenedURL
-	err = record.DecodeMsg(r.reader)
-	for err == nil {
-		records = append(records, record)
-		err = record.DecodeMsg(r.reader)
+	b := make([]byte, info.Size())
+	if _, err = r.file.ReadAt(b, 0); err != nil && err != io.EOF {
+		return segmentScan{}, err
 	}
-	if err != nil && errors.Unwrap(err) != io.EOF {
-		return nil, err
+
+	version, offset, err := readHeader(b)
+	if err != nil {
+		return segmentScan{}, err
 	}
 
-	return records, nil
+	scan := segmentScan{fileSize: info.Size(), version: version}
+	if version == formatV0 {
+		scanUnframed(b, &scan)
+		return scan, nil
+	}
+	scanFrames(b, offset, &scan)
+
+	return scan, nil
 }
 
-// Close closes msgpReader.
-func (r *msgpReader) Close() error {
-	return r.file.Close()
+// scanFrames reads the framed entries starting at the offset.
+func scanFrames(b []byte, offset int, scan *segmentScan) {
+	for offset < len(b) {
+		payload, n, err := readFrame(b[offset:])
+		if errors.Is(err, errTornFrame) {
+			break
+		}
+		if err != nil {
+			scan.skipped++
+			offset += n
+			continue
+		}
+
+		entries, err := decodeFrame(payload, int64(offset+frameHeaderSize))
+		if err != nil {
+			scan.skipped++
+			offset += n
+			continue
+		}
+
+		scan.entries = append(scan.entries, entries...)
+		offset += n
+	}
+	scan.size = int64(offset)
 }
 
-// rewind sets the search position at the beginning of the file and updates reader.
-func (r *msgpReader) rewind() error {
-	_, err := r.file.Seek(0, io.SeekStart)
+// decodeFrame decodes the entries of the frame payload located at the offset.
+// The payload is either a single entry or a batch written down as a msgp array of entries.
+func decodeFrame(payload []byte, offset int64) ([]logEntry, error) {
+	if msgp.NextType(payload) != msgp.ArrayType {
+		var e logEntry
+		if _, err := e.UnmarshalMsg(payload); err != nil {
+			return nil, err
+		}
+		e.offset = offset
+		e.size = int64(len(payload))
+		return []logEntry{e}, nil
+	}
+
+	n, left, err := msgp.ReadArrayHeaderBytes(payload)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	entries := make([]logEntry, n)
+	for i := range entries {
+		start := len(payload) - len(left)
+		if left, err = entries[i].UnmarshalMsg(left); err != nil {
+			return nil, err
+		}
+		entries[i].offset = offset + int64(start)
+		entries[i].size = int64(len(payload) - len(left) - start)
+	}
+	return entries, nil
+}
+
+// scanUnframed reads plain msgp entries. The scan stops at the first entry
+// that cannot be decoded, since there is no way to find where the next one starts.
+func scanUnframed(b []byte, scan *segmentScan) {
+	var offset int
+	for offset < len(b) {
+		var e logEntry
+		left, err := e.UnmarshalMsg(b[offset:])
+		if err != nil {
+			break
+		}
+		n := len(b) - offset - len(left)
+		e.offset = int64(offset)
+		e.size = int64(n)
+
+		scan.entries = append(scan.entries, e)
+		offset += n
+	}
+	scan.size = int64(offset)
+}
+
+// ReadAt reads the entry payload at the offset. A successful call returns err == nil.
+func (r *msgpReader) ReadAt(offset, size int64) (ShortenedURL, error) {
+	b := make([]byte, size)
+	if _, err := r.file.ReadAt(b, offset); err != nil {
+		return ShortenedURL{}, err
+	}
+
+	var record ShortenedURL
+	_, err := record.UnmarshalMsg(b)
+	return record, err
+}
+
+// replay returns the records and the edits of the log entries. Tombstones and counters
+// are applied to the previously written records in the order they were written.
+// The edits are kept in the order they were written, since they make up the history.
+// The greatest sequence entry is kept after the edits.
+func replay(entries []logEntry) ([]ShortenedURL, []ShortenedURL) {
+	records := make([]ShortenedURL, 0, len(entries))
+	var (
+		edits []ShortenedURL
+		seq   uint64
+	)
+	for _, e := range entries {
+		if e.Sequence != 0 {
+			if e.Sequence > seq {
+				seq = e.Sequence
+			}
+			continue
+		}
+		if e.Tombstone {
+			applyTombstone(records, e.ShortenedURL)
+			continue
+		}
+		if e.Counter {
+			applyCounter(records, e.ShortenedURL)
+			continue
+		}
+		if e.Edit {
+			edits = append(edits, e.ShortenedURL)
+			continue
+		}
+		records = append(records, e.ShortenedURL)
+	}
+	if seq != 0 {
+		edits = append(edits, newSequence(seq))
+	}
+	return records, edits
+}
+
+// applyTombstone marks records with the tombstone slug as deleted.
+// Only the records of the tombstone owner are affected.
+func applyTombstone(records []ShortenedURL, tombstone ShortenedURL) {
+	for i := range records {
+		if records[i].Slug == tombstone.Slug &&
+			records[i].UserID == tombstone.UserID {
+			records[i].IsDeleted = true
+		}
+	}
+}
+
+// applyCounter sets the number of the counted visits of the records with the counter slug.
+func applyCounter(records []ShortenedURL, counter ShortenedURL) {
+	for i := range records {
+		if records[i].Slug == counter.Slug {
+			records[i].Visits = counter.Visits
+		}
 	}
+}
 
-	r.reader.Reset(r.file)
-	return err
+// Close closes msgpReader.
+func (r *msgpReader) Close() error {
+	return r.file.Close()
 }
@@ -0,0 +1,165 @@
+package filestorage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Segment file suffixes used by compaction.
+const (
+	// tmpSuffix marks the compaction output that is being written.
+	tmpSuffix = ".tmp"
+	// mergedSuffix marks the complete compaction output that is not swapped in yet.
+	mergedSuffix = ".merged"
+)
+
+// segment is a log segment of the file storage.
+type segment struct {
+	seq       uint64
+	path      string
+	r         reader
+	compacted bool
+	version   uint32
+}
+
+// segmentPath returns the segment path by the storage base path and the sequence number.
+func segmentPath(base string, seq uint64) string {
+	return fmt.Sprintf("%s.%06d", base, seq)
+}
+
+// openSegment opens the segment for reading. The segment file is created if it does not exist.
+func openSegment(base string, seq uint64) (*segment, error) {
+	path := segmentPath(base, seq)
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &segment{
+		seq:  seq,
+		path: path,
+		r:    newMsgpReader(f),
+	}, nil
+}
+
+// readSegment reads all valid entries of the segment file.
+func readSegment(path string) (segmentScan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return segmentScan{}, err
+	}
+	defer f.Close()
+
+	return newMsgpReader(f).Scan()
+}
+
+// writeSegment writes records down to a new segment file and syncs it.
+// It returns the record locations in the segment.
+func writeSegment(path string, seq uint64, records []ShortenedURL) ([]location, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := newMsgpWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	locs := make([]location, len(records))
+	for i, r := range records {
+		ext, err := w.Write(r)
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+		locs[i] = location{seq: seq, offset: ext.offset, size: ext.size}
+	}
+	if err = w.Sync(); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	return locs, w.Close()
+}
+
+// recoverSegments returns the sorted sequence numbers of the storage segments.
+//
+// Leftovers of an interrupted compaction are cleaned up: unfinished output is removed,
+// complete output replaces the segments it was merged from. A single legacy file
+// at the base path is adopted as the first segment.
+func recoverSegments(base string) ([]uint64, error) {
+	dir, name := filepath.Split(base)
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var seqs, merged []uint64
+	for _, f := range files {
+		suffix, ok := strings.CutPrefix(f.Name(), name+".")
+		if !ok || f.IsDir() {
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(suffix, tmpSuffix):
+			if err = os.Remove(filepath.Join(dir, f.Name())); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(suffix, mergedSuffix):
+			seq, err := strconv.ParseUint(strings.TrimSuffix(suffix, mergedSuffix), 10, 64)
+			if err != nil {
+				continue
+			}
+			merged = append(merged, seq)
+		default:
+			seq, err := strconv.ParseUint(suffix, 10, 64)
+			if err != nil {
+				continue
+			}
+			seqs = append(seqs, seq)
+		}
+	}
+
+	// Finish the committed compactions.
+	sort.Slice(merged, func(i, j int) bool { return merged[i] < merged[j] })
+	for _, m := range merged {
+		live := seqs[:0]
+		for _, seq := range seqs {
+			if seq > m {
+				live = append(live, seq)
+				continue
+			}
+			if err = os.Remove(segmentPath(base, seq)); err != nil {
+				return nil, err
+			}
+		}
+		if err = os.Rename(segmentPath(base, m)+mergedSuffix, segmentPath(base, m)); err != nil {
+			return nil, err
+		}
+		seqs = append(live, m)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	if len(seqs) == 0 {
+		info, err := os.Stat(base)
+		if err == nil && info.Mode().IsRegular() {
+			if err = os.Rename(base, segmentPath(base, 1)); err != nil {
+				return nil, err
+			}
+			seqs = append(seqs, 1)
+		}
+	}
+
+	return seqs, nil
+}
@@ -1,41 +1,155 @@
 package filestorage
 
-import "github.com/alukart32/shortener-url/internal/shortener/models"
+import (
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+)
 
 //go:generate msgp
 
-// ShortenedURL is a shortened URL in the file storage.
+// ShortenedURL is a shortened URL in the file storage. The counter entry
+// holds the number of the counted visits of the slug, the edit entry holds
+// the destination change of the slug. The sequence entry holds a handed out value
+// of the counter slug sequence.
 type ShortenedURL struct {
-	Slug      string `msg:"slug"`
-	UserID    string `msg:"userID"`
-	CorrID    string `msg:"corrID"`
-	Value     string `msg:"value"`
-	Raw       string `msg:"Raw"`
-	IsDeleted bool   `msg:"is_deleted"`
+	Slug           string    `msg:"slug"`
+	UserID         string    `msg:"userID"`
+	CorrID         string    `msg:"corrID"`
+	Value          string    `msg:"value"`
+	Raw            string    `msg:"Raw"`
+	Canonical      string    `msg:"canonical"`
+	ExpiresAt      time.Time `msg:"expires_at"`
+	ActivateAt     time.Time `msg:"activate_at"`
+	DeactivateAt   time.Time `msg:"deactivate_at"`
+	MaxVisits      int       `msg:"max_visits"`
+	Visits         int       `msg:"visits"`
+	PasswordHash   string    `msg:"password_hash"`
+	RedirectStatus int       `msg:"redirect_status"`
+	CachePolicy    string    `msg:"cache_policy"`
+	QueryMode      string    `msg:"query_mode"`
+	FixedParams    string    `msg:"fixed_params"`
+	ForwardPath    bool      `msg:"forward_path"`
+	IsDeleted      bool      `msg:"is_deleted"`
+	Tombstone      bool      `msg:"tombstone"`
+	Counter        bool      `msg:"counter"`
+	Edit           bool      `msg:"edit"`
+	Sequence       uint64    `msg:"sequence"`
+	Version        int       `msg:"version"`
+	Old            string    `msg:"old"`
+	ChangedAt      time.Time `msg:"changed_at"`
 }
 
 // newShortenedURL returns a new ShortenedURL from model.
 func newShortenedURL(s models.ShortenedURL) ShortenedURL {
 	return ShortenedURL{
-		UserID:    s.UserID,
-		CorrID:    s.CorrID,
-		Raw:       s.Raw,
+		UserID:         s.UserID,
+		CorrID:         s.CorrID,
+		Raw:            s.Raw,
+		Canonical:      s.Canonical,
+		Slug:           s.Slug,
+		Value:          s.Value,
+		ExpiresAt:      s.ExpiresAt,
+		ActivateAt:     s.ActivateAt,
+		DeactivateAt:   s.DeactivateAt,
+		MaxVisits:      s.MaxVisits,
+		Visits:         s.Visits,
+		PasswordHash:   s.PasswordHash,
+		RedirectStatus: s.RedirectStatus,
+		CachePolicy:    s.CachePolicy,
+		QueryMode:      s.QueryMode,
+		FixedParams:    s.FixedParams,
+		ForwardPath:    s.ForwardPath,
+		IsDeleted:      s.IsDeleted,
+	}
+}
+
+// newCounter returns a new counter record. The counter sets the number of the counted
+// visits of the previously written record with the same slug.
+func newCounter(slug string, visits int) ShortenedURL {
+	return ShortenedURL{
+		Slug:    slug,
+		Visits:  visits,
+		Counter: true,
+	}
+}
+
+// newTombstone returns a new tombstone record. The tombstone marks
+// the previously written record with the same slug and userID as deleted.
+func newTombstone(userID, slug string) ShortenedURL {
+	return ShortenedURL{
+		UserID:    userID,
+		Slug:      slug,
+		IsDeleted: true,
+		Tombstone: true,
+	}
+}
+
+// newSequence returns a new sequence record. The opened storage continues
+// the counter slug sequence after the greatest written value.
+func newSequence(seq uint64) ShortenedURL {
+	return ShortenedURL{Sequence: seq}
+}
+
+// newEdit returns a new edit record. The edit changes the destination
+// of the previously written record with the same slug and userID.
+func newEdit(c models.URLChange) ShortenedURL {
+	return ShortenedURL{
+		Slug:      c.Slug,
+		UserID:    c.UserID,
+		Raw:       c.New,
+		Canonical: c.Canonical,
+		Old:       c.Old,
+		Version:   c.Version,
+		ChangedAt: c.ChangedAt,
+		Edit:      true,
+	}
+}
+
+// change returns the destination change of the edit record.
+func (s *ShortenedURL) change() models.URLChange {
+	return models.URLChange{
 		Slug:      s.Slug,
-		Value:     s.Value,
-		IsDeleted: s.IsDeleted,
+		Version:   s.Version,
+		Old:       s.Old,
+		New:       s.Raw,
+		Canonical: s.Canonical,
+		UserID:    s.UserID,
+		ChangedAt: utc(s.ChangedAt),
 	}
 }
 
 // ToModel converts ShortenedURL to model.ShortenedURL.
 func (s *ShortenedURL) ToModel() models.ShortenedURL {
 	return models.ShortenedURL{
-		UserID:    s.UserID,
-		CorrID:    s.CorrID,
-		Raw:       s.Raw,
-		Slug:      s.Slug,
-		Value:     s.Value,
-		IsDeleted: s.IsDeleted,
+		UserID:         s.UserID,
+		CorrID:         s.CorrID,
+		Raw:            s.Raw,
+		Canonical:      s.Canonical,
+		Slug:           s.Slug,
+		Value:          s.Value,
+		ExpiresAt:      utc(s.ExpiresAt),
+		ActivateAt:     utc(s.ActivateAt),
+		DeactivateAt:   utc(s.DeactivateAt),
+		MaxVisits:      s.MaxVisits,
+		Visits:         s.Visits,
+		PasswordHash:   s.PasswordHash,
+		RedirectStatus: s.RedirectStatus,
+		CachePolicy:    s.CachePolicy,
+		QueryMode:      s.QueryMode,
+		FixedParams:    s.FixedParams,
+		ForwardPath:    s.ForwardPath,
+		IsDeleted:      s.IsDeleted,
+	}
+}
+
+// utc returns the time in UTC. The decoded zero time is returned
+// as time.Time{}, since it is decoded with a location.
+func utc(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Time{}
 	}
+	return t.UTC()
 }
 
 // shortenedURLs is the set of ShortenedURL.
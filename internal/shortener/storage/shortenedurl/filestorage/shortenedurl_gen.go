@@ -54,12 +54,126 @@ func (z *ShortenedURL) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Raw")
 				return
 			}
+		case "canonical":
+			z.Canonical, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Canonical")
+				return
+			}
+		case "expires_at":
+			z.ExpiresAt, err = dc.ReadTime()
+			if err != nil {
+				err = msgp.WrapError(err, "ExpiresAt")
+				return
+			}
+		case "activate_at":
+			z.ActivateAt, err = dc.ReadTime()
+			if err != nil {
+				err = msgp.WrapError(err, "ActivateAt")
+				return
+			}
+		case "deactivate_at":
+			z.DeactivateAt, err = dc.ReadTime()
+			if err != nil {
+				err = msgp.WrapError(err, "DeactivateAt")
+				return
+			}
+		case "max_visits":
+			z.MaxVisits, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "MaxVisits")
+				return
+			}
+		case "visits":
+			z.Visits, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Visits")
+				return
+			}
+		case "password_hash":
+			z.PasswordHash, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "PasswordHash")
+				return
+			}
+		case "redirect_status":
+			z.RedirectStatus, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "RedirectStatus")
+				return
+			}
+		case "cache_policy":
+			z.CachePolicy, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "CachePolicy")
+				return
+			}
+		case "query_mode":
+			z.QueryMode, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "QueryMode")
+				return
+			}
+		case "fixed_params":
+			z.FixedParams, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "FixedParams")
+				return
+			}
+		case "forward_path":
+			z.ForwardPath, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "ForwardPath")
+				return
+			}
 		case "is_deleted":
 			z.IsDeleted, err = dc.ReadBool()
 			if err != nil {
 				err = msgp.WrapError(err, "IsDeleted")
 				return
 			}
+		case "tombstone":
+			z.Tombstone, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Tombstone")
+				return
+			}
+		case "counter":
+			z.Counter, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Counter")
+				return
+			}
+		case "edit":
+			z.Edit, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Edit")
+				return
+			}
+		case "sequence":
+			z.Sequence, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Sequence")
+				return
+			}
+		case "version":
+			z.Version, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Version")
+				return
+			}
+		case "old":
+			z.Old, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Old")
+				return
+			}
+		case "changed_at":
+			z.ChangedAt, err = dc.ReadTime()
+			if err != nil {
+				err = msgp.WrapError(err, "ChangedAt")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -73,9 +187,9 @@ func (z *ShortenedURL) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *ShortenedURL) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 6
+	// map header, size 25
 	// write "slug"
-	err = en.Append(0x86, 0xa4, 0x73, 0x6c, 0x75, 0x67)
+	err = en.Append(0xde, 0x0, 0x19, 0xa4, 0x73, 0x6c, 0x75, 0x67)
 	if err != nil {
 		return
 	}
@@ -124,6 +238,126 @@ func (z *ShortenedURL) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Raw")
 		return
 	}
+	// write "canonical"
+	err = en.Append(0xa9, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Canonical)
+	if err != nil {
+		err = msgp.WrapError(err, "Canonical")
+		return
+	}
+	// write "expires_at"
+	err = en.Append(0xaa, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.ExpiresAt)
+	if err != nil {
+		err = msgp.WrapError(err, "ExpiresAt")
+		return
+	}
+	// write "activate_at"
+	err = en.Append(0xab, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f, 0x61, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.ActivateAt)
+	if err != nil {
+		err = msgp.WrapError(err, "ActivateAt")
+		return
+	}
+	// write "deactivate_at"
+	err = en.Append(0xad, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f, 0x61, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.DeactivateAt)
+	if err != nil {
+		err = msgp.WrapError(err, "DeactivateAt")
+		return
+	}
+	// write "max_visits"
+	err = en.Append(0xaa, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x69, 0x73, 0x69, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.MaxVisits)
+	if err != nil {
+		err = msgp.WrapError(err, "MaxVisits")
+		return
+	}
+	// write "visits"
+	err = en.Append(0xa6, 0x76, 0x69, 0x73, 0x69, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Visits)
+	if err != nil {
+		err = msgp.WrapError(err, "Visits")
+		return
+	}
+	// write "password_hash"
+	err = en.Append(0xad, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x5f, 0x68, 0x61, 0x73, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.PasswordHash)
+	if err != nil {
+		err = msgp.WrapError(err, "PasswordHash")
+		return
+	}
+	// write "redirect_status"
+	err = en.Append(0xaf, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.RedirectStatus)
+	if err != nil {
+		err = msgp.WrapError(err, "RedirectStatus")
+		return
+	}
+	// write "cache_policy"
+	err = en.Append(0xac, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.CachePolicy)
+	if err != nil {
+		err = msgp.WrapError(err, "CachePolicy")
+		return
+	}
+	// write "query_mode"
+	err = en.Append(0xaa, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.QueryMode)
+	if err != nil {
+		err = msgp.WrapError(err, "QueryMode")
+		return
+	}
+	// write "fixed_params"
+	err = en.Append(0xac, 0x66, 0x69, 0x78, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.FixedParams)
+	if err != nil {
+		err = msgp.WrapError(err, "FixedParams")
+		return
+	}
+	// write "forward_path"
+	err = en.Append(0xac, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.ForwardPath)
+	if err != nil {
+		err = msgp.WrapError(err, "ForwardPath")
+		return
+	}
 	// write "is_deleted"
 	err = en.Append(0xaa, 0x69, 0x73, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64)
 	if err != nil {
@@ -134,15 +368,85 @@ func (z *ShortenedURL) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "IsDeleted")
 		return
 	}
+	// write "tombstone"
+	err = en.Append(0xa9, 0x74, 0x6f, 0x6d, 0x62, 0x73, 0x74, 0x6f, 0x6e, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Tombstone)
+	if err != nil {
+		err = msgp.WrapError(err, "Tombstone")
+		return
+	}
+	// write "counter"
+	err = en.Append(0xa7, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Counter)
+	if err != nil {
+		err = msgp.WrapError(err, "Counter")
+		return
+	}
+	// write "edit"
+	err = en.Append(0xa4, 0x65, 0x64, 0x69, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Edit)
+	if err != nil {
+		err = msgp.WrapError(err, "Edit")
+		return
+	}
+	// write "sequence"
+	err = en.Append(0xa8, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Sequence)
+	if err != nil {
+		err = msgp.WrapError(err, "Sequence")
+		return
+	}
+	// write "version"
+	err = en.Append(0xa7, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Version)
+	if err != nil {
+		err = msgp.WrapError(err, "Version")
+		return
+	}
+	// write "old"
+	err = en.Append(0xa3, 0x6f, 0x6c, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Old)
+	if err != nil {
+		err = msgp.WrapError(err, "Old")
+		return
+	}
+	// write "changed_at"
+	err = en.Append(0xaa, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x5f, 0x61, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.ChangedAt)
+	if err != nil {
+		err = msgp.WrapError(err, "ChangedAt")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *ShortenedURL) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 6
+	// map header, size 25
 	// string "slug"
-	o = append(o, 0x86, 0xa4, 0x73, 0x6c, 0x75, 0x67)
+	o = append(o, 0xde, 0x0, 0x19, 0xa4, 0x73, 0x6c, 0x75, 0x67)
 	o = msgp.AppendString(o, z.Slug)
 	// string "userID"
 	o = append(o, 0xa6, 0x75, 0x73, 0x65, 0x72, 0x49, 0x44)
@@ -156,9 +460,66 @@ func (z *ShortenedURL) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Raw"
 	o = append(o, 0xa3, 0x52, 0x61, 0x77)
 	o = msgp.AppendString(o, z.Raw)
+	// string "canonical"
+	o = append(o, 0xa9, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c)
+	o = msgp.AppendString(o, z.Canonical)
+	// string "expires_at"
+	o = append(o, 0xaa, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74)
+	o = msgp.AppendTime(o, z.ExpiresAt)
+	// string "activate_at"
+	o = append(o, 0xab, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f, 0x61, 0x74)
+	o = msgp.AppendTime(o, z.ActivateAt)
+	// string "deactivate_at"
+	o = append(o, 0xad, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f, 0x61, 0x74)
+	o = msgp.AppendTime(o, z.DeactivateAt)
+	// string "max_visits"
+	o = append(o, 0xaa, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x69, 0x73, 0x69, 0x74, 0x73)
+	o = msgp.AppendInt(o, z.MaxVisits)
+	// string "visits"
+	o = append(o, 0xa6, 0x76, 0x69, 0x73, 0x69, 0x74, 0x73)
+	o = msgp.AppendInt(o, z.Visits)
+	// string "password_hash"
+	o = append(o, 0xad, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x5f, 0x68, 0x61, 0x73, 0x68)
+	o = msgp.AppendString(o, z.PasswordHash)
+	// string "redirect_status"
+	o = append(o, 0xaf, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73)
+	o = msgp.AppendInt(o, z.RedirectStatus)
+	// string "cache_policy"
+	o = append(o, 0xac, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79)
+	o = msgp.AppendString(o, z.CachePolicy)
+	// string "query_mode"
+	o = append(o, 0xaa, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65)
+	o = msgp.AppendString(o, z.QueryMode)
+	// string "fixed_params"
+	o = append(o, 0xac, 0x66, 0x69, 0x78, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73)
+	o = msgp.AppendString(o, z.FixedParams)
+	// string "forward_path"
+	o = append(o, 0xac, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68)
+	o = msgp.AppendBool(o, z.ForwardPath)
 	// string "is_deleted"
 	o = append(o, 0xaa, 0x69, 0x73, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64)
 	o = msgp.AppendBool(o, z.IsDeleted)
+	// string "tombstone"
+	o = append(o, 0xa9, 0x74, 0x6f, 0x6d, 0x62, 0x73, 0x74, 0x6f, 0x6e, 0x65)
+	o = msgp.AppendBool(o, z.Tombstone)
+	// string "counter"
+	o = append(o, 0xa7, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72)
+	o = msgp.AppendBool(o, z.Counter)
+	// string "edit"
+	o = append(o, 0xa4, 0x65, 0x64, 0x69, 0x74)
+	o = msgp.AppendBool(o, z.Edit)
+	// string "sequence"
+	o = append(o, 0xa8, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65)
+	o = msgp.AppendUint64(o, z.Sequence)
+	// string "version"
+	o = append(o, 0xa7, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e)
+	o = msgp.AppendInt(o, z.Version)
+	// string "old"
+	o = append(o, 0xa3, 0x6f, 0x6c, 0x64)
+	o = msgp.AppendString(o, z.Old)
+	// string "changed_at"
+	o = append(o, 0xaa, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x5f, 0x61, 0x74)
+	o = msgp.AppendTime(o, z.ChangedAt)
 	return
 }
 
@@ -210,12 +571,126 @@ func (z *ShortenedURL) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Raw")
 				return
 			}
+		case "canonical":
+			z.Canonical, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Canonical")
+				return
+			}
+		case "expires_at":
+			z.ExpiresAt, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ExpiresAt")
+				return
+			}
+		case "activate_at":
+			z.ActivateAt, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ActivateAt")
+				return
+			}
+		case "deactivate_at":
+			z.DeactivateAt, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "DeactivateAt")
+				return
+			}
+		case "max_visits":
+			z.MaxVisits, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "MaxVisits")
+				return
+			}
+		case "visits":
+			z.Visits, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Visits")
+				return
+			}
+		case "password_hash":
+			z.PasswordHash, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PasswordHash")
+				return
+			}
+		case "redirect_status":
+			z.RedirectStatus, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "RedirectStatus")
+				return
+			}
+		case "cache_policy":
+			z.CachePolicy, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "CachePolicy")
+				return
+			}
+		case "query_mode":
+			z.QueryMode, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "QueryMode")
+				return
+			}
+		case "fixed_params":
+			z.FixedParams, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "FixedParams")
+				return
+			}
+		case "forward_path":
+			z.ForwardPath, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ForwardPath")
+				return
+			}
 		case "is_deleted":
 			z.IsDeleted, bts, err = msgp.ReadBoolBytes(bts)
 			if err != nil {
 				err = msgp.WrapError(err, "IsDeleted")
 				return
 			}
+		case "tombstone":
+			z.Tombstone, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Tombstone")
+				return
+			}
+		case "counter":
+			z.Counter, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Counter")
+				return
+			}
+		case "edit":
+			z.Edit, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Edit")
+				return
+			}
+		case "sequence":
+			z.Sequence, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Sequence")
+				return
+			}
+		case "version":
+			z.Version, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Version")
+				return
+			}
+		case "old":
+			z.Old, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Old")
+				return
+			}
+		case "changed_at":
+			z.ChangedAt, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ChangedAt")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -230,6 +705,6 @@ func (z *ShortenedURL) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *ShortenedURL) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(z.Slug) + 7 + msgp.StringPrefixSize + len(z.UserID) + 7 + msgp.StringPrefixSize + len(z.CorrID) + 6 + msgp.StringPrefixSize + len(z.Value) + 4 + msgp.StringPrefixSize + len(z.Raw) + 11 + msgp.BoolSize
+	s = 3 + 5 + msgp.StringPrefixSize + len(z.Slug) + 7 + msgp.StringPrefixSize + len(z.UserID) + 7 + msgp.StringPrefixSize + len(z.CorrID) + 6 + msgp.StringPrefixSize + len(z.Value) + 4 + msgp.StringPrefixSize + len(z.Raw) + 10 + msgp.StringPrefixSize + len(z.Canonical) + 11 + msgp.TimeSize + 12 + msgp.TimeSize + 14 + msgp.TimeSize + 11 + msgp.IntSize + 7 + msgp.IntSize + 14 + msgp.StringPrefixSize + len(z.PasswordHash) + 16 + msgp.IntSize + 13 + msgp.StringPrefixSize + len(z.CachePolicy) + 11 + msgp.StringPrefixSize + len(z.QueryMode) + 13 + msgp.StringPrefixSize + len(z.FixedParams) + 13 + msgp.BoolSize + 11 + msgp.BoolSize + 10 + msgp.BoolSize + 8 + msgp.BoolSize + 5 + msgp.BoolSize + 9 + msgp.Uint64Size + 8 + msgp.IntSize + 4 + msgp.StringPrefixSize + len(z.Old) + 11 + msgp.TimeSize
 	return
 }
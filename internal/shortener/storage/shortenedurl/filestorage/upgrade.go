@@ -0,0 +1,62 @@
+package filestorage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
+)
+
+// Upgrade rewrites the segments of the older format versions at the storage path
+// into the current format. It returns the number of the upgraded segments.
+//
+// Upgrade is a one-shot routine, the storage must not be open while upgrading.
+// Each segment is written down to a temporary file and replaces the old one by renaming,
+// so an interrupted upgrade leaves every segment either in the old or in the current format.
+func Upgrade(path string) (int, error) {
+	seqs, err := recoverSegments(path)
+	if err != nil {
+		return 0, fmt.Errorf("recover segments: %v", err)
+	}
+	return upgradeSegments(path, seqs)
+}
+
+// upgradeSegments rewrites the segments of the older format versions into the current format.
+func upgradeSegments(base string, seqs []uint64) (int, error) {
+	logger := zerologx.Get()
+
+	var upgraded int
+	for _, seq := range seqs {
+		path := segmentPath(base, seq)
+
+		scan, err := readSegment(path)
+		if err != nil {
+			return upgraded, fmt.Errorf("read segment %v: %v", path, err)
+		}
+		if scan.version == currentFormat {
+			continue
+		}
+
+		// Tombstones are kept, since they may refer to the records of the previous segments.
+		entries := make([]ShortenedURL, len(scan.entries))
+		for i, e := range scan.entries {
+			entries[i] = e.ShortenedURL
+		}
+		if _, err = writeSegment(path+tmpSuffix, seq, entries); err != nil {
+			return upgraded, fmt.Errorf("write segment %v: %v", path, err)
+		}
+		if err = os.Rename(path+tmpSuffix, path); err != nil {
+			return upgraded, err
+		}
+		upgraded++
+
+		logger.Info().
+			Str("segment", path).
+			Uint32("from", scan.version).
+			Uint32("to", currentFormat).
+			Int("entries", len(entries)).
+			Int("skipped", scan.skipped).
+			Msg("filestorage: segment upgraded")
+	}
+	return upgraded, nil
+}
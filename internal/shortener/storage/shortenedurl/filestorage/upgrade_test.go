@@ -0,0 +1,78 @@
+package filestorage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgrade(t *testing.T) {
+	marshal := func(r ShortenedURL) []byte {
+		b, err := r.MarshalMsg(nil)
+		require.NoError(t, err)
+		return b
+	}
+
+	base := filepath.Join(t.TempDir(), "test_upgrade")
+	segments := [][]byte{
+		// formatV0.
+		append(
+			marshal(newShortenedURL(newTestModel("slug1"))),
+			marshal(newShortenedURL(newTestModel("slug2")))...,
+		),
+		// formatV1.
+		appendFrame(
+			appendFrame(nil, marshal(newShortenedURL(newTestModel("slug3")))),
+			marshal(newTombstone("1", "slug1")),
+		),
+		// currentFormat.
+		appendFrame(
+			appendHeader(nil, currentFormat),
+			marshal(newShortenedURL(newTestModel("slug4"))),
+		),
+	}
+	for i, b := range segments {
+		require.NoError(t, os.WriteFile(segmentPath(base, uint64(i+1)), b, 0644))
+	}
+
+	n, err := Upgrade(base)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	for i := range segments {
+		b, err := os.ReadFile(segmentPath(base, uint64(i+1)))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(b, appendHeader(nil, currentFormat)))
+	}
+
+	// Nothing to upgrade.
+	n, err = Upgrade(base)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	r, err := FileStorage(Config{Path: base})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Close())
+	}()
+
+	records, err := r.CollectByUser(context.TODO(), "1")
+	require.NoError(t, err)
+	require.Equal(t, 4, len(records))
+	for i, slug := range []string{"slug1", "slug2", "slug3", "slug4"} {
+		assert.Equal(t, slug, records[i].Slug)
+		assert.Equal(t, i == 0, records[i].IsDeleted)
+	}
+	assert.Equal(t, 3, len(r.segments))
+}
+
+// newTestModel returns a test shortened URL of the user 1 by the slug.
+func newTestModel(slug string) models.ShortenedURL {
+	return models.NewShortenedURL("1", slug, "http://demo.com/"+slug, slug, "http://127.0.0.1/"+slug)
+}
@@ -9,35 +9,113 @@ import (
 
 // writer defines the file writer.
 type writer interface {
-	Write(ShortenedURL) error
+	Write(ShortenedURL) (extent, error)
+	WriteBatch([]ShortenedURL) ([]extent, error)
+	Size() int64
+	Sync() error
 	io.Closer
 }
 
 var _ writer = (*msgpWriter)(nil)
 
+// extent is a position of the entry payload in the file.
+type extent struct {
+	offset int64
+	size   int64
+}
+
 // msgpackWriter is a msgp file writer.
 type msgpWriter struct {
-	file   *os.File
-	writer *msgp.Writer
+	file *os.File
+	size int64
+}
+
+// newMsgpWriter returns a new msgpWriter. The writer appends entries to the end of the file.
+// The segment header is written down to an empty file.
+func newMsgpWriter(f *os.File) (writer, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &msgpWriter{
+		file: f,
+		size: info.Size(),
+	}
+	if w.size == 0 {
+		n, err := f.Write(appendHeader(nil, currentFormat))
+		w.size += int64(n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Write writes a new msgp entry down as a single frame. A successful call returns err == nil.
+func (w *msgpWriter) Write(data ShortenedURL) (extent, error) {
+	payload, err := data.MarshalMsg(nil)
+	if err != nil {
+		return extent{}, err
+	}
+
+	offset := w.size + frameHeaderSize
+	if err = w.writeFrame(payload); err != nil {
+		return extent{}, err
+	}
+	return extent{offset: offset, size: int64(len(payload))}, nil
 }
 
-// newMsgpWriter returns a new msgpWriter.
-func newMsgpWriter(f *os.File) writer {
-	return &msgpWriter{
-		file:   f,
-		writer: msgp.NewWriter(f),
+// WriteBatch writes msgp entries down as a single frame holding the msgp array of them,
+// so the batch is either read back as a whole or not at all. A successful call returns err == nil.
+func (w *msgpWriter) WriteBatch(data []ShortenedURL) ([]extent, error) {
+	payload := msgp.AppendArrayHeader(nil, uint32(len(data)))
+
+	extents := make([]extent, len(data))
+	for i, v := range data {
+		start := len(payload)
+
+		var err error
+		if payload, err = v.MarshalMsg(payload); err != nil {
+			return nil, err
+		}
+		extents[i] = extent{
+			offset: w.size + frameHeaderSize + int64(start),
+			size:   int64(len(payload) - start),
+		}
 	}
+
+	if err := w.writeFrame(payload); err != nil {
+		return nil, err
+	}
+	return extents, nil
 }
 
-// Write writes a new msgp entry down. A successful call returns err == nil.
-func (w *msgpWriter) Write(data ShortenedURL) error {
-	b, err := data.MarshalMsg(nil)
+// writeFrame appends the framed payload to the file. A partially written frame
+// is truncated, so a failed write leaves no trace in the file.
+func (w *msgpWriter) writeFrame(payload []byte) error {
+	n, err := w.file.Write(appendFrame(make([]byte, 0, frameHeaderSize+len(payload)), payload))
 	if err != nil {
+		if n > 0 {
+			if terr := w.file.Truncate(w.size); terr != nil {
+				// The file size is unknown now, the torn tail is truncated at the next start.
+				w.size += int64(n)
+			}
+		}
 		return err
 	}
+	w.size += int64(n)
+	return nil
+}
+
+// Size returns the file size.
+func (w *msgpWriter) Size() int64 {
+	return w.size
+}
 
-	_, err = w.file.Write(b)
-	return err
+// Sync commits the written entries to stable storage.
+func (w *msgpWriter) Sync() error {
+	return w.file.Sync()
 }
 
 // Close closes msgpWriter.
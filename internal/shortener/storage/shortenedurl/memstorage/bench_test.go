@@ -0,0 +1,244 @@
+package memstorage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+)
+
+// benchStorage defines the storage methods under benchmark.
+type benchStorage interface {
+	GetBySlug(context.Context, string) (models.ShortenedURL, error)
+	GetByURL(context.Context, string, string) (models.ShortenedURL, error)
+	Save(context.Context, models.ShortenedURL) error
+	CollectByUser(context.Context, string) ([]models.ShortenedURL, error)
+	Delete(string, []string) error
+}
+
+// mapStorage is the previous implementation of memStorage based on a single map
+// and a single lock. It is kept as the benchmark baseline.
+type mapStorage struct {
+	data map[string]shortenedURL
+	mtx  sync.RWMutex
+}
+
+func newMapStorage() *mapStorage {
+	return &mapStorage{data: make(map[string]shortenedURL, 32)}
+}
+
+func (ms *mapStorage) GetBySlug(_ context.Context, slug string) (models.ShortenedURL, error) {
+	ms.mtx.RLock()
+	defer ms.mtx.RUnlock()
+
+	if v, ok := ms.data[slug]; ok {
+		return v.ToModel(slug), nil
+	}
+	return models.ShortenedURL{}, nil
+}
+
+func (ms *mapStorage) GetByURL(_ context.Context, userID, url string) (models.ShortenedURL, error) {
+	ms.mtx.RLock()
+	defer ms.mtx.RUnlock()
+
+	for slug, v := range ms.data {
+		if v.Raw == url && v.UserID == userID {
+			return v.ToModel(slug), nil
+		}
+	}
+	return models.ShortenedURL{}, nil
+}
+
+func (ms *mapStorage) Save(_ context.Context, data models.ShortenedURL) error {
+	ms.mtx.Lock()
+	defer ms.mtx.Unlock()
+
+	for _, v := range ms.data {
+		if v.Raw == data.Raw && v.UserID == data.UserID {
+			return shortenedurl.ErrUniqueViolation
+		}
+	}
+	ms.data[data.Slug] = newShortenedURL(data)
+	return nil
+}
+
+func (ms *mapStorage) CollectByUser(_ context.Context, userID string) ([]models.ShortenedURL, error) {
+	ms.mtx.RLock()
+	defer ms.mtx.RUnlock()
+
+	records := []models.ShortenedURL{}
+	for slug, v := range ms.data {
+		if v.UserID == userID {
+			records = append(records, v.ToModel(slug))
+		}
+	}
+	return records, nil
+}
+
+func (ms *mapStorage) Delete(userID string, slugs []string) error {
+	const workPoolSize = 10
+
+	slugCh := make(chan string, workPoolSize)
+	go func() {
+		defer close(slugCh)
+		for _, s := range slugs {
+			slugCh <- s
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workPoolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for slug := range slugCh {
+				ms.mtx.Lock()
+				if v, ok := ms.data[slug]; ok && v.UserID == userID {
+					v.SetDeleted()
+					ms.data[slug] = v
+				}
+				ms.mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// benchStorages returns the benchmarked storages by name.
+func benchStorages() map[string]func() benchStorage {
+	return map[string]func() benchStorage{
+		"map":     func() benchStorage { return newMapStorage() },
+		"sharded": func() benchStorage { return MemStorage(shortenedurl.ScopeUser) },
+	}
+}
+
+// benchURLs returns n shortened URLs of n/10 users.
+func benchURLs(n int) []models.ShortenedURL {
+	urls := make([]models.ShortenedURL, n)
+	for i := 0; i < n; i++ {
+		slug := fmt.Sprintf("slug_%d", i)
+		urls[i] = models.NewShortenedURL(
+			fmt.Sprintf("%d", i/10),
+			fmt.Sprintf("%d", i),
+			fmt.Sprintf("http://demo.com/%v", i),
+			slug,
+			"http://127.0.0.1:8080/"+slug,
+		)
+	}
+	return urls
+}
+
+// fillStorage saves urls to the storage.
+func fillStorage(b *testing.B, s benchStorage, urls []models.ShortenedURL) {
+	for _, v := range urls {
+		if err := s.Save(context.TODO(), v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStorages_Save(b *testing.B) {
+	urls := benchURLs(1000)
+
+	for name, newStorage := range benchStorages() {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s := newStorage()
+				b.StartTimer()
+
+				fillStorage(b, s, urls)
+			}
+		})
+	}
+}
+
+func BenchmarkStorages_GetByURL(b *testing.B) {
+	urls := benchURLs(10000)
+
+	for name, newStorage := range benchStorages() {
+		b.Run(name, func(b *testing.B) {
+			s := newStorage()
+			fillStorage(b, s, urls)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				v := urls[i%len(urls)]
+				if _, err := s.GetByURL(context.TODO(), v.UserID, v.Raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStorages_CollectByUser(b *testing.B) {
+	urls := benchURLs(10000)
+
+	for name, newStorage := range benchStorages() {
+		b.Run(name, func(b *testing.B) {
+			s := newStorage()
+			fillStorage(b, s, urls)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := s.CollectByUser(context.TODO(), urls[i%len(urls)].UserID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStorages_Delete(b *testing.B) {
+	urls := benchURLs(10000)
+
+	slugs := make([]string, 10)
+	for i := range slugs {
+		slugs[i] = urls[i].Slug
+	}
+
+	for name, newStorage := range benchStorages() {
+		b.Run(name, func(b *testing.B) {
+			s := newStorage()
+			fillStorage(b, s, urls)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if err := s.Delete(urls[0].UserID, slugs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStorages_Parallel(b *testing.B) {
+	urls := benchURLs(10000)
+
+	for name, newStorage := range benchStorages() {
+		b.Run(name, func(b *testing.B) {
+			s := newStorage()
+			fillStorage(b, s, urls)
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				var i int
+				for pb.Next() {
+					v := urls[i%len(urls)]
+					// One write per ten reads.
+					if i%10 == 0 {
+						_ = s.Delete(v.UserID, []string{v.Slug})
+					} else if _, err := s.GetBySlug(context.TODO(), v.Slug); err != nil {
+						b.Fatal(err)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package memstorage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/caarlos0/env/v6"
+)
+
+// CacheConfig represents the cache configuration.
+type CacheConfig struct {
+	// MaxEntries is the maximum number of the cached slugs.
+	MaxEntries int `env:"CACHE_MAX_ENTRIES" envDefault:"100000"`
+
+	// MaxBytes is the maximum estimated memory size of the cached slugs, zero means no limit.
+	MaxBytes int64 `env:"CACHE_MAX_BYTES" envDefault:"0"`
+
+	// StatInterval is the interval between the cache stats reports.
+	StatInterval time.Duration `env:"CACHE_STAT_INTERVAL" envDefault:"1m"`
+}
+
+// Empty checks on being empty.
+func (c CacheConfig) Empty() bool {
+	return c.MaxEntries == 0 &&
+		c.MaxBytes == 0 &&
+		c.StatInterval == 0
+}
+
+// Default cache settings.
+const (
+	defaultCacheMaxEntries   = 100000
+	defaultCacheStatInterval = time.Minute
+)
+
+// urlProvider defines the provider of the shortened URLs behind the cache.
+type urlProvider interface {
+	GetByURL(context.Context, string, string) (models.ShortenedURL, error)
+	GetBySlug(context.Context, string) (models.ShortenedURL, error)
+	CollectByUser(context.Context, string) ([]models.ShortenedURL, error)
+}
+
+// urlDeleter defines the deleter of the shortened URLs behind the cache.
+type urlDeleter interface {
+	Delete(userID string, slugs []string) error
+}
+
+// urlEditor defines the editor of the shortened URL destinations behind the cache.
+type urlEditor interface {
+	Edit(context.Context, models.URLChange) (models.URLChange, error)
+	History(context.Context, string, string) ([]models.URLChange, error)
+}
+
+// CacheStats represents the cache counters.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Bytes     int64
+}
+
+// cache is a read-through cache of the shortened URLs by slug in front of a provider.
+// The slugs are invalidated on deletion and editing through the cache.
+type cache struct {
+	lru      *lru
+	provider urlProvider
+	deleter  urlDeleter
+	editor   urlEditor
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Cache returns a new cache in front of the provider, the deleter and the editor.
+func Cache(cfg CacheConfig, provider urlProvider, deleter urlDeleter, editor urlEditor) (*cache, error) {
+	if provider == nil || deleter == nil || editor == nil {
+		return nil, fmt.Errorf("url provider, deleter or editor is nil")
+	}
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if cfg.MaxEntries <= 0 && cfg.MaxBytes <= 0 {
+		cfg.MaxEntries = defaultCacheMaxEntries
+	}
+	if cfg.StatInterval <= 0 {
+		cfg.StatInterval = defaultCacheStatInterval
+	}
+
+	c := &cache{
+		lru:      newLRU(cfg.MaxEntries, cfg.MaxBytes),
+		provider: provider,
+		deleter:  deleter,
+		editor:   editor,
+		done:     make(chan struct{}),
+	}
+
+	c.wg.Add(1)
+	go c.reporter(cfg.StatInterval)
+
+	return c, nil
+}
+
+// GetBySlug finds the URL by slug. The URL is read from the provider
+// and cached if it is not cached yet.
+func (c *cache) GetBySlug(ctx context.Context, slug string) (models.ShortenedURL, error) {
+	if v, ok := c.lru.get(slug); ok {
+		c.hits.Add(1)
+		return v.ToModel(slug), nil
+	}
+	c.misses.Add(1)
+
+	epoch := c.lru.currentEpoch()
+	r, err := c.provider.GetBySlug(ctx, slug)
+	if err != nil || len(r.Slug) == 0 {
+		return r, err
+	}
+
+	if n := c.lru.add(slug, newShortenedURL(r), epoch); n > 0 {
+		c.evictions.Add(uint64(n))
+	}
+	return r, nil
+}
+
+// GetByURL returns a shortenedURL by original URL from the provider.
+func (c *cache) GetByURL(ctx context.Context, userID, url string) (models.ShortenedURL, error) {
+	return c.provider.GetByURL(ctx, userID, url)
+}
+
+// CollectByUser collects user shortenedURLs from the provider.
+func (c *cache) CollectByUser(ctx context.Context, userID string) ([]models.ShortenedURL, error) {
+	return c.provider.CollectByUser(ctx, userID)
+}
+
+// Delete marks urls as deleted and invalidates them.
+func (c *cache) Delete(userID string, slugs []string) error {
+	err := c.deleter.Delete(userID, slugs)
+	c.lru.remove(slugs...)
+	return err
+}
+
+// Edit changes the destination of the URL and invalidates it.
+func (c *cache) Edit(ctx context.Context, change models.URLChange) (models.URLChange, error) {
+	edited, err := c.editor.Edit(ctx, change)
+	c.lru.remove(change.Slug)
+	return edited, err
+}
+
+// History returns the destination changes of the URL from the editor.
+func (c *cache) History(ctx context.Context, userID, slug string) ([]models.URLChange, error) {
+	return c.editor.History(ctx, userID, slug)
+}
+
+// Stats returns the cache counters.
+func (c *cache) Stats() CacheStats {
+	entries, bytes := c.lru.len()
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   entries,
+		Bytes:     bytes,
+	}
+}
+
+// reporter logs the cache counters periodically until the cache is closed.
+func (c *cache) reporter(interval time.Duration) {
+	defer c.wg.Done()
+
+	logger := zerologx.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			stats := c.Stats()
+			logger.Info().
+				Uint64("hits", stats.Hits).
+				Uint64("misses", stats.Misses).
+				Uint64("evictions", stats.Evictions).
+				Int("entries", stats.Entries).
+				Int64("bytes", stats.Bytes).
+				Msg("memstorage: cache stats")
+		}
+	}
+}
+
+// Close stops the cache stats reports.
+func (c *cache) Close() error {
+	close(c.done)
+	c.wg.Wait()
+	return nil
+}
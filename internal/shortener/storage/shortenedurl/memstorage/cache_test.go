@@ -0,0 +1,112 @@
+package memstorage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerMock struct {
+	GetBySlugFn func(context.Context, string) (models.ShortenedURL, error)
+}
+
+func (m *providerMock) GetBySlug(ctx context.Context, slug string) (models.ShortenedURL, error) {
+	if m != nil && m.GetBySlugFn != nil {
+		return m.GetBySlugFn(ctx, slug)
+	}
+	return models.ShortenedURL{}, fmt.Errorf("unable to get by slug")
+}
+
+func (m *providerMock) GetByURL(context.Context, string, string) (models.ShortenedURL, error) {
+	return models.ShortenedURL{}, fmt.Errorf("unable to get by URL")
+}
+
+func (m *providerMock) CollectByUser(context.Context, string) ([]models.ShortenedURL, error) {
+	return nil, fmt.Errorf("unable to collect")
+}
+
+type deleterMock struct {
+	DeleteFn func(string, []string) error
+}
+
+func (m *deleterMock) Delete(userID string, slugs []string) error {
+	if m != nil && m.DeleteFn != nil {
+		return m.DeleteFn(userID, slugs)
+	}
+	return fmt.Errorf("unable to delete")
+}
+
+func TestCache(t *testing.T) {
+	// The storage behind the cache.
+	storage := MemStorage(shortenedurl.ScopeUser)
+	require.NoError(t, storage.Save(context.TODO(), newTestModel("1", "slug1")))
+	require.NoError(t, storage.Save(context.TODO(), newTestModel("1", "slug2")))
+
+	var reads int
+	provider := &providerMock{
+		GetBySlugFn: func(ctx context.Context, slug string) (models.ShortenedURL, error) {
+			reads++
+			return storage.GetBySlug(ctx, slug)
+		},
+	}
+
+	c, err := Cache(CacheConfig{MaxEntries: 1, StatInterval: time.Hour}, provider, storage, storage)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, c.Close())
+	}()
+
+	get := func(slug string) models.ShortenedURL {
+		v, err := c.GetBySlug(context.TODO(), slug)
+		require.NoError(t, err)
+		return v
+	}
+
+	// Miss, then hit.
+	assert.Equal(t, "slug1", get("slug1").Slug)
+	assert.Equal(t, "slug1", get("slug1").Slug)
+	assert.Equal(t, 1, reads)
+
+	// Unknown slugs are not cached.
+	assert.Empty(t, get("unknown").Slug)
+	assert.Empty(t, get("unknown").Slug)
+	assert.Equal(t, 3, reads)
+
+	// slug2 evicts slug1.
+	assert.Equal(t, "slug2", get("slug2").Slug)
+	assert.Equal(t, "slug1", get("slug1").Slug)
+	assert.Equal(t, 5, reads)
+
+	// Deletion invalidates the slug.
+	require.NoError(t, c.Delete("1", []string{"slug1"}))
+	assert.True(t, get("slug1").IsDeleted)
+	assert.Equal(t, 6, reads)
+
+	// Editing invalidates the slug.
+	assert.Equal(t, "slug2", get("slug2").Slug)
+	_, err = c.Edit(context.TODO(), models.URLChange{Slug: "slug2", UserID: "1", New: "http://demo.com/new"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://demo.com/new", get("slug2").Raw)
+	assert.Equal(t, 8, reads)
+
+	edited := newTestModel("1", "slug2")
+	edited.Raw = "http://demo.com/new"
+	assert.Equal(t, CacheStats{
+		Hits:      1,
+		Misses:    8,
+		Evictions: 3,
+		Entries:   1,
+		Bytes:     sizeOf("slug2", newShortenedURL(edited)),
+	}, c.Stats())
+}
+
+func TestCache_Nil(t *testing.T) {
+	_, err := Cache(CacheConfig{MaxEntries: 1}, nil, &deleterMock{}, MemStorage(shortenedurl.ScopeUser))
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package memstorage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	for _, scope := range []shortenedurl.Scope{shortenedurl.ScopeUser, shortenedurl.ScopeGlobal} {
+		t.Run(string(scope), func(t *testing.T) {
+			storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+				return MemStorage(scope)
+			}, storagetest.Options{Scope: scope})
+		})
+	}
+}
+
+func TestConformance_Persistent(t *testing.T) {
+	for _, scope := range []shortenedurl.Scope{shortenedurl.ScopeUser, shortenedurl.ScopeGlobal} {
+		t.Run(string(scope), func(t *testing.T) {
+			closed := make(map[*memStorage]bool)
+			open := func(t *testing.T, path string) *memStorage {
+				ms, err := PersistentMemStorage(Config{
+					SnapshotPath:     path,
+					SnapshotInterval: time.Hour,
+					WAL:              true,
+					Scope:            scope,
+				})
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					if !closed[ms] {
+						require.NoError(t, ms.Close())
+					}
+				})
+				return ms
+			}
+
+			storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+				return open(t, filepath.Join(t.TempDir(), "snapshot"))
+			}, storagetest.Options{
+				Scope: scope,
+				Reopen: func(t *testing.T, s storagetest.Storage) storagetest.Storage {
+					ms := s.(*memStorage)
+					require.NoError(t, ms.Close())
+					closed[ms] = true
+					return open(t, ms.snapshotPath)
+				},
+			})
+		})
+	}
+}
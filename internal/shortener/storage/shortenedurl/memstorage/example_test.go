@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 )
 
 func Example() {
@@ -34,7 +35,7 @@ func Example() {
 		},
 	}
 
-	repo := MemStorage()
+	repo := MemStorage(shortenedurl.ScopeUser)
 
 	for _, v := range urls {
 		shortenedURL := models.NewShortenedURL(
@@ -60,5 +61,5 @@ func Example() {
 	}
 
 	// Output:
-	// shortenedURL[userID: 1, corrID: 1, raw: http://demo.com, slug: slug1, value: http://localhost:8080/slug1, deleted: false]
+	// shortenedURL[userID: 1, corrID: 1, raw: http://demo.com, canonical: , slug: slug1, value: http://localhost:8080/slug1, expiresAt: , expired: false, activateAt: , deactivateAt: , maxVisits: 0, visits: 0, protected: false, redirectStatus: 0, cachePolicy: , queryMode: , fixedParams: , forwardPath: false, deleted: false]
 }
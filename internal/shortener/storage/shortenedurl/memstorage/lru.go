@@ -0,0 +1,133 @@
+package memstorage
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruEntryOverhead is the estimated memory overhead of an lru entry in bytes.
+const lruEntryOverhead = 128
+
+// lru is a bounded set of shortenedURLs. It evicts the least recently used slugs
+// when the number of entries or their estimated size exceeds the limit.
+// Zero limits are not enforced.
+type lru struct {
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+
+	// epoch is changed by every removal, so the values read before it are not added.
+	epoch uint64
+
+	items map[string]*list.Element
+	order *list.List // front is the most recently used
+	mtx   sync.Mutex
+}
+
+// lruItem is an entry of lru.
+type lruItem struct {
+	slug string
+	v    shortenedURL
+	size int64
+}
+
+// newLRU returns a new empty lru.
+func newLRU(maxEntries int, maxBytes int64) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// sizeOf returns the estimated memory size of the entry.
+func sizeOf(slug string, v shortenedURL) int64 {
+	return int64(lruEntryOverhead + 2*len(slug) + len(v.UserID) + len(v.CorrID) + len(v.Raw) + len(v.Canonical) + len(v.Value))
+}
+
+// get returns the slug value and marks it as recently used.
+func (c *lru) get(slug string) (shortenedURL, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.items[slug]
+	if !ok {
+		return shortenedURL{}, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruItem).v, true
+}
+
+// currentEpoch returns the current removal epoch.
+func (c *lru) currentEpoch() uint64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.epoch
+}
+
+// add adds the slug value read at the epoch. The value is dropped if any slug
+// has been removed since then, since it may be stale. It returns the number of evicted slugs.
+func (c *lru) add(slug string, v shortenedURL, epoch uint64) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if epoch != c.epoch {
+		return 0
+	}
+
+	item := &lruItem{slug: slug, v: v, size: sizeOf(slug, v)}
+	if e, ok := c.items[slug]; ok {
+		c.bytes += item.size - e.Value.(*lruItem).size
+		e.Value = item
+		c.order.MoveToFront(e)
+	} else {
+		c.items[slug] = c.order.PushFront(item)
+		c.bytes += item.size
+	}
+
+	var evicted int
+	for c.overflow() {
+		c.removeElement(c.order.Back())
+		evicted++
+	}
+	return evicted
+}
+
+// overflow checks whether the limits are exceeded. The most recent entry is never evicted.
+func (c *lru) overflow() bool {
+	if c.order.Len() <= 1 {
+		return false
+	}
+	return (c.maxEntries > 0 && c.order.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// remove removes the slugs.
+func (c *lru) remove(slugs ...string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.epoch++
+	for _, slug := range slugs {
+		if e, ok := c.items[slug]; ok {
+			c.removeElement(e)
+		}
+	}
+}
+
+// removeElement removes the list element. The caller must hold c.mtx.
+func (c *lru) removeElement(e *list.Element) {
+	item := c.order.Remove(e).(*lruItem)
+	delete(c.items, item.slug)
+	c.bytes -= item.size
+}
+
+// len returns the number of entries and their estimated size.
+func (c *lru) len() (int, int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.order.Len(), c.bytes
+}
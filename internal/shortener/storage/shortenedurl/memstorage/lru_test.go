@@ -0,0 +1,98 @@
+package memstorage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	v := shortenedURL{UserID: "1", Raw: "http://demo.com"}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxBytes   int64
+		add        []string
+		get        []string
+		want       []string
+		evicted    int
+	}{
+		{
+			name:       "Within limits",
+			maxEntries: 3,
+			add:        []string{"slug1", "slug2", "slug3"},
+			want:       []string{"slug1", "slug2", "slug3"},
+		},
+		{
+			name:       "Evict by entries",
+			maxEntries: 2,
+			add:        []string{"slug1", "slug2", "slug3"},
+			want:       []string{"slug2", "slug3"},
+			evicted:    1,
+		},
+		{
+			name:       "Evict least recently used",
+			maxEntries: 2,
+			add:        []string{"slug1", "slug2"},
+			get:        []string{"slug1"},
+			want:       []string{"slug1", "slug3"},
+			evicted:    1,
+		},
+		{
+			name:     "Evict by bytes",
+			maxBytes: 2 * sizeOf("slug1", v),
+			add:      []string{"slug1", "slug2", "slug3", "slug4"},
+			want:     []string{"slug3", "slug4"},
+			evicted:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLRU(tt.maxEntries, tt.maxBytes)
+
+			var evicted int
+			for _, slug := range tt.add {
+				evicted += c.add(slug, v, c.currentEpoch())
+			}
+			for _, slug := range tt.get {
+				_, ok := c.get(slug)
+				assert.True(t, ok)
+			}
+			if len(tt.get) > 0 {
+				evicted += c.add("slug3", v, c.currentEpoch())
+			}
+			assert.Equal(t, tt.evicted, evicted)
+
+			entries, _ := c.len()
+			assert.Equal(t, len(tt.want), entries)
+			for _, slug := range tt.want {
+				_, ok := c.get(slug)
+				assert.True(t, ok, fmt.Sprintf("slug %v is evicted", slug))
+			}
+		})
+	}
+}
+
+func TestLRU_Remove(t *testing.T) {
+	c := newLRU(10, 0)
+	v := shortenedURL{UserID: "1", Raw: "http://demo.com"}
+
+	epoch := c.currentEpoch()
+	c.add("slug1", v, epoch)
+	c.remove("slug1")
+
+	_, ok := c.get("slug1")
+	assert.False(t, ok)
+
+	// The value read before the removal is stale.
+	c.add("slug1", v, epoch)
+	_, ok = c.get("slug1")
+	assert.False(t, ok)
+
+	entries, bytes := c.len()
+	assert.Equal(t, 0, entries)
+	assert.Equal(t, int64(0), bytes)
+}
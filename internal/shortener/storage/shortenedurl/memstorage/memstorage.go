@@ -1,77 +1,304 @@
 // Package memstorage defines a shortenedURL memory storage.
+//
+// The storage created by PersistentMemStorage survives restarts: it writes a snapshot
+// periodically and on Close, and restores from the latest one at start. The optional
+// write-ahead log covers the changes between snapshots.
+//
+// Cache is a bounded read-through cache of slugs in front of another storage.
+// It evicts the least recently used slugs and invalidates the deleted and edited ones.
 package memstorage
 
 import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 )
 
-// memStorage defines the shortenedURL storage in memory. It is based on a simple map.
+// memStorage defines the shortenedURL storage in memory. The records are split into shards
+// by slug hash, so writes to different shards do not contend. The URL and user indexes
+// are sharded by their keys the same way.
+//
+// The locks are taken in the order: URL index shard, record shard, user index shard.
+// Several shards of the same kind are locked in the shard order.
 type memStorage struct {
-	data map[string]shortenedURL // slug: shortenedURL
-	mtx  sync.RWMutex
+	records [shardCount]recordShard
+	byURL   [shardCount]indexShard
+	byUser  [shardCount]indexShard
+	scope   shortenedurl.Scope
+
+	// seq is the last value of the counter slug sequence.
+	seq atomic.Uint64
+
+	// persist is held for reading by the writes and for writing by the snapshot.
+	persist      sync.RWMutex
+	snapshotPath string
+	walEnabled   bool
+	wal          *wal
+	walSeq       uint64
+
+	done chan struct{}
+	wg   sync.WaitGroup
 }
 
-// MemStorage returns a new empty memStorage.
-func MemStorage() *memStorage {
-	return &memStorage{
-		data: make(map[string]shortenedURL, 32),
-		mtx:  sync.RWMutex{},
+// MemStorage returns a new empty memStorage. The URLs are unique in the scope by their
+// dedup key, the empty scope is shortenedurl.ScopeUser.
+func MemStorage(scope shortenedurl.Scope) *memStorage {
+	if len(scope) == 0 {
+		scope = shortenedurl.ScopeUser
+	}
+
+	ms := &memStorage{scope: scope}
+	for i := 0; i < shardCount; i++ {
+		ms.records[i].data = make(map[string]shortenedURL)
+		ms.records[i].history = make(map[string][]models.URLChange)
+		ms.byURL[i].slugs = make(map[string][]string)
+		ms.byUser[i].slugs = make(map[string][]string)
 	}
+	return ms
 }
 
-// GetBySlug finds the URL by slug.
-func (ms *memStorage) GetBySlug(_ context.Context, slug string) (models.ShortenedURL, error) {
-	if v, ok := ms.data[slug]; ok {
-		return v.ToModel(slug), nil
+// get returns the record by slug.
+func (ms *memStorage) get(slug string) (shortenedURL, bool) {
+	s := &ms.records[shardOf(slug)]
+
+	s.mtx.RLock()
+	v, ok := s.data[slug]
+	s.mtx.RUnlock()
+	return v, ok
+}
+
+// put puts the record to its shard and adds it to the user index.
+// The caller must hold the lock of the URL index shard.
+func (ms *memStorage) put(data models.ShortenedURL) {
+	s := &ms.records[shardOf(data.Slug)]
+	s.mtx.Lock()
+	ms.store(data)
+	s.mtx.Unlock()
+}
+
+// store puts the record to its shard and adds it to the user index.
+// The caller must hold the lock of the record shard.
+func (ms *memStorage) store(data models.ShortenedURL) {
+	ms.records[shardOf(data.Slug)].data[data.Slug] = newShortenedURL(data)
+
+	u := &ms.byUser[shardOf(data.UserID)]
+	u.mtx.Lock()
+	u.add(data.UserID, data.Slug)
+	u.mtx.Unlock()
+}
+
+// lockRecords locks the record shards of the slugs in order and returns the unlock function.
+func (ms *memStorage) lockRecords(slugs ...string) func() {
+	var shards [shardCount]bool
+	for _, slug := range slugs {
+		shards[shardOf(slug)] = true
+	}
+	for i, ok := range shards {
+		if ok {
+			ms.records[i].mtx.Lock()
+		}
 	}
 
-	return models.ShortenedURL{}, nil
+	return func() {
+		for i, ok := range shards {
+			if ok {
+				ms.records[i].mtx.Unlock()
+			}
+		}
+	}
 }
 
-// GetByURL returns a shortenedURL by original URL.
-func (ms *memStorage) GetByURL(_ context.Context, url string) (models.ShortenedURL, error) {
-	ms.mtx.RLock()
-	defer ms.mtx.RUnlock()
+// taken checks whether any of the slugs is already taken or repeated.
+// The caller must hold the locks of the slug record shards.
+func (ms *memStorage) taken(slugs ...string) bool {
+	seen := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		if _, ok := ms.records[shardOf(slug)].data[slug]; ok || seen[slug] {
+			return true
+		}
+		seen[slug] = true
+	}
+	return false
+}
 
-	for slug, v := range ms.data {
-		if v.Raw == url {
-			return v.ToModel(slug), nil
+// lookup returns the record with the URL that conflicts with a new URL
+// of the user in the uniqueness scope. The URL is compared with the dedup key of the records.
+// The caller must hold the lock of the URL index shard.
+func (ms *memStorage) lookup(userID, url string) (models.ShortenedURL, bool) {
+	s := &ms.byURL[shardOf(url)]
+	for _, slug := range s.slugs[url] {
+		if v, ok := ms.get(slug); ok {
+			if m := v.ToModel(slug); m.DedupKey() == url && ms.scope.Conflicts(v.UserID, userID) {
+				return m, true
+			}
 		}
 	}
+	return models.ShortenedURL{}, false
+}
+
+// insert puts the record to the storage and indexes it.
+func (ms *memStorage) insert(data models.ShortenedURL) {
+	s := &ms.byURL[shardOf(data.DedupKey())]
+	s.mtx.Lock()
+	ms.put(data)
+	s.add(data.DedupKey(), data.Slug)
+	s.mtx.Unlock()
+}
+
+// delete marks the user records as deleted. Slugs are grouped by shards, so each shard is locked once.
+func (ms *memStorage) delete(userID string, slugs []string) {
+	var groups [shardCount][]string
+	for _, slug := range slugs {
+		i := shardOf(slug)
+		groups[i] = append(groups[i], slug)
+	}
+
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+
+		s := &ms.records[i]
+		s.mtx.Lock()
+		for _, slug := range group {
+			if v, ok := s.data[slug]; ok &&
+				v.UserID == userID {
+				v.SetDeleted()
+				s.data[slug] = v
+			}
+		}
+		s.mtx.Unlock()
+	}
+}
+
+// edit changes the destination of the record and indexes it by the new dedup key.
+func (ms *memStorage) edit(c models.URLChange) {
+	s := &ms.byURL[shardOf(c.DedupKey())]
+	s.mtx.Lock()
+	r := &ms.records[shardOf(c.Slug)]
+	r.mtx.Lock()
+	ms.change(c)
+	r.mtx.Unlock()
+	s.add(c.DedupKey(), c.Slug)
+	s.mtx.Unlock()
+}
+
+// change sets the new destination of the record and appends the change to its history.
+// The caller must hold the lock of the record shard.
+func (ms *memStorage) change(c models.URLChange) {
+	s := &ms.records[shardOf(c.Slug)]
+	if v, ok := s.data[c.Slug]; ok {
+		v.Raw = c.New
+		v.Canonical = c.Canonical
+		s.data[c.Slug] = v
+	}
+	s.history[c.Slug] = append(s.history[c.Slug], c)
+}
+
+// changeable checks whether the found record may be changed by the user.
+func changeable(v shortenedURL, ok bool, userID string) error {
+	if !ok || v.IsDeleted {
+		return shortenedurl.ErrNotFound
+	}
+	if v.UserID != userID {
+		return shortenedurl.ErrNotOwner
+	}
+	return nil
+}
+
+// setVisits sets the number of the counted visits of the record.
+func (ms *memStorage) setVisits(slug string, visits int) {
+	s := &ms.records[shardOf(slug)]
+	s.mtx.Lock()
+	if v, ok := s.data[slug]; ok {
+		v.Visits = visits
+		s.data[slug] = v
+	}
+	s.mtx.Unlock()
+}
+
+// log appends the change to the write-ahead log if it is enabled.
+// The caller must hold ms.persist for reading.
+func (ms *memStorage) log(r walRecord) error {
+	if !ms.walEnabled {
+		return nil
+	}
+	if ms.wal == nil {
+		return errors.New("write-ahead log is closed")
+	}
+	return ms.wal.append(r)
+}
+
+// GetBySlug finds the URL by slug.
+func (ms *memStorage) GetBySlug(_ context.Context, slug string) (models.ShortenedURL, error) {
+	if v, ok := ms.get(slug); ok {
+		return v.ToModel(slug), nil
+	}
 
 	return models.ShortenedURL{}, nil
 }
 
-// Save saves the new URL in the repository.
+// GetByURL returns a shortenedURL by the canonical form of the original URL,
+// the records without one are looked up by the original URL. In the user scope only
+// the user URLs are looked up, in the global scope the URL of any user is returned.
+func (ms *memStorage) GetByURL(_ context.Context, userID, url string) (models.ShortenedURL, error) {
+	s := &ms.byURL[shardOf(url)]
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	v, _ := ms.lookup(userID, url)
+	return v, nil
+}
+
+// Save saves the new URL in the repository. The taken slug is reported as ErrSlugCollision.
 func (ms *memStorage) Save(_ context.Context, data models.ShortenedURL) error {
-	ms.mtx.Lock()
-	defer ms.mtx.Unlock()
-	for _, v := range ms.data {
-		if v.Raw == data.Raw && v.UserID == data.UserID {
-			return shortenedurl.ErrUniqueViolation
-		}
+	ms.persist.RLock()
+	defer ms.persist.RUnlock()
+
+	s := &ms.byURL[shardOf(data.DedupKey())]
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, ok := ms.lookup(data.UserID, data.DedupKey()); ok {
+		return shortenedurl.ErrUniqueViolation
+	}
+
+	unlock := ms.lockRecords(data.Slug)
+	defer unlock()
+	if ms.taken(data.Slug) {
+		return shortenedurl.ErrSlugCollision
 	}
 
-	ms.data[data.Slug] = newShortenedURL(data)
+	if err := ms.log(walRecord{Op: walSave, Records: []models.ShortenedURL{data}}); err != nil {
+		return err
+	}
+	ms.store(data)
+	s.add(data.DedupKey(), data.Slug)
 	return nil
 }
 
-// CollectByUser collects user shortenedURLs.
+// CollectByUser collects user shortenedURLs, the expired ones are skipped.
 func (ms *memStorage) CollectByUser(_ context.Context, userID string) ([]models.ShortenedURL, error) {
 	if len(userID) == 0 {
 		return nil, errors.New("userID is empty")
 	}
 
-	ms.mtx.RLock()
+	slugs := ms.byUser[shardOf(userID)].get(userID)
+
 	records := []models.ShortenedURL{}
-	defer ms.mtx.RUnlock()
-	for slug, v := range ms.data {
-		if v.UserID == userID {
+	seen := make(map[string]struct{}, len(slugs))
+	for _, slug := range slugs {
+		if _, ok := seen[slug]; ok {
+			continue
+		}
+		seen[slug] = struct{}{}
+
+		if v, ok := ms.get(slug); ok && v.UserID == userID && !v.IsExpired {
 			records = append(records, v.ToModel(slug))
 		}
 	}
@@ -79,75 +306,226 @@ func (ms *memStorage) CollectByUser(_ context.Context, userID string) ([]models.
 	return records, nil
 }
 
-// Batch performs bulk shortenedURL insertion.
-func (ms *memStorage) Batch(_ context.Context, shortURLs []models.ShortenedURL) error {
-	ms.mtx.Lock()
-	defer ms.mtx.Unlock()
+// Batch performs bulk shortenedURL insertion. The URLs that conflict with the saved ones
+// or with the preceding URLs of the batch are not inserted, the conflicting records
+// are returned for them instead. If any slug of the batch is taken, nothing is inserted.
+func (ms *memStorage) Batch(_ context.Context, shortURLs []models.ShortenedURL) ([]models.BatchedURL, error) {
+	ms.persist.RLock()
+	defer ms.persist.RUnlock()
 
+	// Lock the URL index shards of the batch in order.
+	var shards [shardCount]bool
 	for _, v := range shortURLs {
-		ms.data[v.Slug] = newShortenedURL(v)
+		shards[shardOf(v.DedupKey())] = true
+	}
+	for i, ok := range shards {
+		if ok {
+			ms.byURL[i].mtx.Lock()
+			defer ms.byURL[i].mtx.Unlock()
+		}
 	}
 
-	return nil
-}
+	results := make([]models.BatchedURL, len(shortURLs))
+	created := make([]models.ShortenedURL, 0, len(shortURLs))
+	byURL := make(map[string][]int, len(shortURLs)) // dedup key: created records
+	for i, v := range shortURLs {
+		existed, ok := ms.lookup(v.UserID, v.DedupKey())
+		for _, j := range byURL[v.DedupKey()] {
+			if !ok && ms.scope.Conflicts(created[j].UserID, v.UserID) {
+				existed, ok = created[j], true
+			}
+		}
+		if ok {
+			results[i] = models.NewBatchedURL(existed, models.BatchExisted)
+			continue
+		}
+
+		byURL[v.DedupKey()] = append(byURL[v.DedupKey()], len(created))
+		created = append(created, v)
+		results[i] = models.NewBatchedURL(v, models.BatchCreated)
+	}
+	if len(created) == 0 {
+		return results, nil
+	}
+
+	slugs := make([]string, len(created))
+	for i, v := range created {
+		slugs[i] = v.Slug
+	}
+	unlock := ms.lockRecords(slugs...)
+	defer unlock()
+	if ms.taken(slugs...) {
+		return nil, shortenedurl.ErrSlugCollision
+	}
 
-const workPoolSize = 10
+	if err := ms.log(walRecord{Op: walBatch, Records: created}); err != nil {
+		return nil, err
+	}
+	for _, v := range created {
+		ms.store(v)
+		ms.byURL[shardOf(v.DedupKey())].add(v.DedupKey(), v.Slug)
+	}
+
+	return results, nil
+}
 
 // Delete marks urls as deleted.
 func (ms *memStorage) Delete(userID string, slugs []string) error {
-	// Set the appropriate pool size.
-	wcount := workPoolSize
-	if len(slugs) < wcount && len(slugs) >= 1 {
-		wcount = len(slugs)
+	ms.persist.RLock()
+	defer ms.persist.RUnlock()
+
+	if err := ms.log(walRecord{Op: walDelete, UserID: userID, Slugs: slugs}); err != nil {
+		return err
 	}
+	ms.delete(userID, slugs)
 
-	slugCh := make(chan string, wcount)
-	go func() {
-		defer close(slugCh)
-		for _, s := range slugs {
-			slugCh <- s
-		}
-	}()
-
-	var wg sync.WaitGroup
-	for i := 0; i < wcount; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for slug := range slugCh {
-				ms.mtx.Lock()
-				if v, ok := ms.data[slug]; ok &&
-					v.UserID == userID {
-					v.SetDeleted()
-					ms.data[slug] = v
-				}
-				ms.mtx.Unlock()
+	return nil
+}
+
+// Visit counts a visit of the visit-limited URL and returns the number of the visits left.
+// ErrVisitsExhausted is returned if no visits are left. The URL that is not found
+// or is not visit-limited is not counted, -1 is returned for it.
+func (ms *memStorage) Visit(_ context.Context, slug string) (int, error) {
+	ms.persist.RLock()
+	defer ms.persist.RUnlock()
+
+	s := &ms.records[shardOf(slug)]
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	v, ok := s.data[slug]
+	if !ok || v.MaxVisits <= 0 {
+		return -1, nil
+	}
+	if v.Visits >= v.MaxVisits {
+		return 0, shortenedurl.ErrVisitsExhausted
+	}
+
+	v.Visits++
+	if err := ms.log(walRecord{Op: walVisit, Slugs: []string{slug}, Visits: v.Visits}); err != nil {
+		return 0, err
+	}
+	s.data[slug] = v
+	return v.MaxVisits - v.Visits, nil
+}
+
+// Edit changes the destination of the user's URL and returns the change with the old
+// destination and the new version set. ErrNotFound is returned for the URL that is not found
+// or has been deleted, ErrNotOwner for the URL of another user. The new destination that
+// conflicts with another URL in the uniqueness scope is reported as ErrUniqueViolation,
+// the change that would not get its set version as ErrVersionConflict.
+func (ms *memStorage) Edit(_ context.Context, c models.URLChange) (models.URLChange, error) {
+	ms.persist.RLock()
+	defer ms.persist.RUnlock()
+
+	s := &ms.byURL[shardOf(c.DedupKey())]
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if v, ok := ms.lookup(c.UserID, c.DedupKey()); ok && v.Slug != c.Slug {
+		return models.URLChange{}, shortenedurl.ErrUniqueViolation
+	}
+
+	unlock := ms.lockRecords(c.Slug)
+	defer unlock()
+
+	r := &ms.records[shardOf(c.Slug)]
+	v, ok := r.data[c.Slug]
+	if err := changeable(v, ok, c.UserID); err != nil {
+		return models.URLChange{}, err
+	}
+	version := len(r.history[c.Slug]) + 2
+	if c.Version != 0 && c.Version != version {
+		return models.URLChange{}, shortenedurl.ErrVersionConflict
+	}
+	c.Old = v.Raw
+	c.Version = version
+
+	if err := ms.log(walRecord{Op: walEdit, Changes: []models.URLChange{c}}); err != nil {
+		return models.URLChange{}, err
+	}
+	ms.change(c)
+	s.add(c.DedupKey(), c.Slug)
+	return c, nil
+}
+
+// History returns the destination changes of the user's URL in the version order.
+// ErrNotFound is returned for the URL that is not found or has been deleted,
+// ErrNotOwner for the URL of another user.
+func (ms *memStorage) History(_ context.Context, userID, slug string) ([]models.URLChange, error) {
+	s := &ms.records[shardOf(slug)]
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	v, ok := s.data[slug]
+	if err := changeable(v, ok, userID); err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.URLChange, len(s.history[slug]))
+	copy(changes, s.history[slug])
+	return changes, nil
+}
+
+// Expire marks the URLs expired by the time and returns the number of the marked ones.
+// The marks are not logged, the restored storage marks the URLs again by their expiry time.
+func (ms *memStorage) Expire(_ context.Context, now time.Time) (int, error) {
+	var n int
+	for i := 0; i < shardCount; i++ {
+		s := &ms.records[i]
+		s.mtx.Lock()
+		for slug, v := range s.data {
+			if v.expiring(now) {
+				v.IsExpired = true
+				s.data[slug] = v
+				n++
 			}
-		}()
+		}
+		s.mtx.Unlock()
 	}
-	wg.Wait()
+	return n, nil
+}
 
-	return nil
+// Next returns the next value of the counter slug sequence, the first one is 1.
+// The value is kept by the snapshot and the write-ahead log, so the restored
+// storage never hands out the same value again.
+func (ms *memStorage) Next(_ context.Context) (uint64, error) {
+	ms.persist.RLock()
+	defer ms.persist.RUnlock()
+
+	next := ms.seq.Add(1)
+	if err := ms.log(walRecord{Op: walSequence, Seq: next}); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+// advanceSeq moves the counter slug sequence forward to the value.
+func (ms *memStorage) advanceSeq(seq uint64) {
+	for {
+		last := ms.seq.Load()
+		if seq <= last || ms.seq.CompareAndSwap(last, seq) {
+			return
+		}
+	}
 }
 
 // Stat collects statistics about shortened URLs.
 func (ms *memStorage) Stat(_ context.Context) (models.Stat, error) {
 	var stat models.Stat
 
-	done := make(chan struct{}, 1)
-	go func() {
-		ms.mtx.RLock()
-		stat.URLs = len(ms.data)
-		users := make(map[string]struct{})
-		for _, v := range ms.data {
-			users[v.UserID] = struct{}{}
-		}
-		stat.Users = len(users)
-		ms.mtx.RUnlock()
+	for i := 0; i < shardCount; i++ {
+		s := &ms.records[i]
+		s.mtx.RLock()
+		stat.URLs += len(s.data)
+		s.mtx.RUnlock()
 
-		done <- struct{}{}
-	}()
+		// Each user is indexed in a single shard.
+		u := &ms.byUser[i]
+		u.mtx.RLock()
+		stat.Users += len(u.slugs)
+		u.mtx.RUnlock()
+	}
 
-	<-done
 	return stat, nil
 }
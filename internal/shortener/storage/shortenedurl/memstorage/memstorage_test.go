@@ -3,9 +3,11 @@ package memstorage
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -55,7 +57,7 @@ func TestMemStorage_Save(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			storage := MemStorage()
+			storage := MemStorage(shortenedurl.ScopeUser)
 
 			if tt.urlExistes {
 				existedURL := models.NewShortenedURL(
@@ -111,7 +113,7 @@ func BenchmarkMemStorage_Save(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		storage := MemStorage()
+		storage := MemStorage(shortenedurl.ScopeUser)
 		ctx := context.TODO()
 		b.StartTimer()
 
@@ -174,7 +176,7 @@ func TestMemStorage_GetByURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			storage := MemStorage()
+			storage := MemStorage(shortenedurl.ScopeUser)
 
 			if tt.urlExists {
 				existedURL := models.NewShortenedURL(
@@ -188,7 +190,7 @@ func TestMemStorage_GetByURL(t *testing.T) {
 				require.NoError(t, storage.Save(context.TODO(), existedURL))
 			}
 
-			shortenedURL, err := storage.GetByURL(context.TODO(), tt.args.raw)
+			shortenedURL, err := storage.GetByURL(context.TODO(), tt.args.userID, tt.args.raw)
 			require.NoError(t, err)
 
 			if tt.urlExists {
@@ -241,7 +243,7 @@ func TestMemStorage_GetBySlug(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			storage := MemStorage()
+			storage := MemStorage(shortenedurl.ScopeUser)
 
 			shortenedURL := models.NewShortenedURL(
 				tt.args.userID,
@@ -314,7 +316,7 @@ func TestMemStorage_Collect(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			storage := MemStorage()
+			storage := MemStorage(shortenedurl.ScopeUser)
 
 			for _, v := range tt.existedURLs {
 				shortenedURL := models.NewShortenedURL(
@@ -389,7 +391,7 @@ func TestMemStorage_Batch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			storage := MemStorage()
+			storage := MemStorage(shortenedurl.ScopeUser)
 
 			shortenedURLs := make([]models.ShortenedURL, len(tt.args))
 			for i, v := range tt.args {
@@ -403,7 +405,7 @@ func TestMemStorage_Batch(t *testing.T) {
 				shortenedURLs[i] = shortenedURL
 			}
 
-			err := storage.Batch(context.TODO(), shortenedURLs)
+			_, err := storage.Batch(context.TODO(), shortenedURLs)
 			assert.NoError(t, err)
 		})
 	}
@@ -493,7 +495,7 @@ func TestMemStorage_Delete(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			storage := MemStorage()
+			storage := MemStorage(shortenedurl.ScopeUser)
 
 			var slugs []string
 			for _, v := range tt.args {
@@ -516,7 +518,7 @@ func TestMemStorage_Delete(t *testing.T) {
 			require.NoError(t, err)
 
 			for _, slug := range slugs {
-				if v, ok := storage.data[slug]; ok && !v.IsDeleted {
+				if v, ok := storage.get(slug); ok && !v.IsDeleted {
 					t.Errorf("url with slug: %v not deleted", slug)
 				}
 			}
@@ -555,7 +557,7 @@ func BenchmarkMemStorage_Delete(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		storage := MemStorage()
+		storage := MemStorage(shortenedurl.ScopeUser)
 		b.StartTimer()
 
 		if err := storage.Delete(userID, slugs); err != nil {
@@ -651,7 +653,7 @@ func TestMemStorage_Stat(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			storage := MemStorage()
+			storage := MemStorage(shortenedurl.ScopeUser)
 
 			for _, v := range tt.existedURLs {
 				existedURL := models.NewShortenedURL(
@@ -673,3 +675,44 @@ func TestMemStorage_Stat(t *testing.T) {
 		})
 	}
 }
+
+func TestMemStorage_Concurrent(t *testing.T) {
+	storage := MemStorage(shortenedurl.ScopeUser)
+
+	n := 100
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			userID := fmt.Sprintf("%d", i%10)
+			slug := fmt.Sprintf("slug%d", i)
+			raw := fmt.Sprintf("http://demo.com/%d", i)
+			assert.NoError(t, storage.Save(context.TODO(), models.NewShortenedURL(
+				userID, "1", raw, slug, "http://127.0.0.1/"+slug,
+			)))
+
+			v, err := storage.GetByURL(context.TODO(), userID, raw)
+			assert.NoError(t, err)
+			assert.Equal(t, slug, v.Slug)
+
+			assert.NoError(t, storage.Delete(userID, []string{slug}))
+		}(i)
+	}
+	wg.Wait()
+
+	stat, err := storage.Stat(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, n, stat.URLs)
+	assert.Equal(t, 10, stat.Users)
+
+	for i := 0; i < 10; i++ {
+		records, err := storage.CollectByUser(context.TODO(), fmt.Sprintf("%d", i))
+		require.NoError(t, err)
+		assert.Equal(t, n/10, len(records))
+		for _, v := range records {
+			assert.True(t, v.IsDeleted)
+		}
+	}
+}
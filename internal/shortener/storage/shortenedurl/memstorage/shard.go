@@ -0,0 +1,58 @@
+package memstorage
+
+import (
+	"sync"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+)
+
+// shardCount is the number of the storage shards. It must be a power of two.
+const shardCount = 32
+
+// recordShard is a shard of the shortenedURLs keyed by slug. The destination changes
+// of the records are kept in the same shard in the version order.
+type recordShard struct {
+	data    map[string]shortenedURL       // slug: shortenedURL
+	history map[string][]models.URLChange // slug: changes
+	mtx     sync.RWMutex
+}
+
+// indexShard is a shard of the secondary index. It maps a key, such as the raw URL
+// or the userID, to the slugs of the records in the order they were saved.
+//
+// Records may be overwritten by slug, so the caller must check that the indexed record
+// still matches the key.
+type indexShard struct {
+	slugs map[string][]string // key: slugs
+	mtx   sync.RWMutex
+}
+
+// add adds the slug to the key. The caller must hold s.mtx.
+func (s *indexShard) add(key, slug string) {
+	s.slugs[key] = append(s.slugs[key], slug)
+}
+
+// get returns a copy of the key slugs.
+func (s *indexShard) get(key string) []string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	slugs := make([]string, len(s.slugs[key]))
+	copy(slugs, s.slugs[key])
+	return slugs
+}
+
+// shardOf returns the shard number of the key. It is the FNV-1a hash of the key.
+func shardOf(key string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+	return h & (shardCount - 1)
+}
@@ -1,14 +1,31 @@
 package memstorage
 
-import "github.com/alukart32/shortener-url/internal/shortener/models"
+import (
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+)
 
 // ShortURL represents shortened URL.
 type shortenedURL struct {
-	UserID    string
-	CorrID    string
-	Raw       string
-	Value     string
-	IsDeleted bool
+	UserID         string
+	CorrID         string
+	Raw            string
+	Canonical      string
+	Value          string
+	ExpiresAt      time.Time
+	IsExpired      bool
+	ActivateAt     time.Time
+	DeactivateAt   time.Time
+	MaxVisits      int
+	Visits         int
+	PasswordHash   string
+	RedirectStatus int
+	CachePolicy    string
+	QueryMode      string
+	FixedParams    string
+	ForwardPath    bool
+	IsDeleted      bool
 }
 
 // SetDeleted sets IsDeleted as true.
@@ -16,25 +33,57 @@ func (s *shortenedURL) SetDeleted() {
 	s.IsDeleted = true
 }
 
+// expiring checks whether the record has expired by the time but is not marked yet.
+func (s *shortenedURL) expiring(now time.Time) bool {
+	return !s.IsExpired &&
+		!s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
 // newShortenedURL returns a new shortenedURL from models.ShortenedURL.
 func newShortenedURL(s models.ShortenedURL) shortenedURL {
 	return shortenedURL{
-		UserID:    s.UserID,
-		CorrID:    s.CorrID,
-		Raw:       s.Raw,
-		Value:     s.Value,
-		IsDeleted: s.IsDeleted,
+		UserID:         s.UserID,
+		CorrID:         s.CorrID,
+		Raw:            s.Raw,
+		Canonical:      s.Canonical,
+		Value:          s.Value,
+		ExpiresAt:      s.ExpiresAt,
+		IsExpired:      s.IsExpired,
+		ActivateAt:     s.ActivateAt,
+		DeactivateAt:   s.DeactivateAt,
+		MaxVisits:      s.MaxVisits,
+		Visits:         s.Visits,
+		PasswordHash:   s.PasswordHash,
+		RedirectStatus: s.RedirectStatus,
+		CachePolicy:    s.CachePolicy,
+		QueryMode:      s.QueryMode,
+		FixedParams:    s.FixedParams,
+		ForwardPath:    s.ForwardPath,
+		IsDeleted:      s.IsDeleted,
 	}
 }
 
 // ToModel converts shortenedURL to models.ShortenedURL.
 func (s *shortenedURL) ToModel(slug string) models.ShortenedURL {
 	return models.ShortenedURL{
-		UserID:    s.UserID,
-		CorrID:    s.CorrID,
-		Raw:       s.Raw,
-		Slug:      slug,
-		Value:     s.Value,
-		IsDeleted: s.IsDeleted,
+		UserID:         s.UserID,
+		CorrID:         s.CorrID,
+		Raw:            s.Raw,
+		Canonical:      s.Canonical,
+		Slug:           slug,
+		Value:          s.Value,
+		ExpiresAt:      s.ExpiresAt,
+		IsExpired:      s.IsExpired,
+		ActivateAt:     s.ActivateAt,
+		DeactivateAt:   s.DeactivateAt,
+		MaxVisits:      s.MaxVisits,
+		Visits:         s.Visits,
+		PasswordHash:   s.PasswordHash,
+		RedirectStatus: s.RedirectStatus,
+		CachePolicy:    s.CachePolicy,
+		QueryMode:      s.QueryMode,
+		FixedParams:    s.FixedParams,
+		ForwardPath:    s.ForwardPath,
+		IsDeleted:      s.IsDeleted,
 	}
 }
@@ -0,0 +1,327 @@
+package memstorage
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/pkg/zerologx"
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/caarlos0/env/v6"
+)
+
+// Config represents the memStorage persistence configuration.
+type Config struct {
+	// SnapshotPath is the path of the snapshot file.
+	SnapshotPath string `env:"MEM_STORAGE_SNAPSHOT_PATH"`
+
+	// SnapshotInterval is the interval between snapshots.
+	SnapshotInterval time.Duration `env:"MEM_STORAGE_SNAPSHOT_INTERVAL" envDefault:"5m"`
+
+	// WAL enables the write-ahead log covering the changes between snapshots.
+	WAL bool `env:"MEM_STORAGE_WAL" envDefault:"false"`
+
+	// Scope is the scope of the raw URL uniqueness.
+	Scope shortenedurl.Scope `env:"URL_UNIQUE_SCOPE" envDefault:"user"`
+}
+
+// Empty checks on being empty.
+func (c Config) Empty() bool {
+	return len(c.SnapshotPath) == 0 &&
+		c.SnapshotInterval == 0 &&
+		!c.WAL &&
+		len(c.Scope) == 0
+}
+
+// defaultSnapshotInterval is the default interval between snapshots.
+const defaultSnapshotInterval = 5 * time.Minute
+
+// snapshotTmpSuffix marks the snapshot that is being written.
+const snapshotTmpSuffix = ".tmp"
+
+// snapshot is the state of memStorage written down to disk.
+type snapshot struct {
+	// WALSeq is the sequence number of the first write-ahead log not covered by the snapshot.
+	WALSeq uint64
+	// Seq is the last value of the counter slug sequence.
+	Seq     uint64
+	Records []models.ShortenedURL
+	Changes []models.URLChange
+}
+
+// PersistentMemStorage returns a new memStorage restored from the latest snapshot
+// and the write-ahead log. The snapshot is written down periodically and on Close.
+func PersistentMemStorage(cfg Config) (*memStorage, error) {
+	if cfg.Empty() {
+		opts := env.Options{RequiredIfNoDef: true}
+		if err := env.Parse(&cfg, opts); err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+	}
+	if len(cfg.SnapshotPath) == 0 {
+		return nil, fmt.Errorf("empty snapshot path")
+	}
+	if cfg.SnapshotInterval <= 0 {
+		cfg.SnapshotInterval = defaultSnapshotInterval
+	}
+	scope, err := shortenedurl.ParseScope(string(cfg.Scope))
+	if err != nil {
+		return nil, err
+	}
+
+	ms := MemStorage(scope)
+	ms.snapshotPath = cfg.SnapshotPath
+	ms.walEnabled = cfg.WAL
+	ms.done = make(chan struct{})
+
+	if err := ms.restore(); err != nil {
+		return nil, fmt.Errorf("failed to restore: %v", err)
+	}
+	// The snapshot of the restored state starts a new write-ahead log.
+	if _, err := ms.snapshot(false); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %v", err)
+	}
+
+	ms.wg.Add(1)
+	go ms.snapshotter(cfg.SnapshotInterval)
+
+	return ms, nil
+}
+
+// restore restores the state from the snapshot and replays the write-ahead logs after it.
+func (ms *memStorage) restore() error {
+	logger := zerologx.Get()
+
+	var snap snapshot
+	f, err := os.Open(ms.snapshotPath)
+	switch {
+	case err == nil:
+		err = gob.NewDecoder(f).Decode(&snap)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("decode snapshot: %v", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	for _, r := range snap.Records {
+		ms.insert(r)
+	}
+	// The records hold the changed destinations already.
+	for _, c := range snap.Changes {
+		s := &ms.records[shardOf(c.Slug)]
+		s.history[c.Slug] = append(s.history[c.Slug], c)
+	}
+	ms.walSeq = snap.WALSeq
+
+	seqs, err := walSeqs(ms.snapshotPath)
+	if err != nil {
+		return err
+	}
+	for _, seq := range seqs {
+		if seq < snap.WALSeq {
+			continue
+		}
+
+		n, err := readWAL(walPath(ms.snapshotPath, seq), ms.replay)
+		if err != nil {
+			logger.Warn().Err(err).
+				Str("wal", walPath(ms.snapshotPath, seq)).
+				Int("records", n).
+				Msg("memstorage: torn write-ahead log")
+		}
+		ms.walSeq = seq + 1
+	}
+
+	// The snapshots written before the sequence was kept continue
+	// the counter slugs after the restored records.
+	var n int
+	for i := range ms.records {
+		n += len(ms.records[i].data)
+	}
+	ms.advanceSeq(snap.Seq)
+	ms.advanceSeq(uint64(n))
+
+	return nil
+}
+
+// replay applies the write-ahead log record.
+func (ms *memStorage) replay(r walRecord) {
+	switch r.Op {
+	case walSave, walBatch:
+		for _, v := range r.Records {
+			ms.insert(v)
+		}
+	case walDelete:
+		ms.delete(r.UserID, r.Slugs)
+	case walVisit:
+		for _, slug := range r.Slugs {
+			ms.setVisits(slug, r.Visits)
+		}
+	case walEdit:
+		for _, c := range r.Changes {
+			ms.edit(c)
+		}
+	case walSequence:
+		ms.advanceSeq(r.Seq)
+	}
+}
+
+// snapshotter writes snapshots periodically until the storage is closed.
+func (ms *memStorage) snapshotter(interval time.Duration) {
+	defer ms.wg.Done()
+
+	logger := zerologx.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ms.done:
+			return
+		case <-ticker.C:
+			start := time.Now()
+			n, err := ms.snapshot(false)
+			if err != nil {
+				logger.Error().Err(err).Msg("memstorage: snapshot failed")
+				continue
+			}
+			logger.Info().
+				Int("records", n).
+				Dur("took", time.Since(start)).
+				Msg("memstorage: snapshot written")
+		}
+	}
+}
+
+// snapshot writes the snapshot down and removes the write-ahead logs it covers.
+// It returns the number of the written records.
+//
+// The state is copied with the writes blocked, a new write-ahead log is started at the same time.
+// The final snapshot closes the log instead.
+func (ms *memStorage) snapshot(final bool) (int, error) {
+	ms.persist.Lock()
+	snap := snapshot{
+		WALSeq:  ms.walSeq,
+		Seq:     ms.seq.Load(),
+		Records: ms.dump(),
+		Changes: ms.dumpChanges(),
+	}
+	if ms.wal != nil {
+		err := ms.wal.Close()
+		ms.wal = nil
+		if err != nil {
+			ms.persist.Unlock()
+			return 0, err
+		}
+	}
+	if ms.walEnabled && !final {
+		w, err := createWAL(ms.snapshotPath, ms.walSeq)
+		if err != nil {
+			ms.persist.Unlock()
+			return 0, err
+		}
+		ms.wal = w
+		ms.walSeq++
+	}
+	ms.persist.Unlock()
+
+	if err := writeSnapshot(ms.snapshotPath, snap); err != nil {
+		return 0, err
+	}
+
+	seqs, err := walSeqs(ms.snapshotPath)
+	if err != nil {
+		return 0, err
+	}
+	for _, seq := range seqs {
+		if seq >= snap.WALSeq {
+			break
+		}
+		if err = os.Remove(walPath(ms.snapshotPath, seq)); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(snap.Records), nil
+}
+
+// writeSnapshot writes the snapshot down to a temporary file and replaces the previous one by renaming.
+func writeSnapshot(path string, snap snapshot) error {
+	f, err := os.OpenFile(path+snapshotTmpSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err = gob.NewEncoder(f).Encode(snap); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(path+snapshotTmpSuffix, path)
+}
+
+// dump returns all records. The records of each user are in the order they were saved.
+// The caller must block the writes.
+func (ms *memStorage) dump() []models.ShortenedURL {
+	var records []models.ShortenedURL
+
+	seen := make(map[string]struct{})
+	for i := 0; i < shardCount; i++ {
+		u := &ms.byUser[i]
+		u.mtx.RLock()
+		for userID, slugs := range u.slugs {
+			for _, slug := range slugs {
+				if _, ok := seen[slug]; ok {
+					continue
+				}
+				if v, ok := ms.get(slug); ok && v.UserID == userID {
+					seen[slug] = struct{}{}
+					records = append(records, v.ToModel(slug))
+				}
+			}
+		}
+		u.mtx.RUnlock()
+	}
+
+	return records
+}
+
+// dumpChanges returns the destination changes of all records, the changes
+// of each record are in the version order. The caller must block the writes.
+func (ms *memStorage) dumpChanges() []models.URLChange {
+	var changes []models.URLChange
+	for i := 0; i < shardCount; i++ {
+		s := &ms.records[i]
+		s.mtx.RLock()
+		for _, history := range s.history {
+			changes = append(changes, history...)
+		}
+		s.mtx.RUnlock()
+	}
+	return changes
+}
+
+// Close writes the final snapshot and closes the write-ahead log.
+// It does nothing for the storage without persistence.
+func (ms *memStorage) Close() error {
+	if len(ms.snapshotPath) == 0 {
+		return nil
+	}
+
+	close(ms.done)
+	ms.wg.Wait()
+
+	_, err := ms.snapshot(true)
+	return err
+}
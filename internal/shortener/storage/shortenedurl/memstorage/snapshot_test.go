@@ -0,0 +1,161 @@
+package memstorage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistentMemStorage(t *testing.T) {
+	tests := []struct {
+		name  string
+		wal   bool
+		crash bool
+		torn  bool
+		want  int
+	}{
+		{
+			name: "Snapshot on close",
+			want: 4,
+		},
+		{
+			name: "Snapshot and WAL on close",
+			wal:  true,
+			want: 4,
+		},
+		{
+			name:  "Crash without WAL",
+			crash: true,
+			want:  0,
+		},
+		{
+			name:  "Crash with WAL",
+			wal:   true,
+			crash: true,
+			want:  4,
+		},
+		{
+			name:  "Crash with torn WAL",
+			wal:   true,
+			crash: true,
+			torn:  true,
+			want:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				SnapshotPath:     filepath.Join(t.TempDir(), "snapshot"),
+				SnapshotInterval: time.Hour,
+				WAL:              tt.wal,
+			}
+
+			storage, err := PersistentMemStorage(cfg)
+			require.NoError(t, err)
+
+			limited := newTestModel("1", "slug2")
+			limited.MaxVisits = 3
+			require.NoError(t, storage.Save(context.TODO(), newTestModel("1", "slug1")))
+			require.NoError(t, storage.Save(context.TODO(), limited))
+			_, err = storage.Batch(context.TODO(), []models.ShortenedURL{
+				newTestModel("2", "slug3"),
+				newTestModel("2", "slug4"),
+			})
+			require.NoError(t, err)
+			require.NoError(t, storage.Delete("1", []string{"slug1"}))
+			for i := 0; i < 2; i++ {
+				_, err = storage.Visit(context.TODO(), "slug2")
+				require.NoError(t, err)
+			}
+			_, err = storage.Edit(context.TODO(), models.URLChange{
+				Slug:   "slug4",
+				UserID: "2",
+				New:    "http://demo.com/edited",
+			})
+			require.NoError(t, err)
+			// The sequence values are used up by more URLs than are saved.
+			for i := 0; i < 10; i++ {
+				_, err = storage.Next(context.TODO())
+				require.NoError(t, err)
+			}
+
+			if tt.crash {
+				// Stop the storage without the final snapshot.
+				close(storage.done)
+				storage.wg.Wait()
+				if storage.wal != nil {
+					require.NoError(t, storage.wal.Close())
+				}
+			} else {
+				require.NoError(t, storage.Close())
+			}
+
+			if tt.torn {
+				f, err := os.OpenFile(walPath(cfg.SnapshotPath, storage.walSeq-1), os.O_WRONLY|os.O_APPEND, 0644)
+				require.NoError(t, err)
+				_, err = f.Write([]byte{0x1f, 0xff, 0x81})
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			}
+
+			storage, err = PersistentMemStorage(cfg)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, storage.Close())
+			}()
+
+			stat, err := storage.Stat(context.TODO())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stat.URLs)
+
+			next, err := storage.Next(context.TODO())
+			require.NoError(t, err)
+			if tt.want == 0 {
+				// Nothing is restored after the crash without WAL.
+				assert.Equal(t, uint64(1), next)
+				return
+			}
+			// The counter slug sequence continues after the handed out values.
+			assert.Equal(t, uint64(11), next)
+
+			records, err := storage.CollectByUser(context.TODO(), "1")
+			require.NoError(t, err)
+			require.Equal(t, 2, len(records))
+			assert.Equal(t, "slug1", records[0].Slug)
+			assert.True(t, records[0].IsDeleted)
+			assert.Equal(t, "slug2", records[1].Slug)
+			assert.Equal(t, 1, records[1].VisitsLeft())
+
+			v, err := storage.GetByURL(context.TODO(), "2", "http://demo.com/slug3")
+			require.NoError(t, err)
+			assert.Equal(t, "slug3", v.Slug)
+
+			// The edited destination is restored with its history.
+			v, err = storage.GetByURL(context.TODO(), "2", "http://demo.com/edited")
+			require.NoError(t, err)
+			assert.Equal(t, "slug4", v.Slug)
+			history, err := storage.History(context.TODO(), "2", "slug4")
+			require.NoError(t, err)
+			require.Equal(t, 1, len(history))
+			assert.Equal(t, "http://demo.com/slug4", history[0].Old)
+			assert.Equal(t, 2, history[0].Version)
+
+			// The covered write-ahead logs are removed.
+			seqs, err := walSeqs(cfg.SnapshotPath)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(seqs), 1)
+		})
+	}
+}
+
+// newTestModel returns a test shortened URL by the user and the slug.
+func newTestModel(userID, slug string) models.ShortenedURL {
+	return models.NewShortenedURL(userID, slug, "http://demo.com/"+slug, slug, "http://127.0.0.1/"+slug)
+}
@@ -0,0 +1,146 @@
+package memstorage
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+)
+
+// walSuffix is the suffix of the write-ahead log files followed by the sequence number.
+const walSuffix = ".wal."
+
+// walOp is an operation of the write-ahead log.
+type walOp int
+
+// Write-ahead log operations.
+const (
+	walSave walOp = iota + 1
+	walBatch
+	walDelete
+	walVisit
+	walEdit
+	walSequence
+)
+
+// walRecord is a record of the write-ahead log. The visit record holds
+// the number of the counted visits of the slug, the edit record holds
+// the destination changes, the sequence record holds a handed out value
+// of the counter slug sequence.
+type walRecord struct {
+	Op      walOp
+	Records []models.ShortenedURL
+	UserID  string
+	Slugs   []string
+	Visits  int
+	Changes []models.URLChange
+	Seq     uint64
+}
+
+// wal is the write-ahead log of memStorage. Each log file is written by a single gob
+// encoder from the start, so the log is never appended to after restart.
+type wal struct {
+	seq  uint64
+	file *os.File
+	enc  *gob.Encoder
+	mtx  sync.Mutex
+}
+
+// walPath returns the write-ahead log path by the snapshot path and the sequence number.
+func walPath(base string, seq uint64) string {
+	return fmt.Sprintf("%s%s%06d", base, walSuffix, seq)
+}
+
+// createWAL creates a new write-ahead log file.
+func createWAL(base string, seq uint64) (*wal, error) {
+	f, err := os.OpenFile(walPath(base, seq), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wal{
+		seq:  seq,
+		file: f,
+		enc:  gob.NewEncoder(f),
+	}, nil
+}
+
+// append appends the record to the log. A successful call returns err == nil.
+func (w *wal) append(r walRecord) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	return w.enc.Encode(r)
+}
+
+// Close closes the log file.
+func (w *wal) Close() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if err := w.file.Sync(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// readWAL reads the records of the log file. The read stops at the first record
+// that cannot be decoded, which is a torn write of a crash. The number of the read
+// records is returned with the decode error then.
+func readWAL(path string, apply func(walRecord)) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+	for n := 0; ; n++ {
+		var r walRecord
+		if err = dec.Decode(&r); err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+		apply(r)
+	}
+}
+
+// walSeqs returns the sorted sequence numbers of the log files by the snapshot path.
+func walSeqs(base string) ([]uint64, error) {
+	dir, name := filepath.Split(base)
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var seqs []uint64
+	for _, f := range files {
+		suffix, ok := strings.CutPrefix(f.Name(), name+walSuffix)
+		if !ok || f.IsDir() {
+			continue
+		}
+		seq, err := strconv.ParseUint(suffix, 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	return seqs, nil
+}
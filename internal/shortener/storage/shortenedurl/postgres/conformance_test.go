@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/alukart32/shortener-url/internal/pkg/db/migrate"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl/storagetest"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+// storage combines the postgres repositories.
+type storage struct {
+	*shortURLSaver
+	*shortURLProvider
+	*shortURLDeleter
+	*statProvider
+	*slugSequence
+	*shortURLExpirer
+	*shortURLVisitor
+	*shortURLEditor
+}
+
+// TestConformance runs against the database set by TEST_DATABASE_DSN. The shorturls tables are truncated.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if len(dsn) == 0 {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	require.NoError(t, migrate.Up(dsn, "../../../../../migrations"))
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	for _, scope := range []shortenedurl.Scope{shortenedurl.ScopeUser, shortenedurl.ScopeGlobal} {
+		t.Run(string(scope), func(t *testing.T) {
+			open := func() storagetest.Storage {
+				return storage{
+					shortURLSaver:    ShortURLSaver(pool, scope),
+					shortURLProvider: ShortURLProvider(pool, scope),
+					shortURLDeleter:  ShortURLDeleter(pool),
+					statProvider:     StatProvider(pool),
+					slugSequence:     SlugSequence(pool),
+					shortURLExpirer:  ShortURLExpirer(pool),
+					shortURLVisitor:  ShortURLVisitor(pool),
+					shortURLEditor:   ShortURLEditor(pool, scope),
+				}
+			}
+
+			storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+				_, err := pool.Exec(context.Background(), `TRUNCATE shorturls, shorturl_changes`)
+				require.NoError(t, err)
+				return open()
+			}, storagetest.Options{
+				Scope: scope,
+				Reopen: func(*testing.T, storagetest.Storage) storagetest.Storage {
+					return open()
+				},
+			})
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// shortURLEditor represents the shortURL destination editor for the postgres repository.
+// The destination changes are kept in the shorturl_changes table.
+type shortURLEditor struct {
+	pool  *pgxpool.Pool
+	scope shortenedurl.Scope
+}
+
+// ShortURLEditor returns a new shortURLEditor. The edited URLs are unique in the scope
+// by their dedup key, the empty scope is shortenedurl.ScopeUser.
+func ShortURLEditor(pool *pgxpool.Pool, scope shortenedurl.Scope) *shortURLEditor {
+	if len(scope) == 0 {
+		scope = shortenedurl.ScopeUser
+	}
+
+	return &shortURLEditor{
+		pool:  pool,
+		scope: scope,
+	}
+}
+
+// changeable checks whether the shortURL by slug may be changed by the user.
+// ErrNotFound is returned for the shortURL that is not found or has been deleted,
+// ErrNotOwner for the shortURL of another user. The shortURL is locked for update if asked.
+func changeable(ctx context.Context, tx pgx.Tx, userID, slug string, forUpdate bool) (string, error) {
+	getShortURL := `SELECT user_id, original, deleted FROM shorturls WHERE slug = $1`
+	if forUpdate {
+		getShortURL += ` FOR UPDATE`
+	}
+
+	var (
+		owner, raw string
+		deleted    bool
+	)
+	err := tx.QueryRow(ctx, getShortURL, slug).Scan(&owner, &raw, &deleted)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", shortenedurl.ErrNotFound
+		}
+		return "", err
+	}
+	if deleted {
+		return "", shortenedurl.ErrNotFound
+	}
+	if owner != userID {
+		return "", shortenedurl.ErrNotOwner
+	}
+	return raw, nil
+}
+
+// Edit changes the destination of the user's shortURL and returns the change with the old
+// destination and the new version set. ErrNotFound is returned for the shortURL that
+// is not found or has been deleted, ErrNotOwner for the shortURL of another user.
+// The new destination that conflicts with another shortURL in the uniqueness scope
+// is reported as ErrUniqueViolation, the change that would not get its set version
+// as ErrVersionConflict.
+func (e *shortURLEditor) Edit(ctx context.Context, c models.URLChange) (models.URLChange, error) {
+	tx, err := e.pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		return models.URLChange{}, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(context.TODO())
+		} else {
+			tx.Commit(context.TODO())
+		}
+	}()
+
+	if c.Old, err = changeable(ctx, tx, c.UserID, c.Slug, true); err != nil {
+		return models.URLChange{}, err
+	}
+
+	if c.Version != 0 {
+		// The shortURL is locked, so the version is not changed until the commit.
+		const nextVersion = `SELECT COALESCE(MAX(version), 1) + 1 FROM shorturl_changes WHERE slug = $1`
+
+		var version int
+		if err = tx.QueryRow(ctx, nextVersion, c.Slug).Scan(&version); err != nil {
+			return models.URLChange{}, err
+		}
+		if version != c.Version {
+			err = shortenedurl.ErrVersionConflict
+			return models.URLChange{}, err
+		}
+	}
+
+	if e.scope == shortenedurl.ScopeGlobal {
+		if err = lockURLs(ctx, tx, []string{c.DedupKey()}); err != nil {
+			return models.URLChange{}, err
+		}
+
+		const existsURL = `SELECT EXISTS (SELECT 1 FROM shorturls WHERE canonical = $1 AND slug <> $2)`
+
+		var exists bool
+		if err = tx.QueryRow(ctx, existsURL, c.DedupKey(), c.Slug).Scan(&exists); err != nil {
+			return models.URLChange{}, err
+		}
+		if exists {
+			err = shortenedurl.ErrUniqueViolation
+			return models.URLChange{}, err
+		}
+	}
+
+	const editShortURL = `UPDATE shorturls SET original = $2, canonical = $3 WHERE slug = $1`
+
+	if _, err = tx.Exec(ctx, editShortURL, c.Slug, c.New, c.DedupKey()); err != nil {
+		err = uniqueErr(err)
+		return models.URLChange{}, err
+	}
+
+	// The shortURL is locked, so the versions of the slug are not raced for.
+	const insertChange = `INSERT INTO
+	shorturl_changes(slug, version, old, new, canonical, user_id, changed_at)
+	SELECT $1, COALESCE(MAX(version), 1) + 1, $2, $3, $4, $5, $6 FROM shorturl_changes WHERE slug = $1
+	RETURNING version`
+
+	err = tx.QueryRow(ctx, insertChange,
+		c.Slug,
+		c.Old,
+		c.New,
+		c.Canonical,
+		c.UserID,
+		c.ChangedAt,
+	).Scan(&c.Version)
+	if err != nil {
+		return models.URLChange{}, err
+	}
+	return c, nil
+}
+
+// History returns the destination changes of the user's shortURL in the version order.
+// ErrNotFound is returned for the shortURL that is not found or has been deleted,
+// ErrNotOwner for the shortURL of another user.
+func (e *shortURLEditor) History(ctx context.Context, userID, slug string) ([]models.URLChange, error) {
+	tx, err := e.pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.RepeatableRead,
+		AccessMode:     pgx.ReadOnly,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.TODO())
+
+	if _, err = changeable(ctx, tx, userID, slug, false); err != nil {
+		return nil, err
+	}
+
+	const collectChanges = `SELECT slug, version, old, new, canonical, user_id, changed_at
+	FROM shorturl_changes WHERE slug = $1 ORDER BY version`
+
+	rows, err := tx.Query(ctx, collectChanges, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.URLChange{}
+	for rows.Next() {
+		var c models.URLChange
+		err = rows.Scan(&c.Slug, &c.Version, &c.Old, &c.New, &c.Canonical, &c.UserID, &c.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
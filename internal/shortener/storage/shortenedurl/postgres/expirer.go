@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// shortURLExpirer represents the shortURL expirer for the postgres repository.
+type shortURLExpirer struct {
+	pool *pgxpool.Pool
+}
+
+// ShortURLExpirer returns a new shortURLExpirer.
+func ShortURLExpirer(pool *pgxpool.Pool) *shortURLExpirer {
+	return &shortURLExpirer{
+		pool: pool,
+	}
+}
+
+// Expire marks the shortURLs expired by the time and returns the number of the marked ones.
+func (e *shortURLExpirer) Expire(ctx context.Context, now time.Time) (int, error) {
+	const markExpired = `UPDATE shorturls SET expired = true WHERE NOT expired AND expires_at <= $1`
+
+	tag, err := e.pool.Exec(ctx, markExpired, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
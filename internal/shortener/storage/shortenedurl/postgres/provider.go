@@ -3,67 +3,115 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // shortURLProvider represents the shortURL provider for the postgres repository.
 type shortURLProvider struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	scope shortenedurl.Scope
 }
 
-// ShortURLProvider returns a new shortURLProvider.
-func ShortURLProvider(pool *pgxpool.Pool) *shortURLProvider {
+// ShortURLProvider returns a new shortURLProvider. The URLs are looked up in the scope,
+// the empty scope is shortenedurl.ScopeUser.
+func ShortURLProvider(pool *pgxpool.Pool, scope shortenedurl.Scope) *shortURLProvider {
+	if len(scope) == 0 {
+		scope = shortenedurl.ScopeUser
+	}
+
 	return &shortURLProvider{
-		pool: pool,
+		pool:  pool,
+		scope: scope,
 	}
 }
 
-// GetBySlug finds a shortURL by slug. A successful call returns err == nil.
-func (p *shortURLProvider) GetBySlug(ctx context.Context, slug string) (models.ShortenedURL, error) {
-	const getBySlug = `SELECT * FROM shorturls WHERE slug = $1`
+// shortURLColumns are the selected shortURL columns. The canonical form equal
+// to the original URL is read as empty, as if it was not set.
+const shortURLColumns = `slug, user_id, original, COALESCE(NULLIF(canonical, original), ''), short, corr_id,
+	expires_at, expired, activate_at, deactivate_at, max_visits, visits, password_hash,
+	redirect_status, cache_policy, query_mode, fixed_params, forward_path, deleted`
 
-	var r models.ShortenedURL
-	row := p.pool.QueryRow(ctx, getBySlug, slug)
+// scanShortURL scans the shortURL columns of the row.
+func scanShortURL(row pgx.Row) (models.ShortenedURL, error) {
+	var (
+		r                                   models.ShortenedURL
+		expiresAt, activateAt, deactivateAt pgtype.Timestamptz
+	)
 	err := row.Scan(
 		&r.Slug,
 		&r.UserID,
 		&r.Raw,
+		&r.Canonical,
 		&r.Value,
 		&r.CorrID,
+		&expiresAt,
+		&r.IsExpired,
+		&activateAt,
+		&deactivateAt,
+		&r.MaxVisits,
+		&r.Visits,
+		&r.PasswordHash,
+		&r.RedirectStatus,
+		&r.CachePolicy,
+		&r.QueryMode,
+		&r.FixedParams,
+		&r.ForwardPath,
 		&r.IsDeleted,
 	)
+	r.ExpiresAt = timeOf(expiresAt)
+	r.ActivateAt = timeOf(activateAt)
+	r.DeactivateAt = timeOf(deactivateAt)
+	return r, err
+}
+
+// nullTime returns the nullable time, the zero time is NULL.
+func nullTime(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: !t.IsZero()}
+}
+
+// timeOf returns the time of the nullable one, NULL is the zero time.
+func timeOf(t pgtype.Timestamptz) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+// GetBySlug finds a shortURL by slug. A successful call returns err == nil.
+func (p *shortURLProvider) GetBySlug(ctx context.Context, slug string) (models.ShortenedURL, error) {
+	const getBySlug = `SELECT ` + shortURLColumns + ` FROM shorturls WHERE slug = $1`
+
+	r, err := scanShortURL(p.pool.QueryRow(ctx, getBySlug, slug))
 	if err != nil && errors.Is(err, pgx.ErrNoRows) {
-		err = nil
+		return models.ShortenedURL{}, nil
 	}
 
 	return r, err
 }
 
-// GetByURL returns a shortURL by original URL.
-func (p *shortURLProvider) GetByURL(ctx context.Context, url string) (models.ShortenedURL, error) {
-	const getByURL = `SELECT * FROM shorturls WHERE original = $1`
+// GetByURL returns a shortURL by the canonical form of the original URL. In the user scope only
+// the user URLs are looked up, in the global scope the URL of any user is returned.
+func (p *shortURLProvider) GetByURL(ctx context.Context, userID, url string) (models.ShortenedURL, error) {
+	const getByURL = `SELECT ` + shortURLColumns + ` FROM shorturls
+	WHERE canonical = $1 AND ($2 OR user_id = $3) LIMIT 1`
 
-	var r models.ShortenedURL
-	row := p.pool.QueryRow(ctx, getByURL, url)
-	err := row.Scan(
-		&r.Slug,
-		&r.UserID,
-		&r.Raw,
-		&r.Value,
-		&r.CorrID,
-		&r.IsDeleted,
-	)
+	row := p.pool.QueryRow(ctx, getByURL, url, p.scope == shortenedurl.ScopeGlobal, userID)
+	r, err := scanShortURL(row)
 	if err != nil && errors.Is(err, pgx.ErrNoRows) {
-		err = nil
+		return models.ShortenedURL{}, nil
 	}
 
 	return r, err
 }
 
-// Collect finds shortURLs by using userID. A successful call returns err == nil.
+// Collect finds shortURLs by using userID, the expired ones are skipped.
+// A successful call returns err == nil.
 func (p *shortURLProvider) CollectByUser(ctx context.Context, userID string) ([]models.ShortenedURL, error) {
 	tx, err := p.pool.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:       pgx.RepeatableRead,
@@ -81,7 +129,7 @@ func (p *shortURLProvider) CollectByUser(ctx context.Context, userID string) ([]
 		}
 	}()
 
-	const listByUserID = `SELECT * FROM shorturls WHERE user_id = $1`
+	const listByUserID = `SELECT ` + shortURLColumns + ` FROM shorturls WHERE user_id = $1 AND NOT expired`
 
 	rows, err := tx.Query(ctx, listByUserID, userID)
 	if err != nil {
@@ -92,14 +140,7 @@ func (p *shortURLProvider) CollectByUser(ctx context.Context, userID string) ([]
 	records := make([]models.ShortenedURL, 0)
 	for rows.Next() {
 		var r models.ShortenedURL
-		if err = rows.Scan(
-			&r.Slug,
-			&r.UserID,
-			&r.Raw,
-			&r.Value,
-			&r.CorrID,
-			&r.IsDeleted,
-		); err != nil {
+		if r, err = scanShortURL(rows); err != nil {
 			return nil, err
 		}
 		records = append(records, r)
@@ -3,32 +3,92 @@ package postgres
 import (
 	"context"
 	"errors"
-	"fmt"
+	"sort"
 
 	"github.com/alukart32/shortener-url/internal/shortener/models"
 	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // shortURLSaver represents the shortURL saver for the postgres repository.
 type shortURLSaver struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	scope shortenedurl.Scope
 }
 
-// ShortURLSaver returns a new shortURLSaver.
-func ShortURLSaver(pool *pgxpool.Pool) *shortURLSaver {
+// ShortURLSaver returns a new shortURLSaver. The URLs are unique in the scope by their
+// dedup key, the empty scope is shortenedurl.ScopeUser.
+func ShortURLSaver(pool *pgxpool.Pool, scope shortenedurl.Scope) *shortURLSaver {
+	if len(scope) == 0 {
+		scope = shortenedurl.ScopeUser
+	}
+
 	return &shortURLSaver{
-		pool: pool,
+		pool:  pool,
+		scope: scope,
+	}
+}
+
+// lockGlobal locks the dedup keys of the URLs until the end of the transaction in the global scope,
+// so concurrent inserts of the same URL are serialized. It does nothing in the user scope,
+// which is enforced by the unique constraint.
+//
+// The transaction must be read committed to see the URLs inserted before the lock was taken.
+func (s *shortURLSaver) lockGlobal(ctx context.Context, tx pgx.Tx, records []models.ShortenedURL) error {
+	if s.scope != shortenedurl.ScopeGlobal {
+		return nil
+	}
+
+	keys := make([]string, 0, len(records))
+	seen := make(map[string]bool, len(records))
+	for _, r := range records {
+		if key := r.DedupKey(); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return lockURLs(ctx, tx, keys)
+}
+
+// lockURLs locks the dedup keys until the end of the transaction.
+func lockURLs(ctx context.Context, tx pgx.Tx, keys []string) error {
+	// Lock in order to avoid deadlocks between the batches.
+	sort.Strings(keys)
+
+	const lockURL = `SELECT pg_advisory_xact_lock(hashtext($1))`
+	for _, key := range keys {
+		if _, err := tx.Exec(ctx, lockURL, key); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// Save inserts a new shortURL. A successful call returns err == nil.
+// slugConstraint is the primary key constraint of the slug.
+const slugConstraint = "shorturls_pkey"
+
+// uniqueErr maps the unique violations to the storage errors.
+func uniqueErr(err error) error {
+	var pgErr *pgconn.PgError
+	if err != nil && errors.As(err, &pgErr) &&
+		pgErr.SQLState() == pgerrcode.UniqueViolation {
+		if pgErr.ConstraintName == slugConstraint {
+			return shortenedurl.ErrSlugCollision
+		}
+		return shortenedurl.ErrUniqueViolation
+	}
+	return err
+}
+
+// Save inserts a new shortURL. The taken slug is reported as shortenedurl.ErrSlugCollision.
+// A successful call returns err == nil.
 func (s *shortURLSaver) Save(ctx context.Context, data models.ShortenedURL) error {
 	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel:       pgx.RepeatableRead,
+		IsoLevel:       pgx.ReadCommitted,
 		AccessMode:     pgx.ReadWrite,
 		DeferrableMode: pgx.NotDeferrable,
 	})
@@ -43,38 +103,67 @@ func (s *shortURLSaver) Save(ctx context.Context, data models.ShortenedURL) erro
 		}
 	}()
 
+	if s.scope == shortenedurl.ScopeGlobal {
+		if err = s.lockGlobal(ctx, tx, []models.ShortenedURL{data}); err != nil {
+			return err
+		}
+
+		const existsURL = `SELECT EXISTS (SELECT 1 FROM shorturls WHERE canonical = $1)`
+
+		var exists bool
+		if err = tx.QueryRow(ctx, existsURL, data.DedupKey()).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			err = shortenedurl.ErrUniqueViolation
+			return err
+		}
+	}
+
 	const insertShortURL = `INSERT INTO
-	shorturls(slug, user_id, original, short, corr_id)
-	VALUES ($1, $2, $3, $4, $5)`
+	shorturls(slug, user_id, original, canonical, short, corr_id, expires_at, activate_at, deactivate_at,
+		max_visits, password_hash, redirect_status, cache_policy, query_mode, fixed_params, forward_path)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
 
 	_, err = tx.Exec(ctx, insertShortURL,
 		data.Slug,
 		data.UserID,
 		data.Raw,
+		data.DedupKey(),
 		data.Value,
 		data.CorrID,
+		nullTime(data.ExpiresAt),
+		nullTime(data.ActivateAt),
+		nullTime(data.DeactivateAt),
+		data.MaxVisits,
+		data.PasswordHash,
+		data.RedirectStatus,
+		data.CachePolicy,
+		data.QueryMode,
+		data.FixedParams,
+		data.ForwardPath,
 	)
 
-	var pgErr *pgconn.PgError
-	if err != nil && errors.As(err, &pgErr) {
-		if pgerrcode.IsIntegrityConstraintViolation(pgErr.SQLState()) &&
-			pgErr.SQLState() == pgerrcode.UniqueViolation {
-			return shortenedurl.ErrUniqueViolation
-		}
-	}
-	return err
+	return uniqueErr(err)
 }
 
-// Batch performs bulk shortURL insertion. It uses the PostgreSQL copy protocol to perform bulk data insertion.
-// A successful call returns err == nil.
-func (s *shortURLSaver) Batch(ctx context.Context, records []models.ShortenedURL) error {
+// Batch performs bulk shortURL insertion. The records are copied to a staging table
+// by the PostgreSQL copy protocol and then inserted skipping the conflicting ones.
+// The URLs that conflict with the saved ones or with the preceding URLs of the batch
+// are not inserted, the conflicting records are returned for them instead.
+// If any slug of the batch is taken, nothing is inserted.
+func (s *shortURLSaver) Batch(ctx context.Context, records []models.ShortenedURL) ([]models.BatchedURL, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
 	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel:       pgx.RepeatableRead,
+		IsoLevel:       pgx.ReadCommitted,
 		AccessMode:     pgx.ReadWrite,
 		DeferrableMode: pgx.NotDeferrable,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err != nil {
@@ -84,27 +173,178 @@ func (s *shortURLSaver) Batch(ctx context.Context, records []models.ShortenedURL
 		}
 	}()
 
-	// Prepare CopyForm. It uses the PostgreSQL copy protocol to perform bulk data insertion.
+	if err = s.lockGlobal(ctx, tx, records); err != nil {
+		return nil, err
+	}
+
+	const createBatch = `CREATE TEMP TABLE shorturls_batch (
+		ord int, slug varchar, user_id uuid, original varchar, canonical varchar, short varchar, corr_id varchar,
+		expires_at timestamptz, activate_at timestamptz, deactivate_at timestamptz, max_visits int,
+		password_hash varchar, redirect_status int, cache_policy varchar, query_mode varchar,
+		fixed_params varchar, forward_path boolean
+	) ON COMMIT DROP`
+
+	if _, err = tx.Exec(ctx, createBatch); err != nil {
+		return nil, err
+	}
 	_, err = tx.CopyFrom(ctx,
-		pgx.Identifier{"shorturls"},
-		[]string{"slug", "user_id", "original", "short", "corr_id"},
+		pgx.Identifier{"shorturls_batch"},
+		[]string{"ord", "slug", "user_id", "original", "canonical", "short", "corr_id", "expires_at",
+			"activate_at", "deactivate_at", "max_visits", "password_hash", "redirect_status", "cache_policy",
+			"query_mode", "fixed_params", "forward_path"},
 		pgx.CopyFromSlice(len(records), func(i int) ([]any, error) {
 			return []interface{}{
+				i,
 				records[i].Slug,
 				records[i].UserID,
 				records[i].Raw,
+				records[i].DedupKey(),
 				records[i].Value,
 				records[i].CorrID,
+				nullTime(records[i].ExpiresAt),
+				nullTime(records[i].ActivateAt),
+				nullTime(records[i].DeactivateAt),
+				records[i].MaxVisits,
+				records[i].PasswordHash,
+				records[i].RedirectStatus,
+				records[i].CachePolicy,
+				records[i].QueryMode,
+				records[i].FixedParams,
+				records[i].ForwardPath,
 			}, nil
 		}),
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	var pgErr *pgconn.PgError
-	if err != nil && errors.As(err, &pgErr) {
-		if pgerrcode.IsIntegrityConstraintViolation(pgErr.SQLState()) &&
-			pgErr.SQLState() == pgerrcode.UniqueViolation {
-			err = fmt.Errorf("%v", pgErr.Message)
+	// The first URL of the batch wins among the conflicting ones.
+	const (
+		insertUserBatch = `INSERT INTO shorturls(slug, user_id, original, canonical, short, corr_id, expires_at,
+			activate_at, deactivate_at, max_visits, password_hash, redirect_status, cache_policy,
+			query_mode, fixed_params, forward_path)
+		SELECT DISTINCT ON (user_id, canonical) slug, user_id, original, canonical, short, corr_id, expires_at,
+			activate_at, deactivate_at, max_visits, password_hash, redirect_status, cache_policy,
+			query_mode, fixed_params, forward_path
+		FROM shorturls_batch
+		ORDER BY user_id, canonical, ord
+		ON CONFLICT ON CONSTRAINT user_canonical_uniq DO NOTHING
+		RETURNING slug`
+		insertGlobalBatch = `INSERT INTO shorturls(slug, user_id, original, canonical, short, corr_id, expires_at,
+			activate_at, deactivate_at, max_visits, password_hash, redirect_status, cache_policy,
+			query_mode, fixed_params, forward_path)
+		SELECT DISTINCT ON (b.canonical) b.slug, b.user_id, b.original, b.canonical, b.short, b.corr_id, b.expires_at,
+			b.activate_at, b.deactivate_at, b.max_visits, b.password_hash, b.redirect_status, b.cache_policy,
+			b.query_mode, b.fixed_params, b.forward_path
+		FROM shorturls_batch b
+		WHERE NOT EXISTS (SELECT 1 FROM shorturls s WHERE s.canonical = b.canonical)
+		ORDER BY b.canonical, b.ord
+		ON CONFLICT ON CONSTRAINT user_canonical_uniq DO NOTHING
+		RETURNING slug`
+	)
+
+	insertBatch := insertUserBatch
+	if s.scope == shortenedurl.ScopeGlobal {
+		insertBatch = insertGlobalBatch
+	}
+	created, err := s.insertBatch(ctx, tx, insertBatch)
+	if err != nil {
+		err = uniqueErr(err)
+		return nil, err
+	}
+
+	results, err := s.batchResults(ctx, tx, len(records), created)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// insertBatch inserts the staged records and returns the slugs of the inserted ones.
+func (s *shortURLSaver) insertBatch(ctx context.Context, tx pgx.Tx, query string) (map[string]bool, error) {
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	created := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err = rows.Scan(&slug); err != nil {
+			return nil, err
 		}
+		created[slug] = true
 	}
-	return err
+	return created, rows.Err()
+}
+
+// batchResults returns the saved record for each staged one in the batch order.
+// The staged record is created only if its own slug was inserted, the later
+// duplicates of it within the batch are existed.
+func (s *shortURLSaver) batchResults(ctx context.Context, tx pgx.Tx, n int, created map[string]bool) ([]models.BatchedURL, error) {
+	const selectBatch = `SELECT b.ord, b.slug, s.slug, s.user_id, s.original,
+		COALESCE(NULLIF(s.canonical, s.original), ''), s.short, s.corr_id, s.expires_at, s.expired,
+		s.activate_at, s.deactivate_at, s.max_visits, s.visits, s.password_hash, s.redirect_status, s.cache_policy,
+		s.query_mode, s.fixed_params, s.forward_path, s.deleted
+	FROM shorturls_batch b
+	JOIN shorturls s ON s.canonical = b.canonical AND ($1 OR s.user_id = b.user_id)
+	ORDER BY b.ord`
+
+	rows, err := tx.Query(ctx, selectBatch, s.scope == shortenedurl.ScopeGlobal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]models.BatchedURL, n)
+	for rows.Next() {
+		var (
+			ord                                 int
+			staged                              string
+			r                                   models.ShortenedURL
+			expiresAt, activateAt, deactivateAt pgtype.Timestamptz
+		)
+		if err = rows.Scan(
+			&ord,
+			&staged,
+			&r.Slug,
+			&r.UserID,
+			&r.Raw,
+			&r.Canonical,
+			&r.Value,
+			&r.CorrID,
+			&expiresAt,
+			&r.IsExpired,
+			&activateAt,
+			&deactivateAt,
+			&r.MaxVisits,
+			&r.Visits,
+			&r.PasswordHash,
+			&r.RedirectStatus,
+			&r.CachePolicy,
+			&r.QueryMode,
+			&r.FixedParams,
+			&r.ForwardPath,
+			&r.IsDeleted,
+		); err != nil {
+			return nil, err
+		}
+		r.ExpiresAt = timeOf(expiresAt)
+		r.ActivateAt = timeOf(activateAt)
+		r.DeactivateAt = timeOf(deactivateAt)
+		if len(results[ord].Slug) != 0 {
+			continue
+		}
+
+		status := models.BatchExisted
+		if created[r.Slug] && staged == r.Slug {
+			status = models.BatchCreated
+		}
+		results[ord] = models.NewBatchedURL(r, status)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
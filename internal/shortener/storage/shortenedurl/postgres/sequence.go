@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// slugSequence represents the sequence of the counter slugs for the postgres repository.
+type slugSequence struct {
+	pool *pgxpool.Pool
+}
+
+// SlugSequence returns a new slugSequence.
+func SlugSequence(pool *pgxpool.Pool) *slugSequence {
+	return &slugSequence{
+		pool: pool,
+	}
+}
+
+// Next returns the next value of the sequence, the first one is 1.
+func (s *slugSequence) Next(ctx context.Context) (uint64, error) {
+	const nextSlug = `SELECT nextval('shorturls_slug_seq')`
+
+	var next int64
+	if err := s.pool.QueryRow(ctx, nextSlug).Scan(&next); err != nil {
+		return 0, err
+	}
+	return uint64(next), nil
+}
@@ -44,7 +44,7 @@ func (sp *statProvider) Stat(ctx context.Context) (models.Stat, error) {
 
 	const getStat = `
 SELECT
-	SUM(users.cnt) AS urls,
+	COALESCE(SUM(users.cnt), 0) AS urls,
 	COUNT(*) AS users
 FROM
 (
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// shortURLVisitor represents the shortURL visits counter for the postgres repository.
+type shortURLVisitor struct {
+	pool *pgxpool.Pool
+}
+
+// ShortURLVisitor returns a new shortURLVisitor.
+func ShortURLVisitor(pool *pgxpool.Pool) *shortURLVisitor {
+	return &shortURLVisitor{
+		pool: pool,
+	}
+}
+
+// Visit counts a visit of the visit-limited URL and returns the number of the visits left.
+// ErrVisitsExhausted is returned if no visits are left. The URL that is not found
+// or is not visit-limited is not counted, -1 is returned for it.
+func (v *shortURLVisitor) Visit(ctx context.Context, slug string) (int, error) {
+	const (
+		countVisit = `UPDATE shorturls SET visits = visits + 1
+		WHERE slug = $1 AND visits < max_visits
+		RETURNING max_visits - visits`
+		getMaxVisits = `SELECT max_visits FROM shorturls WHERE slug = $1`
+	)
+
+	var left int
+	err := v.pool.QueryRow(ctx, countVisit, slug).Scan(&left)
+	if err == nil {
+		return left, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	// The URL is either exhausted, unlimited or not found.
+	var maxVisits int
+	err = v.pool.QueryRow(ctx, getMaxVisits, slug).Scan(&maxVisits)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return -1, nil
+		}
+		return 0, err
+	}
+	if maxVisits <= 0 {
+		return -1, nil
+	}
+	return 0, shortenedurl.ErrVisitsExhausted
+}
@@ -0,0 +1,31 @@
+package shortenedurl
+
+import "fmt"
+
+// Scope is the scope of the raw URL uniqueness.
+type Scope string
+
+// Uniqueness scopes.
+const (
+	// ScopeUser allows each user to shorten the raw URL once.
+	ScopeUser Scope = "user"
+	// ScopeGlobal allows the raw URL to be shortened once by anyone.
+	ScopeGlobal Scope = "global"
+)
+
+// ParseScope parses the uniqueness scope. The empty scope is ScopeUser.
+func ParseScope(s string) (Scope, error) {
+	switch Scope(s) {
+	case "", ScopeUser:
+		return ScopeUser, nil
+	case ScopeGlobal:
+		return ScopeGlobal, nil
+	}
+	return "", fmt.Errorf("unknown uniqueness scope: %v", s)
+}
+
+// Conflicts checks whether the existing URL of the owner conflicts with
+// a new URL of the user.
+func (s Scope) Conflicts(owner, userID string) bool {
+	return s == ScopeGlobal || owner == userID
+}
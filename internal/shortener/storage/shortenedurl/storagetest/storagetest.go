@@ -0,0 +1,913 @@
+// Package storagetest provides the conformance test suite for the shortened URL storages.
+//
+// Every storage runs the suite from its own tests, preferably with -race:
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+//			return MemStorage(shortenedurl.ScopeUser)
+//		}, storagetest.Options{Scope: shortenedurl.ScopeUser})
+//	}
+//
+// The suite covers uniqueness, slugs, deletion, expiration, visits, editing,
+// ownership, concurrency and stat semantics.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alukart32/shortener-url/internal/shortener/models"
+	"github.com/alukart32/shortener-url/internal/shortener/storage/shortenedurl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Storage defines the shortened URL storage under test.
+type Storage interface {
+	Save(context.Context, models.ShortenedURL) error
+	Batch(context.Context, []models.ShortenedURL) ([]models.BatchedURL, error)
+	GetByURL(context.Context, string, string) (models.ShortenedURL, error)
+	GetBySlug(context.Context, string) (models.ShortenedURL, error)
+	CollectByUser(context.Context, string) ([]models.ShortenedURL, error)
+	Delete(userID string, slugs []string) error
+	Stat(context.Context) (models.Stat, error)
+	Next(context.Context) (uint64, error)
+	Expire(context.Context, time.Time) (int, error)
+	Visit(context.Context, string) (int, error)
+	Edit(context.Context, models.URLChange) (models.URLChange, error)
+	History(context.Context, string, string) ([]models.URLChange, error)
+}
+
+// Options represents the storage specifics.
+type Options struct {
+	// Scope is the uniqueness scope the storage is configured with.
+	Scope shortenedurl.Scope
+
+	// Reopen closes the storage and opens it again on the same data.
+	// The restart tests are skipped if it is nil.
+	Reopen func(t *testing.T, s Storage) Storage
+}
+
+// Test users. They are UUIDs, since some storages require them.
+const (
+	user1 = "7f4c2b1e-6a51-4f3c-9a2d-0d1f0e6b5a01"
+	user2 = "7f4c2b1e-6a51-4f3c-9a2d-0d1f0e6b5a02"
+	user3 = "7f4c2b1e-6a51-4f3c-9a2d-0d1f0e6b5a03"
+)
+
+// passwordHash is the bcrypt hash of the password of the protected URLs.
+const passwordHash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+// Run runs the conformance suite. newStorage must return a new empty storage
+// for each test, the storage is closed by the test cleanup if needed.
+func Run(t *testing.T, newStorage func(t *testing.T) Storage, opts Options) {
+	tests := []struct {
+		name string
+		fn   func(*testing.T, Storage, Options)
+	}{
+		{name: "Save and get", fn: testSaveAndGet},
+		{name: "Not found", fn: testNotFound},
+		{name: "Uniqueness", fn: testUniqueness},
+		{name: "Batch", fn: testBatch},
+		{name: "Batch conflicts", fn: testBatchConflicts},
+		{name: "Canonical URLs", fn: testCanonical},
+		{name: "Slug collision", fn: testSlugCollision},
+		{name: "Slug length", fn: testSlugLength},
+		{name: "Slug sequence", fn: testSlugSequence},
+		{name: "Restart", fn: testRestart},
+		{name: "Collect by user", fn: testCollectByUser},
+		{name: "Delete", fn: testDelete},
+		{name: "Expire", fn: testExpire},
+		{name: "Activation window", fn: testWindow},
+		{name: "Redirect", fn: testRedirect},
+		{name: "Query forwarding", fn: testQueryForwarding},
+		{name: "Visit", fn: testVisit},
+		{name: "Concurrent visits", fn: testConcurrentVisits},
+		{name: "Edit", fn: testEdit},
+		{name: "Edit conflicts", fn: testEditConflicts},
+		{name: "Ownership", fn: testOwnership},
+		{name: "Stat", fn: testStat},
+		{name: "Concurrency", fn: testConcurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStorage(t), opts)
+		})
+	}
+}
+
+// newURL returns a new shortened URL of the user. The slug is 7 characters long.
+func newURL(userID string, n int, raw string) models.ShortenedURL {
+	slug := fmt.Sprintf("s%06d", n)
+	return models.NewShortenedURL(userID, fmt.Sprint(n), raw, slug, "http://127.0.0.1/"+slug)
+}
+
+func testSaveAndGet(t *testing.T, s Storage, _ Options) {
+	v := newURL(user1, 1, "http://demo.com/1")
+	require.NoError(t, s.Save(context.TODO(), v))
+
+	got, err := s.GetBySlug(context.TODO(), v.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, v, got)
+
+	got, err = s.GetByURL(context.TODO(), user1, v.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, v, got)
+
+	// The password hash is kept.
+	protected := newURL(user1, 2, "http://demo.com/2")
+	protected.PasswordHash = passwordHash
+	require.NoError(t, s.Save(context.TODO(), protected))
+
+	got, err = s.GetBySlug(context.TODO(), protected.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, protected, got)
+}
+
+func testNotFound(t *testing.T, s Storage, _ Options) {
+	require.NoError(t, s.Save(context.TODO(), newURL(user1, 1, "http://demo.com/1")))
+
+	got, err := s.GetBySlug(context.TODO(), "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = s.GetByURL(context.TODO(), user1, "http://unknown.com")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	records, err := s.CollectByUser(context.TODO(), user2)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = s.CollectByUser(context.TODO(), "")
+	assert.Error(t, err)
+}
+
+func testUniqueness(t *testing.T, s Storage, opts Options) {
+	raw := "http://demo.com/1"
+	first := newURL(user1, 1, raw)
+	require.NoError(t, s.Save(context.TODO(), first))
+
+	err := s.Save(context.TODO(), newURL(user1, 2, raw))
+	assert.ErrorIs(t, err, shortenedurl.ErrUniqueViolation, "same user, same URL")
+
+	other := newURL(user2, 3, raw)
+	err = s.Save(context.TODO(), other)
+	switch opts.Scope {
+	case shortenedurl.ScopeGlobal:
+		assert.ErrorIs(t, err, shortenedurl.ErrUniqueViolation, "other user, same URL")
+	default:
+		assert.NoError(t, err, "other user, same URL")
+	}
+
+	// The rejected URLs are not saved.
+	got, err := s.GetBySlug(context.TODO(), newURL(user1, 2, raw).Slug)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = s.GetByURL(context.TODO(), user1, raw)
+	require.NoError(t, err)
+	assert.Equal(t, first, got)
+
+	// The conflicting URL is returned to the other user.
+	got, err = s.GetByURL(context.TODO(), user2, raw)
+	require.NoError(t, err)
+	switch opts.Scope {
+	case shortenedurl.ScopeGlobal:
+		assert.Equal(t, first, got)
+	default:
+		assert.Equal(t, other, got)
+	}
+
+	got, err = s.GetByURL(context.TODO(), user3, raw)
+	require.NoError(t, err)
+	switch opts.Scope {
+	case shortenedurl.ScopeGlobal:
+		assert.Equal(t, first, got)
+	default:
+		assert.Empty(t, got)
+	}
+}
+
+func testBatchConflicts(t *testing.T, s Storage, opts Options) {
+	saved := newURL(user1, 1, "http://demo.com/1")
+	require.NoError(t, s.Save(context.TODO(), saved))
+
+	records := []models.ShortenedURL{
+		newURL(user1, 2, "http://demo.com/2"),
+		newURL(user1, 3, saved.Raw),
+		newURL(user1, 4, "http://demo.com/4"),
+		newURL(user1, 5, "http://demo.com/4"),
+		newURL(user2, 6, saved.Raw),
+	}
+	want := []models.BatchedURL{
+		models.NewBatchedURL(records[0], models.BatchCreated),
+		// Conflict with the saved URL.
+		models.NewBatchedURL(saved, models.BatchExisted),
+		models.NewBatchedURL(records[2], models.BatchCreated),
+		// Conflict within the batch.
+		models.NewBatchedURL(records[2], models.BatchExisted),
+		// Same URL of other user.
+		models.NewBatchedURL(records[4], models.BatchCreated),
+	}
+	if opts.Scope == shortenedurl.ScopeGlobal {
+		want[4] = models.NewBatchedURL(saved, models.BatchExisted)
+	}
+
+	batched, err := s.Batch(context.TODO(), records)
+	require.NoError(t, err)
+	assert.Equal(t, want, batched)
+
+	// Only the created URLs are saved.
+	for i, v := range records {
+		got, err := s.GetBySlug(context.TODO(), v.Slug)
+		require.NoError(t, err)
+		if want[i].Status == models.BatchCreated {
+			assert.Equal(t, v, got)
+		} else {
+			assert.Empty(t, got)
+		}
+	}
+
+	// The batch of existed URLs changes nothing.
+	batched, err = s.Batch(context.TODO(), []models.ShortenedURL{newURL(user1, 7, saved.Raw)})
+	require.NoError(t, err)
+	assert.Equal(t, []models.BatchedURL{models.NewBatchedURL(saved, models.BatchExisted)}, batched)
+
+	created := 0
+	for _, v := range want {
+		if v.Status == models.BatchCreated {
+			created++
+		}
+	}
+	stat, err := s.Stat(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 1+created, stat.URLs)
+}
+
+func testBatch(t *testing.T, s Storage, _ Options) {
+	records := []models.ShortenedURL{
+		newURL(user1, 1, "http://demo.com/1"),
+		newURL(user1, 2, "http://demo.com/2"),
+		newURL(user2, 3, "http://demo.com/3"),
+	}
+	records[1].PasswordHash = passwordHash
+	batched, err := s.Batch(context.TODO(), records)
+	require.NoError(t, err)
+	require.Equal(t, len(records), len(batched))
+	for i, v := range records {
+		assert.Equal(t, models.NewBatchedURL(v, models.BatchCreated), batched[i])
+	}
+
+	for _, v := range records {
+		got, err := s.GetBySlug(context.TODO(), v.Slug)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+}
+
+// testCanonical checks that the URLs are deduplicated by the canonical form,
+// while the original URL is kept.
+func testCanonical(t *testing.T, s Storage, _ Options) {
+	const canonical = "http://demo.com/b"
+
+	saved := newURL(user1, 1, "HTTP://Demo.com:80/a/../b")
+	saved.Canonical = canonical
+	require.NoError(t, s.Save(context.TODO(), saved))
+
+	got, err := s.GetByURL(context.TODO(), user1, canonical)
+	require.NoError(t, err)
+	assert.Equal(t, saved, got)
+
+	// Another form of the same URL.
+	v := newURL(user1, 2, "http://demo.com/b?")
+	v.Canonical = canonical
+	assert.ErrorIs(t, s.Save(context.TODO(), v), shortenedurl.ErrUniqueViolation)
+
+	v = newURL(user1, 3, "http://DEMO.com/b")
+	v.Canonical = canonical
+	batched, err := s.Batch(context.TODO(), []models.ShortenedURL{v})
+	require.NoError(t, err)
+	require.Len(t, batched, 1)
+	assert.Equal(t, models.NewBatchedURL(saved, models.BatchExisted), batched[0])
+}
+
+func testSlugCollision(t *testing.T, s Storage, _ Options) {
+	saved := newURL(user1, 1, "http://demo.com/1")
+	require.NoError(t, s.Save(context.TODO(), saved))
+
+	// The same slug with another raw URL.
+	err := s.Save(context.TODO(), newURL(user2, 1, "http://demo.com/2"))
+	assert.ErrorIs(t, err, shortenedurl.ErrSlugCollision)
+
+	tests := []struct {
+		name    string
+		records []models.ShortenedURL
+	}{
+		{
+			name: "Taken slug",
+			records: []models.ShortenedURL{
+				newURL(user1, 2, "http://demo.com/2"),
+				newURL(user1, 1, "http://demo.com/3"),
+			},
+		},
+		{
+			name: "Repeated slug",
+			records: []models.ShortenedURL{
+				newURL(user1, 2, "http://demo.com/2"),
+				newURL(user1, 2, "http://demo.com/3"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batched, err := s.Batch(context.TODO(), tt.records)
+			assert.ErrorIs(t, err, shortenedurl.ErrSlugCollision)
+			assert.Nil(t, batched)
+		})
+	}
+
+	// Nothing but the first URL is saved.
+	got, err := s.GetBySlug(context.TODO(), saved.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, saved, got)
+
+	stat, err := s.Stat(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, models.Stat{URLs: 1, Users: 1}, stat)
+}
+
+// testSlugLength checks the slugs of variable length, such as the counter slugs
+// and the custom aliases.
+func testSlugLength(t *testing.T, s Storage, _ Options) {
+	var want []models.ShortenedURL
+	for i, slug := range []string{"a", "spring-sale", "slug-of-the-maximum-length-32chr"} {
+		v := models.NewShortenedURL(user1, "1", fmt.Sprintf("http://demo.com/%d", i), slug, "http://127.0.0.1/"+slug)
+		require.NoError(t, s.Save(context.TODO(), v))
+		want = append(want, v)
+
+		got, err := s.GetBySlug(context.TODO(), slug)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+
+	records, err := s.CollectByUser(context.TODO(), user1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, want, records)
+}
+
+func testSlugSequence(t *testing.T, s Storage, _ Options) {
+	const (
+		workers = 8
+		perWork = 50
+	)
+
+	var (
+		wg   sync.WaitGroup
+		mtx  sync.Mutex
+		seen = make(map[uint64]bool, workers*perWork)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var prev uint64
+			for i := 0; i < perWork; i++ {
+				next, err := s.Next(context.TODO())
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Greater(t, next, prev)
+				prev = next
+
+				mtx.Lock()
+				assert.False(t, seen[next], "sequence value is repeated")
+				seen[next] = true
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, workers*perWork)
+}
+
+func testRestart(t *testing.T, s Storage, opts Options) {
+	if opts.Reopen == nil {
+		t.Skip("the storage cannot be reopened")
+	}
+
+	// The sequence values are used up by the duplicates and the deleted URLs.
+	var last uint64
+	for i := 0; i < 20; i++ {
+		next, err := s.Next(context.TODO())
+		require.NoError(t, err)
+		last = next
+	}
+	kept := newURL(user1, 1, "http://demo.com/1")
+	deleted := newURL(user1, 2, "http://demo.com/2")
+	require.NoError(t, s.Save(context.TODO(), kept))
+	require.NoError(t, s.Save(context.TODO(), deleted))
+	require.NoError(t, s.Delete(user1, []string{deleted.Slug}))
+
+	s = opts.Reopen(t, s)
+
+	got, err := s.GetBySlug(context.TODO(), kept.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, kept.Raw, got.Raw)
+	got, err = s.GetBySlug(context.TODO(), deleted.Slug)
+	require.NoError(t, err)
+	assert.True(t, got.IsDeleted)
+
+	// The reopened storage never hands out the used up values again.
+	next, err := s.Next(context.TODO())
+	require.NoError(t, err)
+	assert.Greater(t, next, last)
+}
+
+func testCollectByUser(t *testing.T, s Storage, _ Options) {
+	want := []models.ShortenedURL{
+		newURL(user1, 1, "http://demo.com/1"),
+		newURL(user1, 2, "http://demo.com/2"),
+	}
+	for _, v := range want {
+		require.NoError(t, s.Save(context.TODO(), v))
+	}
+	require.NoError(t, s.Save(context.TODO(), newURL(user2, 3, "http://demo.com/3")))
+
+	got, err := s.CollectByUser(context.TODO(), user1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, want, got)
+}
+
+func testDelete(t *testing.T, s Storage, _ Options) {
+	deleted := newURL(user1, 1, "http://demo.com/1")
+	alive := newURL(user1, 2, "http://demo.com/2")
+	require.NoError(t, s.Save(context.TODO(), deleted))
+	require.NoError(t, s.Save(context.TODO(), alive))
+
+	require.NoError(t, s.Delete(user1, []string{deleted.Slug, "unknown"}))
+	// Deletion is idempotent.
+	require.NoError(t, s.Delete(user1, []string{deleted.Slug}))
+
+	got, err := s.GetBySlug(context.TODO(), deleted.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, deleted.Slug, got.Slug)
+	assert.True(t, got.IsDeleted)
+
+	got, err = s.GetByURL(context.TODO(), user1, deleted.Raw)
+	require.NoError(t, err)
+	assert.True(t, got.IsDeleted)
+
+	got, err = s.GetBySlug(context.TODO(), alive.Slug)
+	require.NoError(t, err)
+	assert.False(t, got.IsDeleted)
+
+	// Deleted URLs are still listed to the owner.
+	records, err := s.CollectByUser(context.TODO(), user1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(records))
+}
+
+func testExpire(t *testing.T, s Storage, _ Options) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	expired := newURL(user1, 1, "http://demo.com/1")
+	expired.ExpiresAt = now.Add(-time.Minute)
+	alive := newURL(user1, 2, "http://demo.com/2")
+	alive.ExpiresAt = now.Add(time.Hour)
+	forever := newURL(user1, 3, "http://demo.com/3")
+	batched := newURL(user1, 4, "http://demo.com/4")
+	batched.ExpiresAt = now.Add(-time.Second)
+
+	for _, v := range []models.ShortenedURL{expired, alive, forever} {
+		require.NoError(t, s.Save(context.TODO(), v))
+	}
+	_, err := s.Batch(context.TODO(), []models.ShortenedURL{batched})
+	require.NoError(t, err)
+
+	got, err := s.GetBySlug(context.TODO(), alive.Slug)
+	require.NoError(t, err)
+	assert.True(t, alive.ExpiresAt.Equal(got.ExpiresAt), "expires at %v, got %v", alive.ExpiresAt, got.ExpiresAt)
+
+	n, err := s.Expire(context.TODO(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	// Expiration is idempotent.
+	n, err = s.Expire(context.TODO(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	for _, v := range []models.ShortenedURL{expired, batched} {
+		got, err = s.GetBySlug(context.TODO(), v.Slug)
+		require.NoError(t, err)
+		assert.True(t, got.IsExpired)
+		assert.True(t, v.ExpiresAt.Equal(got.ExpiresAt))
+	}
+	got, err = s.GetBySlug(context.TODO(), alive.Slug)
+	require.NoError(t, err)
+	assert.False(t, got.IsExpired)
+
+	// Expired URLs are not listed.
+	records, err := s.CollectByUser(context.TODO(), user1)
+	require.NoError(t, err)
+	slugs := make([]string, len(records))
+	for i, v := range records {
+		slugs[i] = v.Slug
+	}
+	assert.ElementsMatch(t, []string{alive.Slug, forever.Slug}, slugs)
+}
+
+func testWindow(t *testing.T, s Storage, _ Options) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	pending := newURL(user1, 1, "http://demo.com/1")
+	pending.ActivateAt = now.Add(time.Hour)
+	pending.DeactivateAt = now.Add(2 * time.Hour)
+	closing := newURL(user1, 2, "http://demo.com/2")
+	closing.DeactivateAt = now.Add(time.Minute)
+	batched := newURL(user1, 3, "http://demo.com/3")
+	batched.ActivateAt = now.Add(time.Minute)
+
+	require.NoError(t, s.Save(context.TODO(), pending))
+	require.NoError(t, s.Save(context.TODO(), closing))
+	_, err := s.Batch(context.TODO(), []models.ShortenedURL{batched})
+	require.NoError(t, err)
+
+	for _, v := range []models.ShortenedURL{pending, closing, batched} {
+		got, err := s.GetBySlug(context.TODO(), v.Slug)
+		require.NoError(t, err)
+		assert.True(t, v.ActivateAt.Equal(got.ActivateAt), "activate at %v, got %v", v.ActivateAt, got.ActivateAt)
+		assert.True(t, v.DeactivateAt.Equal(got.DeactivateAt),
+			"deactivate at %v, got %v", v.DeactivateAt, got.DeactivateAt)
+	}
+
+	// The URLs are listed to the owner regardless of the window.
+	records, err := s.CollectByUser(context.TODO(), user1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(records))
+}
+
+func testRedirect(t *testing.T, s Storage, _ Options) {
+	permanent := newURL(user1, 1, "http://demo.com/1")
+	permanent.RedirectStatus = http.StatusMovedPermanently
+	permanent.CachePolicy = models.CachePublic
+	tracking := newURL(user1, 2, "http://demo.com/2")
+	tracking.RedirectStatus = http.StatusFound
+	tracking.CachePolicy = models.CacheNoStore
+	batched := newURL(user1, 3, "http://demo.com/3")
+	batched.RedirectStatus = http.StatusPermanentRedirect
+	defaults := newURL(user1, 4, "http://demo.com/4")
+
+	require.NoError(t, s.Save(context.TODO(), permanent))
+	require.NoError(t, s.Save(context.TODO(), tracking))
+	_, err := s.Batch(context.TODO(), []models.ShortenedURL{batched, defaults})
+	require.NoError(t, err)
+
+	for _, v := range []models.ShortenedURL{permanent, tracking, batched, defaults} {
+		got, err := s.GetBySlug(context.TODO(), v.Slug)
+		require.NoError(t, err)
+		assert.Equal(t, v.RedirectStatus, got.RedirectStatus)
+		assert.Equal(t, v.CachePolicy, got.CachePolicy)
+	}
+}
+
+func testQueryForwarding(t *testing.T, s Storage, _ Options) {
+	merged := newURL(user1, 1, "http://demo.com/1")
+	merged.QueryMode = models.QueryMerge
+	merged.FixedParams = "utm_medium=email&utm_source=newsletter"
+	forwarded := newURL(user1, 2, "http://demo.com/2")
+	forwarded.ForwardPath = true
+	batched := newURL(user1, 3, "http://demo.com/3")
+	batched.QueryMode = models.QueryOverride
+	batched.FixedParams = "utm_campaign=spring"
+	batched.ForwardPath = true
+	defaults := newURL(user1, 4, "http://demo.com/4")
+
+	require.NoError(t, s.Save(context.TODO(), merged))
+	require.NoError(t, s.Save(context.TODO(), forwarded))
+	_, err := s.Batch(context.TODO(), []models.ShortenedURL{batched, defaults})
+	require.NoError(t, err)
+
+	for _, v := range []models.ShortenedURL{merged, forwarded, batched, defaults} {
+		got, err := s.GetBySlug(context.TODO(), v.Slug)
+		require.NoError(t, err)
+		assert.Equal(t, v.QueryMode, got.QueryMode)
+		assert.Equal(t, v.FixedParams, got.FixedParams)
+		assert.Equal(t, v.ForwardPath, got.ForwardPath)
+	}
+}
+
+func testVisit(t *testing.T, s Storage, _ Options) {
+	limited := newURL(user1, 1, "http://demo.com/1")
+	limited.MaxVisits = 2
+	unlimited := newURL(user1, 2, "http://demo.com/2")
+	batched := newURL(user1, 3, "http://demo.com/3")
+	batched.MaxVisits = 1
+
+	require.NoError(t, s.Save(context.TODO(), limited))
+	require.NoError(t, s.Save(context.TODO(), unlimited))
+	_, err := s.Batch(context.TODO(), []models.ShortenedURL{batched})
+	require.NoError(t, err)
+
+	for _, want := range []int{1, 0} {
+		left, err := s.Visit(context.TODO(), limited.Slug)
+		require.NoError(t, err)
+		assert.Equal(t, want, left)
+	}
+	_, err = s.Visit(context.TODO(), limited.Slug)
+	assert.ErrorIs(t, err, shortenedurl.ErrVisitsExhausted)
+
+	left, err := s.Visit(context.TODO(), batched.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, 0, left)
+
+	// Unlimited and unknown URLs are not counted.
+	for _, slug := range []string{unlimited.Slug, "s999999"} {
+		left, err = s.Visit(context.TODO(), slug)
+		require.NoError(t, err)
+		assert.Equal(t, -1, left)
+	}
+
+	got, err := s.GetBySlug(context.TODO(), limited.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.MaxVisits)
+	assert.Equal(t, 2, got.Visits)
+	assert.Equal(t, 0, got.VisitsLeft())
+
+	// The visits left are listed.
+	records, err := s.CollectByUser(context.TODO(), user1)
+	require.NoError(t, err)
+	visitsLeft := make(map[string]int, len(records))
+	for _, v := range records {
+		visitsLeft[v.Slug] = v.VisitsLeft()
+	}
+	assert.Equal(t, map[string]int{
+		limited.Slug:   0,
+		unlimited.Slug: -1,
+		batched.Slug:   0,
+	}, visitsLeft)
+}
+
+func testConcurrentVisits(t *testing.T, s Storage, _ Options) {
+	const (
+		maxVisits = 5
+		workers   = 16
+	)
+
+	v := newURL(user1, 1, "http://demo.com/1")
+	v.MaxVisits = maxVisits
+	require.NoError(t, s.Save(context.TODO(), v))
+
+	var (
+		wg        sync.WaitGroup
+		mtx       sync.Mutex
+		counted   []int
+		exhausted int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			left, err := s.Visit(context.TODO(), v.Slug)
+			mtx.Lock()
+			defer mtx.Unlock()
+			if errors.Is(err, shortenedurl.ErrVisitsExhausted) {
+				exhausted++
+				return
+			}
+			assert.NoError(t, err)
+			counted = append(counted, left)
+		}()
+	}
+	wg.Wait()
+
+	// Each visit is counted once.
+	assert.ElementsMatch(t, []int{4, 3, 2, 1, 0}, counted)
+	assert.Equal(t, workers-maxVisits, exhausted)
+}
+
+func testEdit(t *testing.T, s Storage, _ Options) {
+	v := newURL(user1, 1, "http://demo.com/1")
+	require.NoError(t, s.Save(context.TODO(), v))
+
+	changedAt := time.Now().UTC().Truncate(time.Second)
+	change, err := s.Edit(context.TODO(), models.URLChange{
+		Slug:      v.Slug,
+		UserID:    user1,
+		New:       "http://demo.com/2",
+		ChangedAt: changedAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, change.Version)
+	assert.Equal(t, v.Raw, change.Old)
+	assert.Equal(t, "http://demo.com/2", change.New)
+
+	// The URL is got and deduplicated by the new destination.
+	got, err := s.GetBySlug(context.TODO(), v.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, "http://demo.com/2", got.Raw)
+
+	got, err = s.GetByURL(context.TODO(), user1, "http://demo.com/2")
+	require.NoError(t, err)
+	assert.Equal(t, v.Slug, got.Slug)
+
+	got, err = s.GetByURL(context.TODO(), user1, v.Raw)
+	require.NoError(t, err)
+	assert.True(t, got.Empty(), "found by the old destination")
+
+	// The old destination may be shortened again.
+	again := newURL(user1, 2, v.Raw)
+	require.NoError(t, s.Save(context.TODO(), again))
+
+	change, err = s.Edit(context.TODO(), models.URLChange{
+		Slug:      v.Slug,
+		UserID:    user1,
+		New:       "http://demo.com/3",
+		Canonical: "http://demo.com/3/",
+		ChangedAt: changedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, change.Version)
+
+	got, err = s.GetByURL(context.TODO(), user1, "http://demo.com/3/")
+	require.NoError(t, err)
+	assert.Equal(t, v.Slug, got.Slug)
+	assert.Equal(t, "http://demo.com/3", got.Raw)
+
+	// The change of the outdated version is not made.
+	_, err = s.Edit(context.TODO(), models.URLChange{
+		Slug:    v.Slug,
+		Version: 3,
+		UserID:  user1,
+		New:     "http://demo.com/4",
+	})
+	assert.ErrorIs(t, err, shortenedurl.ErrVersionConflict)
+
+	// The changes are listed in the version order.
+	history, err := s.History(context.TODO(), user1, v.Slug)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(history))
+	for i, want := range []models.URLChange{
+		{Slug: v.Slug, Version: 2, Old: v.Raw, New: "http://demo.com/2",
+			UserID: user1, ChangedAt: changedAt},
+		{Slug: v.Slug, Version: 3, Old: "http://demo.com/2", New: "http://demo.com/3",
+			Canonical: "http://demo.com/3/", UserID: user1, ChangedAt: changedAt.Add(time.Minute)},
+	} {
+		assert.True(t, want.ChangedAt.Equal(history[i].ChangedAt),
+			"changed at %v, got %v", want.ChangedAt, history[i].ChangedAt)
+		history[i].ChangedAt = want.ChangedAt
+		assert.Equal(t, want, history[i])
+	}
+
+	// The URL that has not been edited has no changes.
+	history, err = s.History(context.TODO(), user1, again.Slug)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	// Only the owner edits the URL and lists its changes.
+	_, err = s.Edit(context.TODO(), models.URLChange{Slug: v.Slug, UserID: user2, New: "http://demo.com/4"})
+	assert.ErrorIs(t, err, shortenedurl.ErrNotOwner)
+	_, err = s.History(context.TODO(), user2, v.Slug)
+	assert.ErrorIs(t, err, shortenedurl.ErrNotOwner)
+
+	_, err = s.Edit(context.TODO(), models.URLChange{Slug: "unknown", UserID: user1, New: "http://demo.com/4"})
+	assert.ErrorIs(t, err, shortenedurl.ErrNotFound)
+	_, err = s.History(context.TODO(), user1, "unknown")
+	assert.ErrorIs(t, err, shortenedurl.ErrNotFound)
+
+	// The deleted URL is not edited.
+	require.NoError(t, s.Delete(user1, []string{v.Slug}))
+	_, err = s.Edit(context.TODO(), models.URLChange{Slug: v.Slug, UserID: user1, New: "http://demo.com/4"})
+	assert.ErrorIs(t, err, shortenedurl.ErrNotFound)
+}
+
+func testEditConflicts(t *testing.T, s Storage, opts Options) {
+	v := newURL(user1, 1, "http://demo.com/1")
+	own := newURL(user1, 2, "http://demo.com/2")
+	other := newURL(user2, 3, "http://demo.com/3")
+	for _, r := range []models.ShortenedURL{v, own, other} {
+		require.NoError(t, s.Save(context.TODO(), r))
+	}
+
+	// The destination of another URL of the user conflicts in any scope.
+	_, err := s.Edit(context.TODO(), models.URLChange{Slug: v.Slug, UserID: user1, New: own.Raw})
+	assert.ErrorIs(t, err, shortenedurl.ErrUniqueViolation)
+
+	// The destination of another user's URL conflicts in the global scope only.
+	_, err = s.Edit(context.TODO(), models.URLChange{Slug: v.Slug, UserID: user1, New: other.Raw})
+	if opts.Scope == shortenedurl.ScopeGlobal {
+		assert.ErrorIs(t, err, shortenedurl.ErrUniqueViolation)
+	} else {
+		assert.NoError(t, err)
+	}
+
+	// The URL may be edited to its own destination.
+	got, err := s.GetBySlug(context.TODO(), v.Slug)
+	require.NoError(t, err)
+	_, err = s.Edit(context.TODO(), models.URLChange{Slug: v.Slug, UserID: user1, New: got.Raw})
+	assert.NoError(t, err)
+
+	// The conflicting edits are not listed.
+	history, err := s.History(context.TODO(), user1, v.Slug)
+	require.NoError(t, err)
+	if opts.Scope == shortenedurl.ScopeGlobal {
+		assert.Equal(t, 1, len(history))
+	} else {
+		assert.Equal(t, 2, len(history))
+	}
+}
+
+func testOwnership(t *testing.T, s Storage, _ Options) {
+	v := newURL(user1, 1, "http://demo.com/1")
+	require.NoError(t, s.Save(context.TODO(), v))
+
+	require.NoError(t, s.Delete(user2, []string{v.Slug}))
+
+	got, err := s.GetBySlug(context.TODO(), v.Slug)
+	require.NoError(t, err)
+	assert.False(t, got.IsDeleted, "deleted by another user")
+}
+
+func testStat(t *testing.T, s Storage, _ Options) {
+	stat, err := s.Stat(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, models.Stat{}, stat)
+
+	records := []models.ShortenedURL{
+		newURL(user1, 1, "http://demo.com/1"),
+		newURL(user1, 2, "http://demo.com/2"),
+		newURL(user2, 3, "http://demo.com/3"),
+	}
+	for _, v := range records {
+		require.NoError(t, s.Save(context.TODO(), v))
+	}
+	require.NoError(t, s.Delete(user2, []string{records[2].Slug}))
+
+	// Deleted URLs are counted.
+	stat, err = s.Stat(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, models.Stat{URLs: 3, Users: 2}, stat)
+}
+
+func testConcurrency(t *testing.T, s Storage, _ Options) {
+	const (
+		workers = 8
+		perUser = 25
+	)
+	users := []string{user1, user2, user3}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			userID := users[w%len(users)]
+			for i := 0; i < perUser; i++ {
+				n := w*perUser + i
+				v := newURL(userID, n, fmt.Sprintf("http://demo.com/%d", n))
+				if !assert.NoError(t, s.Save(context.TODO(), v)) {
+					return
+				}
+
+				got, err := s.GetBySlug(context.TODO(), v.Slug)
+				assert.NoError(t, err)
+				assert.Equal(t, v.Raw, got.Raw)
+
+				_, err = s.CollectByUser(context.TODO(), userID)
+				assert.NoError(t, err)
+				_, err = s.Stat(context.TODO())
+				assert.NoError(t, err)
+
+				if i%5 == 0 {
+					assert.NoError(t, s.Delete(userID, []string{v.Slug}))
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stat, err := s.Stat(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, models.Stat{URLs: workers * perUser, Users: len(users)}, stat)
+
+	var deleted int
+	for _, userID := range users {
+		records, err := s.CollectByUser(context.TODO(), userID)
+		require.NoError(t, err)
+		for _, v := range records {
+			if v.IsDeleted {
+				deleted++
+			}
+		}
+	}
+	assert.Equal(t, workers*perUser/5, deleted)
+}
@@ -0,0 +1,13 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM "shorturls" GROUP BY "original" HAVING count(*) > 1) THEN
+        RAISE EXCEPTION 'originals shortened by several users do not fit UNIQUE ("original"), delete the duplicates before the downgrade';
+    END IF;
+END
+$$;
+
+ALTER TABLE "shorturls"
+    DROP CONSTRAINT "user_original_uniq";
+
+ALTER TABLE "shorturls"
+    ADD CONSTRAINT "original_uniq" UNIQUE ("original");
\ No newline at end of file
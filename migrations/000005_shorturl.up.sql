@@ -0,0 +1,5 @@
+ALTER TABLE "shorturls"
+    DROP CONSTRAINT "original_uniq";
+
+ALTER TABLE "shorturls"
+    ADD CONSTRAINT "user_original_uniq" UNIQUE ("user_id", "original");
\ No newline at end of file
@@ -0,0 +1,12 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM "shorturls" WHERE length("slug") <> 7) THEN
+        RAISE EXCEPTION 'slugs other than 7 characters do not fit char(7), delete them before the downgrade';
+    END IF;
+END
+$$;
+
+DROP SEQUENCE IF EXISTS "shorturls_slug_seq";
+
+ALTER TABLE "shorturls"
+    ALTER COLUMN "slug" TYPE char(7);
\ No newline at end of file
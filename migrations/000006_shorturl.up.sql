@@ -0,0 +1,4 @@
+ALTER TABLE "shorturls"
+    ALTER COLUMN "slug" TYPE varchar;
+
+CREATE SEQUENCE IF NOT EXISTS "shorturls_slug_seq" AS bigint MINVALUE 1;
\ No newline at end of file
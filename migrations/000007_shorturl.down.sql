@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS "canonical_idx";
+
+ALTER TABLE "shorturls"
+    DROP CONSTRAINT "user_canonical_uniq";
+
+ALTER TABLE "shorturls"
+    ADD CONSTRAINT "user_original_uniq" UNIQUE ("user_id", "original");
+
+ALTER TABLE "shorturls"
+    DROP COLUMN "canonical";
\ No newline at end of file
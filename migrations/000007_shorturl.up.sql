@@ -0,0 +1,15 @@
+ALTER TABLE "shorturls"
+    ADD COLUMN "canonical" varchar;
+
+UPDATE "shorturls" SET "canonical" = "original";
+
+ALTER TABLE "shorturls"
+    ALTER COLUMN "canonical" SET NOT NULL;
+
+ALTER TABLE "shorturls"
+    DROP CONSTRAINT "user_original_uniq";
+
+ALTER TABLE "shorturls"
+    ADD CONSTRAINT "user_canonical_uniq" UNIQUE ("user_id", "canonical");
+
+CREATE INDEX "canonical_idx" ON "shorturls" ("canonical");
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX "shorturls_expires_at_idx";
+
+ALTER TABLE "shorturls"
+    DROP COLUMN "expired",
+    DROP COLUMN "expires_at";
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE "shorturls"
+    ADD COLUMN "expires_at" timestamptz,
+    ADD COLUMN "expired" boolean NOT NULL DEFAULT false;
+
+CREATE INDEX "shorturls_expires_at_idx" ON "shorturls" ("expires_at")
+    WHERE "expires_at" IS NOT NULL AND NOT "expired";
\ No newline at end of file
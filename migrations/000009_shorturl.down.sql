@@ -0,0 +1,3 @@
+ALTER TABLE "shorturls"
+    DROP COLUMN "visits",
+    DROP COLUMN "max_visits";
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "shorturls"
+    ADD COLUMN "max_visits" integer NOT NULL DEFAULT 0,
+    ADD COLUMN "visits" integer NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE "shorturls"
+    ADD COLUMN "password_hash" varchar NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "shorturls"
+    DROP COLUMN "deactivate_at",
+    DROP COLUMN "activate_at";
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "shorturls"
+    ADD COLUMN "activate_at" timestamptz,
+    ADD COLUMN "deactivate_at" timestamptz;
\ No newline at end of file
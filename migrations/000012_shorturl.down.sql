@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "shorturl_changes";
\ No newline at end of file
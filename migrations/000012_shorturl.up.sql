@@ -0,0 +1,10 @@
+CREATE TABLE "shorturl_changes" (
+  "slug" varchar NOT NULL REFERENCES "shorturls" ("slug") ON DELETE CASCADE,
+  "version" integer NOT NULL,
+  "old" varchar NOT NULL,
+  "new" varchar NOT NULL,
+  "canonical" varchar NOT NULL,
+  "user_id" uuid NOT NULL,
+  "changed_at" timestamptz NOT NULL,
+  PRIMARY KEY ("slug", "version")
+);
\ No newline at end of file
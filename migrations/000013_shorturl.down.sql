@@ -0,0 +1,3 @@
+ALTER TABLE "shorturls"
+    DROP COLUMN "redirect_status",
+    DROP COLUMN "cache_policy";
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "shorturls"
+    ADD COLUMN "redirect_status" integer NOT NULL DEFAULT 0,
+    ADD COLUMN "cache_policy" varchar NOT NULL DEFAULT '';
\ No newline at end of file
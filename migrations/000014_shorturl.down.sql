@@ -0,0 +1,4 @@
+ALTER TABLE "shorturls"
+    DROP COLUMN "query_mode",
+    DROP COLUMN "fixed_params",
+    DROP COLUMN "forward_path";
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE "shorturls"
+    ADD COLUMN "query_mode" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "fixed_params" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "forward_path" boolean NOT NULL DEFAULT false;
\ No newline at end of file
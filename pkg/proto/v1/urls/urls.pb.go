@@ -9,6 +9,7 @@ package urls
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -20,12 +21,71 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Status says whether the URL was created or already existed.
+type BatchURLsResponse_Status int32
+
+const (
+	BatchURLsResponse_CREATED BatchURLsResponse_Status = 0
+	BatchURLsResponse_EXISTED BatchURLsResponse_Status = 1
+)
+
+// Enum value maps for BatchURLsResponse_Status.
+var (
+	BatchURLsResponse_Status_name = map[int32]string{
+		0: "CREATED",
+		1: "EXISTED",
+	}
+	BatchURLsResponse_Status_value = map[string]int32{
+		"CREATED": 0,
+		"EXISTED": 1,
+	}
+)
+
+func (x BatchURLsResponse_Status) Enum() *BatchURLsResponse_Status {
+	p := new(BatchURLsResponse_Status)
+	*p = x
+	return p
+}
+
+func (x BatchURLsResponse_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BatchURLsResponse_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_proto_urls_proto_enumTypes[0].Descriptor()
+}
+
+func (BatchURLsResponse_Status) Type() protoreflect.EnumType {
+	return &file_api_v1_proto_urls_proto_enumTypes[0]
+}
+
+func (x BatchURLsResponse_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BatchURLsResponse_Status.Descriptor instead.
+func (BatchURLsResponse_Status) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{3, 0}
+}
+
 type ShortURLRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Raw string `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	Raw            string                 `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	Alias          string                 `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Ttl            int64                  `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	MaxVisits      int32                  `protobuf:"varint,5,opt,name=max_visits,json=maxVisits,proto3" json:"max_visits,omitempty"`
+	Password       string                 `protobuf:"bytes,6,opt,name=password,proto3" json:"password,omitempty"`
+	ActivateAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=activate_at,json=activateAt,proto3" json:"activate_at,omitempty"`
+	DeactivateAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=deactivate_at,json=deactivateAt,proto3" json:"deactivate_at,omitempty"`
+	RedirectStatus int32                  `protobuf:"varint,9,opt,name=redirect_status,json=redirectStatus,proto3" json:"redirect_status,omitempty"`
+	CachePolicy    string                 `protobuf:"bytes,10,opt,name=cache_policy,json=cachePolicy,proto3" json:"cache_policy,omitempty"`
+	QueryMode      string                 `protobuf:"bytes,11,opt,name=query_mode,json=queryMode,proto3" json:"query_mode,omitempty"`
+	FixedParams    map[string]string      `protobuf:"bytes,12,rep,name=fixed_params,json=fixedParams,proto3" json:"fixed_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ForwardPath    bool                   `protobuf:"varint,13,opt,name=forward_path,json=forwardPath,proto3" json:"forward_path,omitempty"`
 }
 
 func (x *ShortURLRequest) Reset() {
@@ -67,6 +127,90 @@ func (x *ShortURLRequest) GetRaw() string {
 	return ""
 }
 
+func (x *ShortURLRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ShortURLRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ShortURLRequest) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *ShortURLRequest) GetMaxVisits() int32 {
+	if x != nil {
+		return x.MaxVisits
+	}
+	return 0
+}
+
+func (x *ShortURLRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ShortURLRequest) GetActivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ActivateAt
+	}
+	return nil
+}
+
+func (x *ShortURLRequest) GetDeactivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeactivateAt
+	}
+	return nil
+}
+
+func (x *ShortURLRequest) GetRedirectStatus() int32 {
+	if x != nil {
+		return x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *ShortURLRequest) GetCachePolicy() string {
+	if x != nil {
+		return x.CachePolicy
+	}
+	return ""
+}
+
+func (x *ShortURLRequest) GetQueryMode() string {
+	if x != nil {
+		return x.QueryMode
+	}
+	return ""
+}
+
+func (x *ShortURLRequest) GetFixedParams() map[string]string {
+	if x != nil {
+		return x.FixedParams
+	}
+	return nil
+}
+
+func (x *ShortURLRequest) GetForwardPath() bool {
+	if x != nil {
+		return x.ForwardPath
+	}
+	return false
+}
+
 type ShortURLResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -472,17 +616,21 @@ func (*DelURLsResponse) Descriptor() ([]byte, []int) {
 	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{9}
 }
 
-type BatchURLsRequest_URL struct {
+// URLChange is a change of the shortened URL destination.
+type URLChange struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Raw    string `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
-	CorrId string `protobuf:"bytes,2,opt,name=corr_id,json=corrId,proto3" json:"corr_id,omitempty"`
+	Version   int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	OldRaw    string                 `protobuf:"bytes,2,opt,name=old_raw,json=oldRaw,proto3" json:"old_raw,omitempty"`
+	NewRaw    string                 `protobuf:"bytes,3,opt,name=new_raw,json=newRaw,proto3" json:"new_raw,omitempty"`
+	UserId    string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ChangedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
 }
 
-func (x *BatchURLsRequest_URL) Reset() {
-	*x = BatchURLsRequest_URL{}
+func (x *URLChange) Reset() {
+	*x = URLChange{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_v1_proto_urls_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -490,13 +638,13 @@ func (x *BatchURLsRequest_URL) Reset() {
 	}
 }
 
-func (x *BatchURLsRequest_URL) String() string {
+func (x *URLChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchURLsRequest_URL) ProtoMessage() {}
+func (*URLChange) ProtoMessage() {}
 
-func (x *BatchURLsRequest_URL) ProtoReflect() protoreflect.Message {
+func (x *URLChange) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_proto_urls_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -508,36 +656,57 @@ func (x *BatchURLsRequest_URL) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchURLsRequest_URL.ProtoReflect.Descriptor instead.
-func (*BatchURLsRequest_URL) Descriptor() ([]byte, []int) {
-	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{2, 0}
+// Deprecated: Use URLChange.ProtoReflect.Descriptor instead.
+func (*URLChange) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *BatchURLsRequest_URL) GetRaw() string {
+func (x *URLChange) GetVersion() int32 {
 	if x != nil {
-		return x.Raw
+		return x.Version
+	}
+	return 0
+}
+
+func (x *URLChange) GetOldRaw() string {
+	if x != nil {
+		return x.OldRaw
 	}
 	return ""
 }
 
-func (x *BatchURLsRequest_URL) GetCorrId() string {
+func (x *URLChange) GetNewRaw() string {
 	if x != nil {
-		return x.CorrId
+		return x.NewRaw
 	}
 	return ""
 }
 
-type BatchURLsResponse_URL struct {
+func (x *URLChange) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *URLChange) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
+type EditURLRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ShortUrl string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
-	CorrId   string `protobuf:"bytes,2,opt,name=corr_id,json=corrId,proto3" json:"corr_id,omitempty"`
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Raw  string `protobuf:"bytes,2,opt,name=raw,proto3" json:"raw,omitempty"`
 }
 
-func (x *BatchURLsResponse_URL) Reset() {
-	*x = BatchURLsResponse_URL{}
+func (x *EditURLRequest) Reset() {
+	*x = EditURLRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_v1_proto_urls_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -545,13 +714,13 @@ func (x *BatchURLsResponse_URL) Reset() {
 	}
 }
 
-func (x *BatchURLsResponse_URL) String() string {
+func (x *EditURLRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchURLsResponse_URL) ProtoMessage() {}
+func (*EditURLRequest) ProtoMessage() {}
 
-func (x *BatchURLsResponse_URL) ProtoReflect() protoreflect.Message {
+func (x *EditURLRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_proto_urls_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -563,40 +732,35 @@ func (x *BatchURLsResponse_URL) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchURLsResponse_URL.ProtoReflect.Descriptor instead.
-func (*BatchURLsResponse_URL) Descriptor() ([]byte, []int) {
-	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{3, 0}
+// Deprecated: Use EditURLRequest.ProtoReflect.Descriptor instead.
+func (*EditURLRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *BatchURLsResponse_URL) GetShortUrl() string {
+func (x *EditURLRequest) GetSlug() string {
 	if x != nil {
-		return x.ShortUrl
+		return x.Slug
 	}
 	return ""
 }
 
-func (x *BatchURLsResponse_URL) GetCorrId() string {
+func (x *EditURLRequest) GetRaw() string {
 	if x != nil {
-		return x.CorrId
+		return x.Raw
 	}
 	return ""
 }
 
-type GetShortenedURLResponse_ShortenedURL struct {
+type EditURLResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	UserId    string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	CorrId    string `protobuf:"bytes,2,opt,name=corr_id,json=corrId,proto3" json:"corr_id,omitempty"`
-	Raw       string `protobuf:"bytes,3,opt,name=raw,proto3" json:"raw,omitempty"`
-	Slug      string `protobuf:"bytes,4,opt,name=slug,proto3" json:"slug,omitempty"`
-	Value     string `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
-	IsDeleted bool   `protobuf:"varint,6,opt,name=is_deleted,json=isDeleted,proto3" json:"is_deleted,omitempty"`
+	Change *URLChange `protobuf:"bytes,1,opt,name=change,proto3" json:"change,omitempty"`
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) Reset() {
-	*x = GetShortenedURLResponse_ShortenedURL{}
+func (x *EditURLResponse) Reset() {
+	*x = EditURLResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_v1_proto_urls_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -604,13 +768,13 @@ func (x *GetShortenedURLResponse_ShortenedURL) Reset() {
 	}
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) String() string {
+func (x *EditURLResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetShortenedURLResponse_ShortenedURL) ProtoMessage() {}
+func (*EditURLResponse) ProtoMessage() {}
 
-func (x *GetShortenedURLResponse_ShortenedURL) ProtoReflect() protoreflect.Message {
+func (x *EditURLResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_v1_proto_urls_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -622,79 +786,138 @@ func (x *GetShortenedURLResponse_ShortenedURL) ProtoReflect() protoreflect.Messa
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetShortenedURLResponse_ShortenedURL.ProtoReflect.Descriptor instead.
-func (*GetShortenedURLResponse_ShortenedURL) Descriptor() ([]byte, []int) {
-	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{5, 0}
+// Deprecated: Use EditURLResponse.ProtoReflect.Descriptor instead.
+func (*EditURLResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) GetUserId() string {
+func (x *EditURLResponse) GetChange() *URLChange {
 	if x != nil {
-		return x.UserId
+		return x.Change
 	}
-	return ""
+	return nil
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) GetCorrId() string {
-	if x != nil {
-		return x.CorrId
+type ListURLChangesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+}
+
+func (x *ListURLChangesRequest) Reset() {
+	*x = ListURLChangesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_proto_urls_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return ""
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) GetRaw() string {
-	if x != nil {
-		return x.Raw
+func (x *ListURLChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLChangesRequest) ProtoMessage() {}
+
+func (x *ListURLChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) GetSlug() string {
+// Deprecated: Use ListURLChangesRequest.ProtoReflect.Descriptor instead.
+func (*ListURLChangesRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListURLChangesRequest) GetSlug() string {
 	if x != nil {
 		return x.Slug
 	}
 	return ""
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) GetValue() string {
-	if x != nil {
-		return x.Value
+type ListURLChangesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Changes []*URLChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *ListURLChangesResponse) Reset() {
+	*x = ListURLChangesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_proto_urls_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return ""
 }
 
-func (x *GetShortenedURLResponse_ShortenedURL) GetIsDeleted() bool {
+func (x *ListURLChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLChangesResponse) ProtoMessage() {}
+
+func (x *ListURLChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLChangesResponse.ProtoReflect.Descriptor instead.
+func (*ListURLChangesResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListURLChangesResponse) GetChanges() []*URLChange {
 	if x != nil {
-		return x.IsDeleted
+		return x.Changes
 	}
-	return false
+	return nil
 }
 
-type ListURLsResponse_URL struct {
+type RollbackURLRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Raw      string `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
-	ShortUrl string `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Slug    string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Version int32  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
 }
 
-func (x *ListURLsResponse_URL) Reset() {
-	*x = ListURLsResponse_URL{}
+func (x *RollbackURLRequest) Reset() {
+	*x = RollbackURLRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_v1_proto_urls_proto_msgTypes[13]
+		mi := &file_api_v1_proto_urls_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ListURLsResponse_URL) String() string {
+func (x *RollbackURLRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListURLsResponse_URL) ProtoMessage() {}
+func (*RollbackURLRequest) ProtoMessage() {}
 
-func (x *ListURLsResponse_URL) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_proto_urls_proto_msgTypes[13]
+func (x *RollbackURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -705,172 +928,915 @@ func (x *ListURLsResponse_URL) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListURLsResponse_URL.ProtoReflect.Descriptor instead.
-func (*ListURLsResponse_URL) Descriptor() ([]byte, []int) {
-	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{7, 0}
+// Deprecated: Use RollbackURLRequest.ProtoReflect.Descriptor instead.
+func (*RollbackURLRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ListURLsResponse_URL) GetRaw() string {
+func (x *RollbackURLRequest) GetSlug() string {
 	if x != nil {
-		return x.Raw
+		return x.Slug
 	}
 	return ""
 }
 
-func (x *ListURLsResponse_URL) GetShortUrl() string {
+func (x *RollbackURLRequest) GetVersion() int32 {
 	if x != nil {
-		return x.ShortUrl
+		return x.Version
 	}
-	return ""
+	return 0
 }
 
-var File_api_v1_proto_urls_proto protoreflect.FileDescriptor
+type RollbackURLResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_api_v1_proto_urls_proto_rawDesc = []byte{
-	0x0a, 0x17, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x75,
-	0x72, 0x6c, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x75, 0x72, 0x6c, 0x73, 0x2e,
-	0x76, 0x31, 0x22, 0x23, 0x0a, 0x0f, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x03, 0x72, 0x61, 0x77, 0x22, 0x2f, 0x0a, 0x10, 0x53, 0x68, 0x6f, 0x72, 0x74,
-	0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73,
-	0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x22, 0x75, 0x0a, 0x10, 0x42, 0x61, 0x74, 0x63,
-	0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x03,
-	0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73,
-	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x2e, 0x55, 0x52, 0x4c, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x1a, 0x30, 0x0a,
-	0x03, 0x55, 0x52, 0x4c, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x72, 0x61, 0x77, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x6f, 0x72, 0x72, 0x5f, 0x69,
-	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x72, 0x72, 0x49, 0x64, 0x22,
-	0x93, 0x01, 0x0a, 0x11, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0c, 0x62, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64,
-	0x5f, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x75, 0x72,
-	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x55, 0x52, 0x4c, 0x52, 0x0b, 0x62, 0x61, 0x74,
-	0x63, 0x68, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x73, 0x1a, 0x3b, 0x0a, 0x03, 0x55, 0x52, 0x4c, 0x12,
-	0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x12, 0x17, 0x0a, 0x07,
-	0x63, 0x6f, 0x72, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63,
-	0x6f, 0x72, 0x72, 0x49, 0x64, 0x22, 0x2c, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x53, 0x68, 0x6f, 0x72,
-	0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73,
-	0x6c, 0x75, 0x67, 0x22, 0x83, 0x02, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x53, 0x68, 0x6f, 0x72, 0x74,
-	0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x4a, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
-	0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52,
-	0x4c, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x1a, 0x9b, 0x01, 0x0a, 0x0c,
-	0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x12, 0x17, 0x0a, 0x07,
-	0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75,
-	0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x6f, 0x72, 0x72, 0x5f, 0x69, 0x64,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x72, 0x72, 0x49, 0x64, 0x12, 0x10,
-	0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x61, 0x77,
-	0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x73, 0x6c, 0x75, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73,
-	0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09,
-	0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0x11, 0x0a, 0x0f, 0x4c, 0x69, 0x73,
-	0x74, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x8e, 0x01, 0x0a,
-	0x10, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x44, 0x0a, 0x0e, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x75,
-	0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73,
-	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x55, 0x52, 0x4c, 0x52, 0x0d, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63,
-	0x74, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x73, 0x1a, 0x34, 0x0a, 0x03, 0x55, 0x52, 0x4c, 0x12, 0x10,
-	0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x61, 0x77,
-	0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x22, 0x26, 0x0a,
-	0x0e, 0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x14, 0x0a, 0x05, 0x73, 0x6c, 0x75, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05,
-	0x73, 0x6c, 0x75, 0x67, 0x73, 0x22, 0x11, 0x0a, 0x0f, 0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x94, 0x01, 0x0a, 0x0d, 0x55, 0x52, 0x4c,
-	0x73, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x12, 0x3f, 0x0a, 0x08, 0x53, 0x68,
-	0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x12, 0x18, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31,
-	0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74,
-	0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x42,
-	0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e,
-	0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61,
-	0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32,
-	0xa5, 0x01, 0x0a, 0x0c, 0x55, 0x52, 0x4c, 0x73, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72,
-	0x12, 0x54, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64,
-	0x55, 0x52, 0x4c, 0x12, 0x1f, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
-	0x74, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
-	0x65, 0x74, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52,
-	0x4c, 0x73, 0x12, 0x18, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
-	0x74, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x75,
-	0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x4b, 0x0a, 0x0b, 0x55, 0x52, 0x4c, 0x73, 0x44,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x12, 0x3c, 0x0a, 0x07, 0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c,
-	0x73, 0x12, 0x17, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x55,
-	0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x75, 0x72, 0x6c,
-	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x13, 0x5a, 0x11, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x75, 0x72, 0x6c, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	Change *URLChange `protobuf:"bytes,1,opt,name=change,proto3" json:"change,omitempty"`
 }
 
-var (
-	file_api_v1_proto_urls_proto_rawDescOnce sync.Once
-	file_api_v1_proto_urls_proto_rawDescData = file_api_v1_proto_urls_proto_rawDesc
-)
-
-func file_api_v1_proto_urls_proto_rawDescGZIP() []byte {
-	file_api_v1_proto_urls_proto_rawDescOnce.Do(func() {
-		file_api_v1_proto_urls_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_v1_proto_urls_proto_rawDescData)
-	})
-	return file_api_v1_proto_urls_proto_rawDescData
+func (x *RollbackURLResponse) Reset() {
+	*x = RollbackURLResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_proto_urls_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-var file_api_v1_proto_urls_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
-var file_api_v1_proto_urls_proto_goTypes = []interface{}{
-	(*ShortURLRequest)(nil),                      // 0: urls.v1.ShortURLRequest
-	(*ShortURLResponse)(nil),                     // 1: urls.v1.ShortURLResponse
-	(*BatchURLsRequest)(nil),                     // 2: urls.v1.BatchURLsRequest
-	(*BatchURLsResponse)(nil),                    // 3: urls.v1.BatchURLsResponse
-	(*GetShortenedURLRequest)(nil),               // 4: urls.v1.GetShortenedURLRequest
-	(*GetShortenedURLResponse)(nil),              // 5: urls.v1.GetShortenedURLResponse
-	(*ListURLsRequest)(nil),                      // 6: urls.v1.ListURLsRequest
-	(*ListURLsResponse)(nil),                     // 7: urls.v1.ListURLsResponse
-	(*DelURLsRequest)(nil),                       // 8: urls.v1.DelURLsRequest
-	(*DelURLsResponse)(nil),                      // 9: urls.v1.DelURLsResponse
-	(*BatchURLsRequest_URL)(nil),                 // 10: urls.v1.BatchURLsRequest.URL
-	(*BatchURLsResponse_URL)(nil),                // 11: urls.v1.BatchURLsResponse.URL
-	(*GetShortenedURLResponse_ShortenedURL)(nil), // 12: urls.v1.GetShortenedURLResponse.ShortenedURL
-	(*ListURLsResponse_URL)(nil),                 // 13: urls.v1.ListURLsResponse.URL
-}
-var file_api_v1_proto_urls_proto_depIdxs = []int32{
-	10, // 0: urls.v1.BatchURLsRequest.url:type_name -> urls.v1.BatchURLsRequest.URL
-	11, // 1: urls.v1.BatchURLsResponse.batched_urls:type_name -> urls.v1.BatchURLsResponse.URL
-	12, // 2: urls.v1.GetShortenedURLResponse.short_url:type_name -> urls.v1.GetShortenedURLResponse.ShortenedURL
-	13, // 3: urls.v1.ListURLsResponse.collected_urls:type_name -> urls.v1.ListURLsResponse.URL
-	0,  // 4: urls.v1.URLsShortener.ShortURL:input_type -> urls.v1.ShortURLRequest
-	2,  // 5: urls.v1.URLsShortener.BatchURLs:input_type -> urls.v1.BatchURLsRequest
-	4,  // 6: urls.v1.URLsProvider.GetShortenedURL:input_type -> urls.v1.GetShortenedURLRequest
-	6,  // 7: urls.v1.URLsProvider.ListURLs:input_type -> urls.v1.ListURLsRequest
-	8,  // 8: urls.v1.URLsDeleter.DelURLs:input_type -> urls.v1.DelURLsRequest
-	1,  // 9: urls.v1.URLsShortener.ShortURL:output_type -> urls.v1.ShortURLResponse
-	3,  // 10: urls.v1.URLsShortener.BatchURLs:output_type -> urls.v1.BatchURLsResponse
-	5,  // 11: urls.v1.URLsProvider.GetShortenedURL:output_type -> urls.v1.GetShortenedURLResponse
-	7,  // 12: urls.v1.URLsProvider.ListURLs:output_type -> urls.v1.ListURLsResponse
-	9,  // 13: urls.v1.URLsDeleter.DelURLs:output_type -> urls.v1.DelURLsResponse
-	9,  // [9:14] is the sub-list for method output_type
-	4,  // [4:9] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+func (x *RollbackURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_api_v1_proto_urls_proto_init() }
-func file_api_v1_proto_urls_proto_init() {
-	if File_api_v1_proto_urls_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
+func (*RollbackURLResponse) ProtoMessage() {}
+
+func (x *RollbackURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackURLResponse.ProtoReflect.Descriptor instead.
+func (*RollbackURLResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RollbackURLResponse) GetChange() *URLChange {
+	if x != nil {
+		return x.Change
+	}
+	return nil
+}
+
+type BatchURLsRequest_URL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Raw            string                 `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	CorrId         string                 `protobuf:"bytes,2,opt,name=corr_id,json=corrId,proto3" json:"corr_id,omitempty"`
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Ttl            int64                  `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	MaxVisits      int32                  `protobuf:"varint,5,opt,name=max_visits,json=maxVisits,proto3" json:"max_visits,omitempty"`
+	Password       string                 `protobuf:"bytes,6,opt,name=password,proto3" json:"password,omitempty"`
+	ActivateAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=activate_at,json=activateAt,proto3" json:"activate_at,omitempty"`
+	DeactivateAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=deactivate_at,json=deactivateAt,proto3" json:"deactivate_at,omitempty"`
+	RedirectStatus int32                  `protobuf:"varint,9,opt,name=redirect_status,json=redirectStatus,proto3" json:"redirect_status,omitempty"`
+	CachePolicy    string                 `protobuf:"bytes,10,opt,name=cache_policy,json=cachePolicy,proto3" json:"cache_policy,omitempty"`
+	QueryMode      string                 `protobuf:"bytes,11,opt,name=query_mode,json=queryMode,proto3" json:"query_mode,omitempty"`
+	FixedParams    map[string]string      `protobuf:"bytes,12,rep,name=fixed_params,json=fixedParams,proto3" json:"fixed_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ForwardPath    bool                   `protobuf:"varint,13,opt,name=forward_path,json=forwardPath,proto3" json:"forward_path,omitempty"`
+}
+
+func (x *BatchURLsRequest_URL) Reset() {
+	*x = BatchURLsRequest_URL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_proto_urls_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchURLsRequest_URL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchURLsRequest_URL) ProtoMessage() {}
+
+func (x *BatchURLsRequest_URL) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchURLsRequest_URL.ProtoReflect.Descriptor instead.
+func (*BatchURLsRequest_URL) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *BatchURLsRequest_URL) GetRaw() string {
+	if x != nil {
+		return x.Raw
+	}
+	return ""
+}
+
+func (x *BatchURLsRequest_URL) GetCorrId() string {
+	if x != nil {
+		return x.CorrId
+	}
+	return ""
+}
+
+func (x *BatchURLsRequest_URL) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *BatchURLsRequest_URL) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *BatchURLsRequest_URL) GetMaxVisits() int32 {
+	if x != nil {
+		return x.MaxVisits
+	}
+	return 0
+}
+
+func (x *BatchURLsRequest_URL) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *BatchURLsRequest_URL) GetActivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ActivateAt
+	}
+	return nil
+}
+
+func (x *BatchURLsRequest_URL) GetDeactivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeactivateAt
+	}
+	return nil
+}
+
+func (x *BatchURLsRequest_URL) GetRedirectStatus() int32 {
+	if x != nil {
+		return x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *BatchURLsRequest_URL) GetCachePolicy() string {
+	if x != nil {
+		return x.CachePolicy
+	}
+	return ""
+}
+
+func (x *BatchURLsRequest_URL) GetQueryMode() string {
+	if x != nil {
+		return x.QueryMode
+	}
+	return ""
+}
+
+func (x *BatchURLsRequest_URL) GetFixedParams() map[string]string {
+	if x != nil {
+		return x.FixedParams
+	}
+	return nil
+}
+
+func (x *BatchURLsRequest_URL) GetForwardPath() bool {
+	if x != nil {
+		return x.ForwardPath
+	}
+	return false
+}
+
+type BatchURLsResponse_URL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ShortUrl string                   `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	CorrId   string                   `protobuf:"bytes,2,opt,name=corr_id,json=corrId,proto3" json:"corr_id,omitempty"`
+	Status   BatchURLsResponse_Status `protobuf:"varint,3,opt,name=status,proto3,enum=urls.v1.BatchURLsResponse_Status" json:"status,omitempty"`
+}
+
+func (x *BatchURLsResponse_URL) Reset() {
+	*x = BatchURLsResponse_URL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_proto_urls_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchURLsResponse_URL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchURLsResponse_URL) ProtoMessage() {}
+
+func (x *BatchURLsResponse_URL) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchURLsResponse_URL.ProtoReflect.Descriptor instead.
+func (*BatchURLsResponse_URL) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *BatchURLsResponse_URL) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *BatchURLsResponse_URL) GetCorrId() string {
+	if x != nil {
+		return x.CorrId
+	}
+	return ""
+}
+
+func (x *BatchURLsResponse_URL) GetStatus() BatchURLsResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return BatchURLsResponse_CREATED
+}
+
+// The visits left are set for the visit-limited URL only,
+// the activation window is set for the scheduled URL only. The redirect
+// and the forwarding are set for the URL shortened with their own only.
+type GetShortenedURLResponse_ShortenedURL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CorrId         string                 `protobuf:"bytes,2,opt,name=corr_id,json=corrId,proto3" json:"corr_id,omitempty"`
+	Raw            string                 `protobuf:"bytes,3,opt,name=raw,proto3" json:"raw,omitempty"`
+	Slug           string                 `protobuf:"bytes,4,opt,name=slug,proto3" json:"slug,omitempty"`
+	Value          string                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	IsDeleted      bool                   `protobuf:"varint,6,opt,name=is_deleted,json=isDeleted,proto3" json:"is_deleted,omitempty"`
+	ExpiresAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	MaxVisits      int32                  `protobuf:"varint,8,opt,name=max_visits,json=maxVisits,proto3" json:"max_visits,omitempty"`
+	VisitsLeft     int32                  `protobuf:"varint,9,opt,name=visits_left,json=visitsLeft,proto3" json:"visits_left,omitempty"`
+	Protected      bool                   `protobuf:"varint,10,opt,name=protected,proto3" json:"protected,omitempty"`
+	ActivateAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=activate_at,json=activateAt,proto3" json:"activate_at,omitempty"`
+	DeactivateAt   *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=deactivate_at,json=deactivateAt,proto3" json:"deactivate_at,omitempty"`
+	RedirectStatus int32                  `protobuf:"varint,13,opt,name=redirect_status,json=redirectStatus,proto3" json:"redirect_status,omitempty"`
+	CachePolicy    string                 `protobuf:"bytes,14,opt,name=cache_policy,json=cachePolicy,proto3" json:"cache_policy,omitempty"`
+	QueryMode      string                 `protobuf:"bytes,15,opt,name=query_mode,json=queryMode,proto3" json:"query_mode,omitempty"`
+	FixedParams    map[string]string      `protobuf:"bytes,16,rep,name=fixed_params,json=fixedParams,proto3" json:"fixed_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ForwardPath    bool                   `protobuf:"varint,17,opt,name=forward_path,json=forwardPath,proto3" json:"forward_path,omitempty"`
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) Reset() {
+	*x = GetShortenedURLResponse_ShortenedURL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_proto_urls_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShortenedURLResponse_ShortenedURL) ProtoMessage() {}
+
+func (x *GetShortenedURLResponse_ShortenedURL) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShortenedURLResponse_ShortenedURL.ProtoReflect.Descriptor instead.
+func (*GetShortenedURLResponse_ShortenedURL) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{5, 0}
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetCorrId() string {
+	if x != nil {
+		return x.CorrId
+	}
+	return ""
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetRaw() string {
+	if x != nil {
+		return x.Raw
+	}
+	return ""
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetIsDeleted() bool {
+	if x != nil {
+		return x.IsDeleted
+	}
+	return false
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetMaxVisits() int32 {
+	if x != nil {
+		return x.MaxVisits
+	}
+	return 0
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetVisitsLeft() int32 {
+	if x != nil {
+		return x.VisitsLeft
+	}
+	return 0
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetProtected() bool {
+	if x != nil {
+		return x.Protected
+	}
+	return false
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetActivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ActivateAt
+	}
+	return nil
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetDeactivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeactivateAt
+	}
+	return nil
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetRedirectStatus() int32 {
+	if x != nil {
+		return x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetCachePolicy() string {
+	if x != nil {
+		return x.CachePolicy
+	}
+	return ""
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetQueryMode() string {
+	if x != nil {
+		return x.QueryMode
+	}
+	return ""
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetFixedParams() map[string]string {
+	if x != nil {
+		return x.FixedParams
+	}
+	return nil
+}
+
+func (x *GetShortenedURLResponse_ShortenedURL) GetForwardPath() bool {
+	if x != nil {
+		return x.ForwardPath
+	}
+	return false
+}
+
+// The visits left are set for the visit-limited URLs only,
+// the activation window is set for the scheduled URLs only.
+type ListURLsResponse_URL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Raw          string                 `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	ShortUrl     string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	MaxVisits    int32                  `protobuf:"varint,3,opt,name=max_visits,json=maxVisits,proto3" json:"max_visits,omitempty"`
+	VisitsLeft   int32                  `protobuf:"varint,4,opt,name=visits_left,json=visitsLeft,proto3" json:"visits_left,omitempty"`
+	Protected    bool                   `protobuf:"varint,5,opt,name=protected,proto3" json:"protected,omitempty"`
+	ActivateAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=activate_at,json=activateAt,proto3" json:"activate_at,omitempty"`
+	DeactivateAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=deactivate_at,json=deactivateAt,proto3" json:"deactivate_at,omitempty"`
+}
+
+func (x *ListURLsResponse_URL) Reset() {
+	*x = ListURLsResponse_URL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1_proto_urls_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListURLsResponse_URL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListURLsResponse_URL) ProtoMessage() {}
+
+func (x *ListURLsResponse_URL) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_proto_urls_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListURLsResponse_URL.ProtoReflect.Descriptor instead.
+func (*ListURLsResponse_URL) Descriptor() ([]byte, []int) {
+	return file_api_v1_proto_urls_proto_rawDescGZIP(), []int{7, 0}
+}
+
+func (x *ListURLsResponse_URL) GetRaw() string {
+	if x != nil {
+		return x.Raw
+	}
+	return ""
+}
+
+func (x *ListURLsResponse_URL) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *ListURLsResponse_URL) GetMaxVisits() int32 {
+	if x != nil {
+		return x.MaxVisits
+	}
+	return 0
+}
+
+func (x *ListURLsResponse_URL) GetVisitsLeft() int32 {
+	if x != nil {
+		return x.VisitsLeft
+	}
+	return 0
+}
+
+func (x *ListURLsResponse_URL) GetProtected() bool {
+	if x != nil {
+		return x.Protected
+	}
+	return false
+}
+
+func (x *ListURLsResponse_URL) GetActivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ActivateAt
+	}
+	return nil
+}
+
+func (x *ListURLsResponse_URL) GetDeactivateAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeactivateAt
+	}
+	return nil
+}
+
+var File_api_v1_proto_urls_proto protoreflect.FileDescriptor
+
+var file_api_v1_proto_urls_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x75,
+	0x72, 0x6c, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x75, 0x72, 0x6c, 0x73, 0x2e,
+	0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xdb, 0x04, 0x0a, 0x0f, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x61, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69,
+	0x61, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12,
+	0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74,
+	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x1d, 0x0a, 0x0a,
+	0x6d, 0x61, 0x78, 0x5f, 0x76, 0x69, 0x73, 0x69, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x6d, 0x61, 0x78, 0x56, 0x69, 0x73, 0x69, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x3b, 0x0a, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x76,
+	0x61, 0x74, 0x65, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61,
+	0x74, 0x65, 0x41, 0x74, 0x12, 0x3f, 0x0a, 0x0d, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61,
+	0x74, 0x65, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76,
+	0x61, 0x74, 0x65, 0x41, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e,
+	0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x21,
+	0x0a, 0x0c, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x61, 0x63, 0x68, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x4c, 0x0a, 0x0c, 0x66, 0x69, 0x78, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x2e, 0x46, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0b, 0x66, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x21,
+	0x0a, 0x0c, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x50, 0x61, 0x74,
+	0x68, 0x1a, 0x3e, 0x0a, 0x10, 0x46, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x2f, 0x0a, 0x10, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75,
+	0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55,
+	0x72, 0x6c, 0x22, 0x9d, 0x05, 0x0a, 0x10, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x55, 0x52, 0x4c, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x1a, 0xd7, 0x04, 0x0a, 0x03, 0x55, 0x52, 0x4c,
+	0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72,
+	0x61, 0x77, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x6f, 0x72, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x72, 0x72, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x65,
+	0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f,
+	0x76, 0x69, 0x73, 0x69, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61,
+	0x78, 0x56, 0x69, 0x73, 0x69, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x12, 0x3b, 0x0a, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f,
+	0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x41, 0x74,
+	0x12, 0x3f, 0x0a, 0x0d, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f, 0x61,
+	0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0c, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x41,
+	0x74, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x72, 0x65, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x61,
+	0x63, 0x68, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x61, 0x63, 0x68, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1d, 0x0a,
+	0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x51, 0x0a, 0x0c,
+	0x66, 0x69, 0x78, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x0c, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x55, 0x52,
+	0x4c, 0x2e, 0x46, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0b, 0x66, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12,
+	0x21, 0x0a, 0x0c, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x50, 0x61,
+	0x74, 0x68, 0x1a, 0x3e, 0x0a, 0x10, 0x46, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xf2, 0x01, 0x0a, 0x11, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0c, 0x62, 0x61, 0x74, 0x63,
+	0x68, 0x65, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e,
+	0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52,
+	0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x55, 0x52, 0x4c, 0x52, 0x0b,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x73, 0x1a, 0x76, 0x0a, 0x03, 0x55,
+	0x52, 0x4c, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x12,
+	0x17, 0x0a, 0x07, 0x63, 0x6f, 0x72, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x63, 0x6f, 0x72, 0x72, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x22, 0x22, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a,
+	0x07, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x45, 0x58,
+	0x49, 0x53, 0x54, 0x45, 0x44, 0x10, 0x01, 0x22, 0x2c, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x53, 0x68,
+	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0xcb, 0x06, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x53, 0x68, 0x6f,
+	0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x4a, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64,
+	0x55, 0x52, 0x4c, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x1a, 0xe3, 0x05,
+	0x0a, 0x0c, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x12, 0x17,
+	0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x6f, 0x72, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x72, 0x72, 0x49, 0x64,
+	0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72,
+	0x61, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x69, 0x73, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x09, 0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x65,
+	0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x69,
+	0x73, 0x69, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x56,
+	0x69, 0x73, 0x69, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x69, 0x73, 0x69, 0x74, 0x73, 0x5f,
+	0x6c, 0x65, 0x66, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x76, 0x69, 0x73, 0x69,
+	0x74, 0x73, 0x4c, 0x65, 0x66, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x72, 0x6f, 0x74, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x74, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x12, 0x3b, 0x0a, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65,
+	0x5f, 0x61, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x41,
+	0x74, 0x12, 0x3f, 0x0a, 0x0d, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f,
+	0x61, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65,
+	0x41, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x72, 0x65, 0x64,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63,
+	0x61, 0x63, 0x68, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x63, 0x61, 0x63, 0x68, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1d,
+	0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x0f, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x61, 0x0a,
+	0x0c, 0x66, 0x69, 0x78, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x10, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x3e, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55,
+	0x52, 0x4c, 0x2e, 0x46, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x0b, 0x66, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x50,
+	0x61, 0x74, 0x68, 0x1a, 0x3e, 0x0a, 0x10, 0x46, 0x69, 0x78, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0x11, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xeb, 0x02, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x55,
+	0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a, 0x0e, 0x63,
+	0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x55,
+	0x52, 0x4c, 0x52, 0x0d, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x55, 0x72, 0x6c,
+	0x73, 0x1a, 0x90, 0x02, 0x0a, 0x03, 0x55, 0x52, 0x4c, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x61, 0x77, 0x12, 0x1b, 0x0a, 0x09, 0x73,
+	0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f,
+	0x76, 0x69, 0x73, 0x69, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61,
+	0x78, 0x56, 0x69, 0x73, 0x69, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x69, 0x73, 0x69, 0x74,
+	0x73, 0x5f, 0x6c, 0x65, 0x66, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x76, 0x69,
+	0x73, 0x69, 0x74, 0x73, 0x4c, 0x65, 0x66, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x72, 0x6f, 0x74,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x70, 0x72, 0x6f,
+	0x74, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x3b, 0x0a, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61,
+	0x74, 0x65, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74,
+	0x65, 0x41, 0x74, 0x12, 0x3f, 0x0a, 0x0d, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74,
+	0x65, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61,
+	0x74, 0x65, 0x41, 0x74, 0x22, 0x26, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x6c, 0x75, 0x67, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x73, 0x6c, 0x75, 0x67, 0x73, 0x22, 0x11, 0x0a, 0x0f,
+	0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0xab, 0x01, 0x0a, 0x09, 0x55, 0x52, 0x4c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x6f, 0x6c, 0x64, 0x5f, 0x72,
+	0x61, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x6c, 0x64, 0x52, 0x61, 0x77,
+	0x12, 0x17, 0x0a, 0x07, 0x6e, 0x65, 0x77, 0x5f, 0x72, 0x61, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x6e, 0x65, 0x77, 0x52, 0x61, 0x77, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x41, 0x74, 0x22, 0x36, 0x0a,
+	0x0e, 0x45, 0x64, 0x69, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x61, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x72, 0x61, 0x77, 0x22, 0x3d, 0x0a, 0x0f, 0x45, 0x64, 0x69, 0x74, 0x55, 0x52, 0x4c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x55, 0x52, 0x4c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x06, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x22, 0x2b, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75,
+	0x67, 0x22, 0x46, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x07, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x75,
+	0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x52, 0x4c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0x42, 0x0a, 0x12, 0x52, 0x6f, 0x6c,
+	0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x41, 0x0a,
+	0x13, 0x52, 0x6f, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55,
+	0x52, 0x4c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x32, 0x94, 0x01, 0x0a, 0x0d, 0x55, 0x52, 0x4c, 0x73, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
+	0x65, 0x72, 0x12, 0x3f, 0x0a, 0x08, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x12, 0x18,
+	0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52,
+	0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73,
+	0x12, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x75, 0x72,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xa5, 0x01, 0x0a, 0x0c, 0x55, 0x52, 0x4c, 0x73,
+	0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x54, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53,
+	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x12, 0x1f, 0x2e, 0x75, 0x72,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
+	0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x75,
+	0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65,
+	0x6e, 0x65, 0x64, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f,
+	0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x18, 0x2e, 0x75, 0x72, 0x6c,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32,
+	0x4b, 0x0a, 0x0b, 0x55, 0x52, 0x4c, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x12, 0x3c,
+	0x0a, 0x07, 0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x17, 0x2e, 0x75, 0x72, 0x6c, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x18, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c,
+	0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xe7, 0x01, 0x0a,
+	0x0a, 0x55, 0x52, 0x4c, 0x73, 0x45, 0x64, 0x69, 0x74, 0x6f, 0x72, 0x12, 0x3c, 0x0a, 0x07, 0x45,
+	0x64, 0x69, 0x74, 0x55, 0x52, 0x4c, 0x12, 0x17, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x64, 0x69, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x18, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x64, 0x69, 0x74, 0x55, 0x52,
+	0x4c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x51, 0x0a, 0x0e, 0x4c, 0x69, 0x73,
+	0x74, 0x55, 0x52, 0x4c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x75, 0x72,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x75, 0x72,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x52, 0x4c, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0b,
+	0x52, 0x6f, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x52, 0x4c, 0x12, 0x1b, 0x2e, 0x75, 0x72,
+	0x6c, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x52,
+	0x4c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x6f, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x52, 0x4c, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x13, 0x5a, 0x11, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x75, 0x72, 0x6c, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_v1_proto_urls_proto_rawDescOnce sync.Once
+	file_api_v1_proto_urls_proto_rawDescData = file_api_v1_proto_urls_proto_rawDesc
+)
+
+func file_api_v1_proto_urls_proto_rawDescGZIP() []byte {
+	file_api_v1_proto_urls_proto_rawDescOnce.Do(func() {
+		file_api_v1_proto_urls_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_v1_proto_urls_proto_rawDescData)
+	})
+	return file_api_v1_proto_urls_proto_rawDescData
+}
+
+var file_api_v1_proto_urls_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_v1_proto_urls_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_api_v1_proto_urls_proto_goTypes = []interface{}{
+	(BatchURLsResponse_Status)(0),                // 0: urls.v1.BatchURLsResponse.Status
+	(*ShortURLRequest)(nil),                      // 1: urls.v1.ShortURLRequest
+	(*ShortURLResponse)(nil),                     // 2: urls.v1.ShortURLResponse
+	(*BatchURLsRequest)(nil),                     // 3: urls.v1.BatchURLsRequest
+	(*BatchURLsResponse)(nil),                    // 4: urls.v1.BatchURLsResponse
+	(*GetShortenedURLRequest)(nil),               // 5: urls.v1.GetShortenedURLRequest
+	(*GetShortenedURLResponse)(nil),              // 6: urls.v1.GetShortenedURLResponse
+	(*ListURLsRequest)(nil),                      // 7: urls.v1.ListURLsRequest
+	(*ListURLsResponse)(nil),                     // 8: urls.v1.ListURLsResponse
+	(*DelURLsRequest)(nil),                       // 9: urls.v1.DelURLsRequest
+	(*DelURLsResponse)(nil),                      // 10: urls.v1.DelURLsResponse
+	(*URLChange)(nil),                            // 11: urls.v1.URLChange
+	(*EditURLRequest)(nil),                       // 12: urls.v1.EditURLRequest
+	(*EditURLResponse)(nil),                      // 13: urls.v1.EditURLResponse
+	(*ListURLChangesRequest)(nil),                // 14: urls.v1.ListURLChangesRequest
+	(*ListURLChangesResponse)(nil),               // 15: urls.v1.ListURLChangesResponse
+	(*RollbackURLRequest)(nil),                   // 16: urls.v1.RollbackURLRequest
+	(*RollbackURLResponse)(nil),                  // 17: urls.v1.RollbackURLResponse
+	nil,                                          // 18: urls.v1.ShortURLRequest.FixedParamsEntry
+	(*BatchURLsRequest_URL)(nil),                 // 19: urls.v1.BatchURLsRequest.URL
+	nil,                                          // 20: urls.v1.BatchURLsRequest.URL.FixedParamsEntry
+	(*BatchURLsResponse_URL)(nil),                // 21: urls.v1.BatchURLsResponse.URL
+	(*GetShortenedURLResponse_ShortenedURL)(nil), // 22: urls.v1.GetShortenedURLResponse.ShortenedURL
+	nil,                           // 23: urls.v1.GetShortenedURLResponse.ShortenedURL.FixedParamsEntry
+	(*ListURLsResponse_URL)(nil),  // 24: urls.v1.ListURLsResponse.URL
+	(*timestamppb.Timestamp)(nil), // 25: google.protobuf.Timestamp
+}
+var file_api_v1_proto_urls_proto_depIdxs = []int32{
+	25, // 0: urls.v1.ShortURLRequest.expires_at:type_name -> google.protobuf.Timestamp
+	25, // 1: urls.v1.ShortURLRequest.activate_at:type_name -> google.protobuf.Timestamp
+	25, // 2: urls.v1.ShortURLRequest.deactivate_at:type_name -> google.protobuf.Timestamp
+	18, // 3: urls.v1.ShortURLRequest.fixed_params:type_name -> urls.v1.ShortURLRequest.FixedParamsEntry
+	19, // 4: urls.v1.BatchURLsRequest.url:type_name -> urls.v1.BatchURLsRequest.URL
+	21, // 5: urls.v1.BatchURLsResponse.batched_urls:type_name -> urls.v1.BatchURLsResponse.URL
+	22, // 6: urls.v1.GetShortenedURLResponse.short_url:type_name -> urls.v1.GetShortenedURLResponse.ShortenedURL
+	24, // 7: urls.v1.ListURLsResponse.collected_urls:type_name -> urls.v1.ListURLsResponse.URL
+	25, // 8: urls.v1.URLChange.changed_at:type_name -> google.protobuf.Timestamp
+	11, // 9: urls.v1.EditURLResponse.change:type_name -> urls.v1.URLChange
+	11, // 10: urls.v1.ListURLChangesResponse.changes:type_name -> urls.v1.URLChange
+	11, // 11: urls.v1.RollbackURLResponse.change:type_name -> urls.v1.URLChange
+	25, // 12: urls.v1.BatchURLsRequest.URL.expires_at:type_name -> google.protobuf.Timestamp
+	25, // 13: urls.v1.BatchURLsRequest.URL.activate_at:type_name -> google.protobuf.Timestamp
+	25, // 14: urls.v1.BatchURLsRequest.URL.deactivate_at:type_name -> google.protobuf.Timestamp
+	20, // 15: urls.v1.BatchURLsRequest.URL.fixed_params:type_name -> urls.v1.BatchURLsRequest.URL.FixedParamsEntry
+	0,  // 16: urls.v1.BatchURLsResponse.URL.status:type_name -> urls.v1.BatchURLsResponse.Status
+	25, // 17: urls.v1.GetShortenedURLResponse.ShortenedURL.expires_at:type_name -> google.protobuf.Timestamp
+	25, // 18: urls.v1.GetShortenedURLResponse.ShortenedURL.activate_at:type_name -> google.protobuf.Timestamp
+	25, // 19: urls.v1.GetShortenedURLResponse.ShortenedURL.deactivate_at:type_name -> google.protobuf.Timestamp
+	23, // 20: urls.v1.GetShortenedURLResponse.ShortenedURL.fixed_params:type_name -> urls.v1.GetShortenedURLResponse.ShortenedURL.FixedParamsEntry
+	25, // 21: urls.v1.ListURLsResponse.URL.activate_at:type_name -> google.protobuf.Timestamp
+	25, // 22: urls.v1.ListURLsResponse.URL.deactivate_at:type_name -> google.protobuf.Timestamp
+	1,  // 23: urls.v1.URLsShortener.ShortURL:input_type -> urls.v1.ShortURLRequest
+	3,  // 24: urls.v1.URLsShortener.BatchURLs:input_type -> urls.v1.BatchURLsRequest
+	5,  // 25: urls.v1.URLsProvider.GetShortenedURL:input_type -> urls.v1.GetShortenedURLRequest
+	7,  // 26: urls.v1.URLsProvider.ListURLs:input_type -> urls.v1.ListURLsRequest
+	9,  // 27: urls.v1.URLsDeleter.DelURLs:input_type -> urls.v1.DelURLsRequest
+	12, // 28: urls.v1.URLsEditor.EditURL:input_type -> urls.v1.EditURLRequest
+	14, // 29: urls.v1.URLsEditor.ListURLChanges:input_type -> urls.v1.ListURLChangesRequest
+	16, // 30: urls.v1.URLsEditor.RollbackURL:input_type -> urls.v1.RollbackURLRequest
+	2,  // 31: urls.v1.URLsShortener.ShortURL:output_type -> urls.v1.ShortURLResponse
+	4,  // 32: urls.v1.URLsShortener.BatchURLs:output_type -> urls.v1.BatchURLsResponse
+	6,  // 33: urls.v1.URLsProvider.GetShortenedURL:output_type -> urls.v1.GetShortenedURLResponse
+	8,  // 34: urls.v1.URLsProvider.ListURLs:output_type -> urls.v1.ListURLsResponse
+	10, // 35: urls.v1.URLsDeleter.DelURLs:output_type -> urls.v1.DelURLsResponse
+	13, // 36: urls.v1.URLsEditor.EditURL:output_type -> urls.v1.EditURLResponse
+	15, // 37: urls.v1.URLsEditor.ListURLChanges:output_type -> urls.v1.ListURLChangesResponse
+	17, // 38: urls.v1.URLsEditor.RollbackURL:output_type -> urls.v1.RollbackURLResponse
+	31, // [31:39] is the sub-list for method output_type
+	23, // [23:31] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_api_v1_proto_urls_proto_init() }
+func file_api_v1_proto_urls_proto_init() {
+	if File_api_v1_proto_urls_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
 		file_api_v1_proto_urls_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ShortURLRequest); i {
 			case 0:
@@ -992,7 +1958,7 @@ func file_api_v1_proto_urls_proto_init() {
 			}
 		}
 		file_api_v1_proto_urls_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BatchURLsRequest_URL); i {
+			switch v := v.(*URLChange); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1004,7 +1970,7 @@ func file_api_v1_proto_urls_proto_init() {
 			}
 		}
 		file_api_v1_proto_urls_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BatchURLsResponse_URL); i {
+			switch v := v.(*EditURLRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1016,7 +1982,7 @@ func file_api_v1_proto_urls_proto_init() {
 			}
 		}
 		file_api_v1_proto_urls_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetShortenedURLResponse_ShortenedURL); i {
+			switch v := v.(*EditURLResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1028,6 +1994,90 @@ func file_api_v1_proto_urls_proto_init() {
 			}
 		}
 		file_api_v1_proto_urls_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListURLChangesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_proto_urls_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListURLChangesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_proto_urls_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RollbackURLRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_proto_urls_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RollbackURLResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_proto_urls_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchURLsRequest_URL); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_proto_urls_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchURLsResponse_URL); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_proto_urls_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetShortenedURLResponse_ShortenedURL); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1_proto_urls_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListURLsResponse_URL); i {
 			case 0:
 				return &v.state
@@ -1045,13 +2095,14 @@ func file_api_v1_proto_urls_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_v1_proto_urls_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   14,
+			NumEnums:      1,
+			NumMessages:   24,
 			NumExtensions: 0,
-			NumServices:   3,
+			NumServices:   4,
 		},
 		GoTypes:           file_api_v1_proto_urls_proto_goTypes,
 		DependencyIndexes: file_api_v1_proto_urls_proto_depIdxs,
+		EnumInfos:         file_api_v1_proto_urls_proto_enumTypes,
 		MessageInfos:      file_api_v1_proto_urls_proto_msgTypes,
 	}.Build()
 	File_api_v1_proto_urls_proto = out.File
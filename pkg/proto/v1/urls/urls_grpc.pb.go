@@ -30,7 +30,17 @@ type URLsShortenerClient interface {
 	// Shorts the URL.
 	//
 	// The saved value of the shortened URL will be returned if a raw value is found.
-	// Error is returned if URL is invalid.
+	// Error is returned if URL is invalid. The optional alias is the custom slug,
+	// AlreadyExists is returned if it is taken. The shortened URL expires at the optional
+	// expires_at or after the optional ttl in seconds, at most one of them is set.
+	// It redirects only from the optional activate_at until the optional deactivate_at.
+	// It redirects at most the optional max_visits times and is protected by
+	// the optional password. It redirects with the optional redirect_status
+	// (301, 302, 307 or 308) and cache_policy (no-store, private or public),
+	// the service defaults are used if they are not set. The redirect forwards
+	// the query by the optional query_mode (drop, merge or override), sets
+	// the optional fixed_params and forwards the path after the slug if
+	// the optional forward_path is set.
 	ShortURL(ctx context.Context, in *ShortURLRequest, opts ...grpc.CallOption) (*ShortURLResponse, error)
 	// Saves a batch of URLs.
 	BatchURLs(ctx context.Context, in *BatchURLsRequest, opts ...grpc.CallOption) (*BatchURLsResponse, error)
@@ -69,7 +79,17 @@ type URLsShortenerServer interface {
 	// Shorts the URL.
 	//
 	// The saved value of the shortened URL will be returned if a raw value is found.
-	// Error is returned if URL is invalid.
+	// Error is returned if URL is invalid. The optional alias is the custom slug,
+	// AlreadyExists is returned if it is taken. The shortened URL expires at the optional
+	// expires_at or after the optional ttl in seconds, at most one of them is set.
+	// It redirects only from the optional activate_at until the optional deactivate_at.
+	// It redirects at most the optional max_visits times and is protected by
+	// the optional password. It redirects with the optional redirect_status
+	// (301, 302, 307 or 308) and cache_policy (no-store, private or public),
+	// the service defaults are used if they are not set. The redirect forwards
+	// the query by the optional query_mode (drop, merge or override), sets
+	// the optional fixed_params and forwards the path after the slug if
+	// the optional forward_path is set.
 	ShortURL(context.Context, *ShortURLRequest) (*ShortURLResponse, error)
 	// Saves a batch of URLs.
 	BatchURLs(context.Context, *BatchURLsRequest) (*BatchURLsResponse, error)
@@ -164,7 +184,10 @@ const (
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type URLsProviderClient interface {
-	// Get the shortened URL.
+	// Get the shortened URL. NotFound is returned if it has expired or has been deactivated.
+	// PermissionDenied is returned for the password-protected URL unless
+	// the caller is its owner, FailedPrecondition is returned for the URL
+	// that is not active yet unless the caller is its owner.
 	GetShortenedURL(ctx context.Context, in *GetShortenedURLRequest, opts ...grpc.CallOption) (*GetShortenedURLResponse, error)
 	// Collects shortened URLs.
 	ListURLs(ctx context.Context, in *ListURLsRequest, opts ...grpc.CallOption) (*ListURLsResponse, error)
@@ -200,7 +223,10 @@ func (c *uRLsProviderClient) ListURLs(ctx context.Context, in *ListURLsRequest,
 // All implementations must embed UnimplementedURLsProviderServer
 // for forward compatibility
 type URLsProviderServer interface {
-	// Get the shortened URL.
+	// Get the shortened URL. NotFound is returned if it has expired or has been deactivated.
+	// PermissionDenied is returned for the password-protected URL unless
+	// the caller is its owner, FailedPrecondition is returned for the URL
+	// that is not active yet unless the caller is its owner.
 	GetShortenedURL(context.Context, *GetShortenedURLRequest) (*GetShortenedURLResponse, error)
 	// Collects shortened URLs.
 	ListURLs(context.Context, *ListURLsRequest) (*ListURLsResponse, error)
@@ -377,3 +403,185 @@ var URLsDeleter_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/proto/urls.proto",
 }
+
+const (
+	URLsEditor_EditURL_FullMethodName        = "/urls.v1.URLsEditor/EditURL"
+	URLsEditor_ListURLChanges_FullMethodName = "/urls.v1.URLsEditor/ListURLChanges"
+	URLsEditor_RollbackURL_FullMethodName    = "/urls.v1.URLsEditor/RollbackURL"
+)
+
+// URLsEditorClient is the client API for URLsEditor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type URLsEditorClient interface {
+	// Changes the destination of the shortened URL of the caller. InvalidArgument is returned
+	// if the destination is invalid or rejected, NotFound if the URL is not found or has been
+	// deleted, PermissionDenied for the URL of another user and AlreadyExists if the destination
+	// is shortened by another URL.
+	EditURL(ctx context.Context, in *EditURLRequest, opts ...grpc.CallOption) (*EditURLResponse, error)
+	// Lists the destination changes of the shortened URL of the caller in the version order.
+	ListURLChanges(ctx context.Context, in *ListURLChangesRequest, opts ...grpc.CallOption) (*ListURLChangesResponse, error)
+	// Changes the destination of the shortened URL of the caller back to the one
+	// of the version, the destination the URL was shortened with is version 1.
+	// The rollback is a new change, InvalidArgument is returned for the unknown version,
+	// Aborted if the URL is changed concurrently.
+	RollbackURL(ctx context.Context, in *RollbackURLRequest, opts ...grpc.CallOption) (*RollbackURLResponse, error)
+}
+
+type uRLsEditorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewURLsEditorClient(cc grpc.ClientConnInterface) URLsEditorClient {
+	return &uRLsEditorClient{cc}
+}
+
+func (c *uRLsEditorClient) EditURL(ctx context.Context, in *EditURLRequest, opts ...grpc.CallOption) (*EditURLResponse, error) {
+	out := new(EditURLResponse)
+	err := c.cc.Invoke(ctx, URLsEditor_EditURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsEditorClient) ListURLChanges(ctx context.Context, in *ListURLChangesRequest, opts ...grpc.CallOption) (*ListURLChangesResponse, error) {
+	out := new(ListURLChangesResponse)
+	err := c.cc.Invoke(ctx, URLsEditor_ListURLChanges_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsEditorClient) RollbackURL(ctx context.Context, in *RollbackURLRequest, opts ...grpc.CallOption) (*RollbackURLResponse, error) {
+	out := new(RollbackURLResponse)
+	err := c.cc.Invoke(ctx, URLsEditor_RollbackURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// URLsEditorServer is the server API for URLsEditor service.
+// All implementations must embed UnimplementedURLsEditorServer
+// for forward compatibility
+type URLsEditorServer interface {
+	// Changes the destination of the shortened URL of the caller. InvalidArgument is returned
+	// if the destination is invalid or rejected, NotFound if the URL is not found or has been
+	// deleted, PermissionDenied for the URL of another user and AlreadyExists if the destination
+	// is shortened by another URL.
+	EditURL(context.Context, *EditURLRequest) (*EditURLResponse, error)
+	// Lists the destination changes of the shortened URL of the caller in the version order.
+	ListURLChanges(context.Context, *ListURLChangesRequest) (*ListURLChangesResponse, error)
+	// Changes the destination of the shortened URL of the caller back to the one
+	// of the version, the destination the URL was shortened with is version 1.
+	// The rollback is a new change, InvalidArgument is returned for the unknown version,
+	// Aborted if the URL is changed concurrently.
+	RollbackURL(context.Context, *RollbackURLRequest) (*RollbackURLResponse, error)
+	mustEmbedUnimplementedURLsEditorServer()
+}
+
+// UnimplementedURLsEditorServer must be embedded to have forward compatible implementations.
+type UnimplementedURLsEditorServer struct {
+}
+
+func (UnimplementedURLsEditorServer) EditURL(context.Context, *EditURLRequest) (*EditURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditURL not implemented")
+}
+func (UnimplementedURLsEditorServer) ListURLChanges(context.Context, *ListURLChangesRequest) (*ListURLChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListURLChanges not implemented")
+}
+func (UnimplementedURLsEditorServer) RollbackURL(context.Context, *RollbackURLRequest) (*RollbackURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackURL not implemented")
+}
+func (UnimplementedURLsEditorServer) mustEmbedUnimplementedURLsEditorServer() {}
+
+// UnsafeURLsEditorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to URLsEditorServer will
+// result in compilation errors.
+type UnsafeURLsEditorServer interface {
+	mustEmbedUnimplementedURLsEditorServer()
+}
+
+func RegisterURLsEditorServer(s grpc.ServiceRegistrar, srv URLsEditorServer) {
+	s.RegisterService(&URLsEditor_ServiceDesc, srv)
+}
+
+func _URLsEditor_EditURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsEditorServer).EditURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLsEditor_EditURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsEditorServer).EditURL(ctx, req.(*EditURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLsEditor_ListURLChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListURLChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsEditorServer).ListURLChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLsEditor_ListURLChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsEditorServer).ListURLChanges(ctx, req.(*ListURLChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLsEditor_RollbackURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsEditorServer).RollbackURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLsEditor_RollbackURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsEditorServer).RollbackURL(ctx, req.(*RollbackURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// URLsEditor_ServiceDesc is the grpc.ServiceDesc for URLsEditor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var URLsEditor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "urls.v1.URLsEditor",
+	HandlerType: (*URLsEditorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "EditURL",
+			Handler:    _URLsEditor_EditURL_Handler,
+		},
+		{
+			MethodName: "ListURLChanges",
+			Handler:    _URLsEditor_ListURLChanges_Handler,
+		},
+		{
+			MethodName: "RollbackURL",
+			Handler:    _URLsEditor_RollbackURL_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/proto/urls.proto",
+}